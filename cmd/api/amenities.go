@@ -51,7 +51,8 @@ func (app *application) CreateAmenityHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	if err := app.repository.Amenities.Create(r.Context(), payload.Name, payload.TypeId); err != nil {
+	id, err := app.repository.Amenities.Create(r.Context(), payload.Name, payload.TypeId)
+	if err != nil {
 		switch err {
 		case repository.ErrDuplicateAmenities:
 			app.conflictErrorResponse(w, r, err)
@@ -64,6 +65,8 @@ func (app *application) CreateAmenityHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	app.recordAudit(r, auditCreate, "amenity", id, nil, payload)
+
 	if err := app.jsonResponse(w, http.StatusCreated, "amenity created successfull"); err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -116,26 +119,25 @@ func (app *application) GetAmenityByIDHandler(w http.ResponseWriter, r *http.Req
 //
 // @Param			limit	query		string	false	"limit pages"
 // @Param			offset	query		string	false	"skip rows"
-// @Param			sort	query		string	false	"sort latest(desc) older(asc)"
+// @Param			cursor	query		string	false	"cursor of the next or prev page from meta"
+// @Param			sort	query		string	false	"sort latest(desc), older(asc) or by fields e.g. name,-created_at"
+// @Param			name	query		string	false	"name of the amenity contains"
+// @Param			type_id	query		int	false	"type id of the amenity"
 //
-// @Success		200		{object}	main.jsonResponse.envelope{data=[]AmenityResponse}
+// @Success		200		{object}	main.jsonPageResponse.envelope{data=[]AmenityResponse,meta=pagination.Page}
 // @Failure		404		{object}	main.WriteJSONError.envelope
 // @Failure		500		{object}	main.WriteJSONError.envelope
 // @Router			/amenities [GET]
 func (app *application) GetAmenitiesHandler(w http.ResponseWriter, r *http.Request) {
 
-	query, err := repository.PaginatedAmenitiesQuery{
-		Limit:  10,
-		Offset: 0,
-		Sort:   "asc",
-	}.Parse(r)
+	query, err := repository.AmenitiesList.Parse(r.URL.Query())
 
 	if err != nil {
 		app.badRequestResponse(w, r, err)
 		return
 	}
 
-	amenities, err := app.repository.Amenities.GetAmenities(r.Context(), query)
+	amenities, page, err := app.repository.Amenities.GetAmenities(r.Context(), query)
 	if err != nil {
 		app.internalServerError(w, r, err)
 
@@ -156,7 +158,7 @@ func (app *application) GetAmenitiesHandler(w http.ResponseWriter, r *http.Reque
 		amenitiesRes = append(amenitiesRes, newAmenity)
 	}
 
-	if err := app.jsonResponse(w, http.StatusOK, amenitiesRes); err != nil {
+	if err := app.jsonPageResponse(w, r, http.StatusOK, amenitiesRes, page); err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
@@ -216,6 +218,8 @@ func (app *application) UpdateAmenityHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	before := *amenity
+
 	amenity.Name = payload.Name
 
 	// if change the type update new one
@@ -228,6 +232,8 @@ func (app *application) UpdateAmenityHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	app.recordAudit(r, auditUpdate, "amenity", amenity.Id, before, amenity)
+
 	if err := app.jsonResponse(w, http.StatusCreated, "update amenity successfull"); err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -271,6 +277,8 @@ func (app *application) DeleteAmenityHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	app.recordAudit(r, auditDelete, "amenity", amenity.Id, amenity, nil)
+
 	if err := app.responseNoContent(w); err != nil {
 		app.internalServerError(w, r, err)
 		return
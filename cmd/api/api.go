@@ -15,7 +15,9 @@ import (
 	"github.com/charmbracelet/log"
 	"github.com/faizisyellow/gobali/internal/auth"
 	"github.com/faizisyellow/gobali/internal/mailer"
+	"github.com/faizisyellow/gobali/internal/oidc"
 	"github.com/faizisyellow/gobali/internal/repository"
+	"github.com/faizisyellow/gobali/internal/search"
 	"github.com/faizisyellow/gobali/internal/uploader"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -28,7 +30,16 @@ type application struct {
 	repository     repository.Repository
 	mailer         mailer.Client
 	upload         uploader.Uploader
+	signer         *uploader.URLSigner
+	search         search.Searcher
 	authentication auth.Authenticator
+	oidc           oidcLogin
+}
+
+// oidcLogin holds the configured social login providers by name and the logins waiting for a callback.
+type oidcLogin struct {
+	providers map[string]*oidc.Provider
+	states    *oidc.StateStore
 }
 
 type config struct {
@@ -39,10 +50,15 @@ type config struct {
 	upload    uploadConfig
 	clientURL string
 	auth      authConfig
+	oidc      []oidc.Config
+	cache     cacheConfig
 }
 
 type tokenConfig struct {
 	privateKey string
+	keysDir    string
+	activeKid  string
+	keyGrace   time.Duration
 	exp        time.Duration
 	iss        string
 	sub        string
@@ -57,8 +73,24 @@ type basicConfig struct {
 	username, password string
 }
 
+type cacheConfig struct {
+	ttl time.Duration
+}
+
 type uploadConfig struct {
-	baseDir string
+	// storage is "local" or "s3", replicas of the API need a shared s3 storage
+	storage    string
+	baseDir    string
+	privateDir string
+	baseURL    string
+	s3         uploader.S3Config
+	// signing of the URLs to private files
+	signingKey   string
+	privateURL   string
+	signedURLExp time.Duration
+	// garbage collection of unreferenced files, a zero interval disables it
+	gcInterval time.Duration
+	gcGrace    time.Duration
 }
 
 type mailConfig struct {
@@ -88,13 +120,15 @@ func (app *application) mount() http.Handler {
 	r.Use(cors.Handler(cors.Options{
 		// AllowedOrigins:   []string{"https://foo.com"}, // Use this to allow specific origin hosts
 		AllowedOrigins:   []string{app.configs.clientURL, "http://localhost:5173"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		ExposedHeaders:   []string{"Link", "X-Total-Count"},
 		AllowCredentials: false,
 		MaxAge:           300, // Maximum value not ignored by any of major browsers
 	}))
-	r.Use(middleware.Timeout(60 * time.Second))
+	r.Use(app.TimeoutMiddleware)
+
+	r.Get("/.well-known/jwks.json", app.JWKSHandler)
 
 	r.Route("/v1", func(r chi.Router) {
 
@@ -105,15 +139,31 @@ func (app *application) mount() http.Handler {
 			r.Get("/health", app.healthHandler)
 
 			r.Route("/users", func(r chi.Router) {
+				// the account of the user is only managed by the user
+				r.Use(app.DenyApiKey)
+
 				// get the user by the who's is login
 				r.Get("/profile", app.ProfileUser)
+				r.Put("/profile", app.UpdateProfileHandler)
+				r.With(app.DenyImpersonation).Post("/profile/email", app.ChangeEmailHandler)
 				r.Post("/", app.CreateUserHandler)
 
 				r.Get("/bookings", app.UserBookingsHandler)
+
+				r.Get("/sessions", app.GetSessionsHandler)
+				r.Delete("/sessions/{sessionID}", app.RevokeSessionHandler)
+
+				r.Route("/me", func(r chi.Router) {
+					r.Use(app.DenyImpersonation)
+
+					r.Get("/export", app.ExportUserDataHandler)
+					r.Put("/consents", app.UpdateConsentHandler)
+					r.Delete("/", app.DeleteAccountHandler)
+				})
 			})
 
 			r.Route("/categories", func(r chi.Router) {
-				r.Use(app.OfficerOnlyAccess)
+				r.Use(app.RequirePermission("categories:manage"))
 
 				r.Post("/", app.CreateCategoryHandler)
 				r.Get("/", app.GetCategoriesHandler)
@@ -126,7 +176,7 @@ func (app *application) mount() http.Handler {
 			})
 
 			r.Route("/locations", func(r chi.Router) {
-				r.Use(app.OfficerOnlyAccess)
+				r.Use(app.RequirePermission("locations:manage"))
 
 				r.Post("/", app.CreateLocationHandler)
 				r.Get("/", app.GetLocationsHandler)
@@ -140,7 +190,7 @@ func (app *application) mount() http.Handler {
 
 			r.Route("/types", func(r chi.Router) {
 
-				r.Use(app.OfficerOnlyAccess)
+				r.Use(app.RequirePermission("types:manage"))
 
 				r.Get("/", app.GetTypesHandler)
 				r.Post("/", app.CreateTypeHandler)
@@ -153,7 +203,7 @@ func (app *application) mount() http.Handler {
 			})
 
 			r.Route("/amenities", func(r chi.Router) {
-				r.Use(app.OfficerOnlyAccess)
+				r.Use(app.RequirePermission("amenities:manage"))
 
 				r.Get("/", app.GetAmenitiesHandler)
 				r.Post("/", app.CreateAmenityHandler)
@@ -167,31 +217,102 @@ func (app *application) mount() http.Handler {
 
 			r.Route("/villas", func(r chi.Router) {
 
-				r.Use(app.OfficerOnlyAccess)
-
-				r.Post("/", app.UploadImagesMiddleware(app.CreateVillaHandler, "villas"))
+				r.With(app.RequirePermission("villas:create")).Post("/", app.UploadImagesMiddleware(app.CreateVillaHandler, "villas"))
 
 				r.Route("/{villaID}", func(r chi.Router) {
 					r.Use(app.VillaContentMiddleware)
 
-					r.Put("/", app.UploadImagesMiddleware(app.UpdateVillaHandler, "villas"))
-					r.Delete("/", app.DeleteVillaByIdHandler)
+					r.With(app.RequirePermission("villas:update"), app.PublishedVillaMiddleware).Put("/", app.UploadImagesMiddleware(app.UpdateVillaHandler, "villas"))
+					r.With(app.RequirePermission("villas:delete")).Delete("/", app.DeleteVillaByIdHandler)
+					// the permission of each move is checked by the handler
+					r.Patch("/status", app.UpdateVillaStatusHandler)
+
+					r.Route("/images", func(r chi.Router) {
+						r.Use(app.RequirePermission("villas:update"))
+						r.Use(app.PublishedVillaMiddleware)
+
+						r.Post("/", app.UploadImagesMiddleware(app.AddVillaImagesHandler, "villas"))
+						r.Put("/order", app.OrderVillaImagesHandler)
+						r.Delete("/{filename}", app.DeleteVillaImageHandler)
+					})
 				})
 			})
 
 			r.Route("/bookings", func(r chi.Router) {
-				r.Post("/", app.UserAction(app.CreateBookingHandler))
+				r.With(app.RequirePermission("bookings:create")).Post("/", app.CreateBookingHandler)
 
-				r.Get("/", app.BookingAccess("admin", app.GetBookingsHandler))
+				r.Get("/", app.BookingAccess("bookings:read", app.GetBookingsHandler))
 
 				r.Route("/{bookingID}", func(r chi.Router) {
 					r.Use(app.BookingContentMiddleware)
 
 					// user can self check-in check-out.
-					r.Get("/", app.BookingAccess("admin", app.GetBookingByIdHandler))
-					r.Patch("/check-in", app.BookingAccess("admin", app.CheckInHandler))
-					r.Patch("/check-out", app.BookingAccess("admin", app.CheckOutHandler))
-					r.Delete("/", app.BookingAccess("admin", app.DeleteBookingHandler))
+					r.Get("/", app.BookingAccess("bookings:read", app.GetBookingByIdHandler))
+					r.Patch("/check-in", app.BookingAccess("bookings:check-in", app.CheckInHandler))
+					r.Patch("/check-out", app.BookingAccess("bookings:check-out", app.CheckOutHandler))
+					r.Delete("/", app.BookingAccess("bookings:delete", app.DeleteBookingHandler))
+
+					r.Get("/documents", app.BookingAccess("bookings:read", app.GetBookingDocumentsHandler))
+					r.Post("/documents", app.BookingAccess("bookings:check-in", app.UploadDocumentsMiddleware(app.AddBookingDocumentsHandler, documentsDst)))
+				})
+			})
+
+			r.Route("/admin", func(r chi.Router) {
+				r.Use(app.DenyImpersonation)
+
+				r.Route("/roles", func(r chi.Router) {
+					r.Use(app.RequirePermission("roles:manage"))
+
+					r.Get("/", app.GetRolesHandler)
+					r.Post("/", app.CreateRoleHandler)
+
+					r.Route("/{roleID}", func(r chi.Router) {
+						r.Use(app.RoleContentMiddleware)
+
+						r.Get("/", app.GetRoleByIdHandler)
+						r.Put("/", app.UpdateRoleHandler)
+						r.Delete("/", app.DeleteRoleHandler)
+						r.Put("/permissions", app.SetRolePermissionsHandler)
+					})
+				})
+
+				r.With(app.RequirePermission("roles:manage")).Get("/permissions", app.GetPermissionsHandler)
+				r.With(app.RequirePermission("audit:read")).Get("/audit", app.GetAuditEventsHandler)
+
+				r.Route("/villas", func(r chi.Router) {
+					r.Use(app.RequirePermission("villas:update"))
+
+					r.Get("/", app.GetAdminVillasHandler)
+					r.With(app.VillaContentMiddleware).Get("/{villaID}", app.GetVillaByIdHandler)
+				})
+
+				r.Route("/api-keys", func(r chi.Router) {
+					r.Use(app.RequirePermission("api_keys:manage"))
+
+					r.Get("/", app.GetApiKeysHandler)
+					r.Post("/", app.CreateApiKeyHandler)
+					r.With(app.ApiKeyContentMiddleware).Delete("/{keyID}", app.RevokeApiKeyHandler)
+				})
+
+				r.Route("/users", func(r chi.Router) {
+					r.Use(app.RequirePermission("users:manage"))
+
+					r.Get("/", app.GetUsersHandler)
+
+					r.Route("/{userID}", func(r chi.Router) {
+						r.Use(app.AccountContentMiddleware)
+
+						r.Get("/", app.GetUserByIdHandler)
+						r.Put("/role", app.UpdateUserRoleHandler)
+						r.Put("/activation", app.UpdateUserActivationHandler)
+						r.Delete("/", app.DeleteUserHandler)
+
+						r.With(app.RequirePermission("users:impersonate")).Post("/impersonate", app.ImpersonateUserHandler)
+
+						r.Get("/sessions", app.GetUserSessionsHandler)
+						r.Delete("/sessions", app.RevokeUserSessionsHandler)
+						r.Delete("/sessions/{sessionID}", app.RevokeUserSessionHandler)
+					})
 				})
 			})
 		})
@@ -207,20 +328,30 @@ func (app *application) mount() http.Handler {
 			))
 
 			r.Get("/villas", app.GetVillasHandler)
-			r.With(app.VillaContentMiddleware).Get("/villas/{villaID}", app.GetVillaByIdHandler)
+			r.Get("/villas/facets", app.GetVillaFacetsHandler)
+			r.Get("/villas/map", app.GetVillaPinsHandler)
+			r.With(app.VillaContentMiddleware, app.VisibleVillaMiddleware).Get("/villas/{villaID}", app.GetVillaByIdHandler)
+
+			// the signature of the URL authorizes the private file
+			r.Get("/files/private/{group}/{name}", app.PrivateFileHandler)
 
 			r.Put("/users/activate/{token}", app.ActivateUserHandler)
+			r.Put("/users/email/confirm/{token}", app.ConfirmEmailChangeHandler)
 
 			r.Route("/authentication", func(r chi.Router) {
 				r.Post("/register", app.RegisterHandler)
 				r.Post("/login", app.LoginHandler)
+				r.Get("/oidc/{provider}", app.OIDCAuthorizeHandler)
+				r.Post("/oidc/{provider}/callback", app.OIDCCallbackHandler)
 			})
 		})
 	})
 
 	// Create a route along /files that will serve contents from
-	// the ./data/ folder.
-	FileServer(r, "/files", "internal/assets")
+	// the upload folder, the s3 storage serves its objects itself.
+	if app.configs.upload.storage == "local" {
+		FileServer(r, "/files", app.configs.upload.baseDir)
+	}
 
 	return r
 }
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/faizisyellow/gobali/internal/auth"
+	"github.com/faizisyellow/gobali/internal/repository"
+	"github.com/go-chi/chi/v5"
+)
+
+var ErrApiKeyNotAllowed = errors.New("not allowed with an api key")
+
+type apiKeyCtxKey string
+
+const apiKeyRecordKey apiKeyCtxKey = "api_key_record"
+
+type CreateApiKeyPayload struct {
+	Name          string   `json:"name" validate:"required,min=3,max=255"`
+	UserId        int      `json:"user_id" validate:"required"`
+	Scopes        []string `json:"scopes" validate:"required,min=1"`
+	ExpiresInDays int      `json:"expires_in_days" validate:"gte=0,lte=730"`
+}
+
+type CreateApiKeyResponse struct {
+	Key    string             `json:"key"`
+	ApiKey *repository.ApiKey `json:"api_key"`
+}
+
+// @Summary		Issue API key
+// @Description	Issue a partner API key acting as the user, limited to the scopes. The key is only shown once
+// @Tags			Admin
+// @Accept			json
+// @Produce		json
+// @Param			payload	body	CreateApiKeyPayload	true	"Payload issue api key"
+// @Security		JWT
+// @Success		201	{object}	main.jsonResponse.envelope{data=CreateApiKeyResponse}
+// @Failure		400	{object}	main.WriteJSONError.envelope
+// @Failure		500	{object}	main.WriteJSONError.envelope
+// @Router			/admin/api-keys [post]
+func (app *application) CreateApiKeyHandler(w http.ResponseWriter, r *http.Request) {
+	payload := &CreateApiKeyPayload{}
+
+	if err := readJSON(w, r, payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+
+	permissions, err := app.repository.Roles.GetPermissions(ctx)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	for _, scope := range payload.Scopes {
+		known := slices.ContainsFunc(permissions, func(p *repository.Permission) bool { return p.Name == scope })
+		if !known {
+			app.badRequestResponse(w, r, repository.ErrPermissionNotExist)
+			return
+		}
+	}
+
+	plainKey, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	issuer := getUserFromContext(r)
+
+	key := &repository.ApiKey{
+		Name:      payload.Name,
+		Prefix:    prefix,
+		Hash:      hash,
+		UserId:    payload.UserId,
+		Scopes:    payload.Scopes,
+		CreatedBy: &issuer.Id,
+	}
+
+	var expiresAt *time.Time
+	if payload.ExpiresInDays > 0 {
+		exp := time.Now().AddDate(0, 0, payload.ExpiresInDays)
+		expiresAt = &exp
+	}
+
+	if err := app.repository.ApiKeys.Create(ctx, key, expiresAt); err != nil {
+		switch err {
+		case repository.ErrNoRows:
+			app.badRequestResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+
+		return
+	}
+
+	key, err = app.repository.ApiKeys.GetByID(ctx, key.Id)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.recordAudit(r, auditCreate, "api_key", key.Id, nil, key)
+
+	if err := app.jsonResponse(w, http.StatusCreated, CreateApiKeyResponse{Key: plainKey, ApiKey: key}); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// @Summary		Get API keys
+// @Description	Get every partner API key, without the secret
+// @Tags			Admin
+// @Produce		json
+// @Security		JWT
+// @Success		200	{object}	main.jsonResponse.envelope{data=[]repository.ApiKey}
+// @Failure		500	{object}	main.WriteJSONError.envelope
+// @Router			/admin/api-keys [get]
+func (app *application) GetApiKeysHandler(w http.ResponseWriter, r *http.Request) {
+	keys, err := app.repository.ApiKeys.GetApiKeys(r.Context())
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, keys); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// @Summary		Revoke API key
+// @Description	Revoke partner API key by ID
+// @Tags			Admin
+// @Param			keyID	path	int	true	"API key ID"
+// @Security		JWT
+// @Success		204
+// @Failure		404	{object}	main.WriteJSONError.envelope
+// @Failure		500	{object}	main.WriteJSONError.envelope
+// @Router			/admin/api-keys/{keyID} [delete]
+func (app *application) RevokeApiKeyHandler(w http.ResponseWriter, r *http.Request) {
+	key := GetApiKeyFromContext(r)
+
+	if err := app.repository.ApiKeys.Revoke(r.Context(), key.Id); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.recordAudit(r, "revoke", "api_key", key.Id, key, nil)
+
+	if err := app.responseNoContent(w); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+func (app *application) ApiKeyContentMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keyId := chi.URLParam(r, "keyID")
+
+		id, err := strconv.Atoi(keyId)
+		if err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+
+		ctx := r.Context()
+
+		key, err := app.repository.ApiKeys.GetByID(ctx, id)
+		if err != nil {
+			switch err {
+			case repository.ErrNoRows:
+				app.notFoundResponse(w, r, err)
+			default:
+				app.internalServerError(w, r, err)
+			}
+
+			return
+		}
+
+		ctx = context.WithValue(ctx, apiKeyRecordKey, key)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func GetApiKeyFromContext(r *http.Request) *repository.ApiKey {
+	return r.Context().Value(apiKeyRecordKey).(*repository.ApiKey)
+}
+
+// DenyApiKey keeps the partner keys out of the account of their owner, e.g. the export of its personal data,
+// the scopes only limit the permissions and these routes need none.
+func (app *application) DenyApiKey(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Value(apiKeyCtx).(*repository.ApiKey); ok {
+			log.Warn("blocked api key request", "path", r.URL, "method", r.Method)
+
+			WriteJSONError(w, http.StatusForbidden, &[]string{ErrApiKeyNotAllowed.Error()})
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
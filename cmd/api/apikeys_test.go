@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/faizisyellow/gobali/internal/auth"
+	"github.com/faizisyellow/gobali/internal/repository"
+)
+
+// stubApiKeys finds the one key, the other methods are never reached.
+type stubApiKeys struct {
+	*repository.ApiKeysRepository
+	key *repository.ApiKey
+}
+
+func (s *stubApiKeys) GetActiveByPrefix(ctx context.Context, prefix string) (*repository.ApiKey, error) {
+	if prefix != s.key.Prefix {
+		return nil, repository.ErrNoRows
+	}
+
+	return s.key, nil
+}
+
+func (s *stubApiKeys) Touch(ctx context.Context, id int) error {
+	return nil
+}
+
+// stubUsers finds the users by id and counts the role changes, the other methods are never reached.
+type stubUsers struct {
+	*repository.UserRepository
+	users       map[int]repository.User
+	roleChanges int
+}
+
+func (s *stubUsers) GetByID(ctx context.Context, userId int) (*repository.User, error) {
+	user, ok := s.users[userId]
+	if !ok {
+		return nil, repository.ErrNoRows
+	}
+
+	user.Role.Permissions = append([]string{}, user.Role.Permissions...)
+
+	return &user, nil
+}
+
+func TestDenyApiKey(t *testing.T) {
+	key, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	owner := repository.User{Id: 7, Username: "partner", Role: repository.Role{Name: "admin", Permissions: []string{"bookings:read", "users:manage"}}}
+
+	app := &application{
+		repository: repository.Repository{
+			ApiKeys: &stubApiKeys{key: &repository.ApiKey{Id: 1, Prefix: prefix, Hash: hash, UserId: owner.Id, Scopes: []string{"bookings:read"}}},
+			Users:   &stubUsers{users: map[int]repository.User{owner.Id: owner}},
+		},
+	}
+
+	router := app.mount()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+	}{
+		{name: "should deny the export of the personal data", method: http.MethodGet, path: "/v1/users/me/export"},
+		{name: "should deny the deletion of the account", method: http.MethodDelete, path: "/v1/users/me/"},
+		{name: "should deny the consents", method: http.MethodPut, path: "/v1/users/me/consents"},
+		{name: "should deny the profile", method: http.MethodGet, path: "/v1/users/profile"},
+		{name: "should deny the profile update", method: http.MethodPut, path: "/v1/users/profile"},
+		{name: "should deny the email change", method: http.MethodPost, path: "/v1/users/profile/email"},
+		{name: "should deny the sessions", method: http.MethodGet, path: "/v1/users/sessions"},
+		{name: "should deny revoking a session", method: http.MethodDelete, path: "/v1/users/sessions/abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Authorization", "ApiKey "+key)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusForbidden {
+				t.Errorf("expected: %v but got: %v %s", http.StatusForbidden, rr.Code, rr.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"expvar"
+	"net"
+	"net/http"
+
+	"github.com/charmbracelet/log"
+	"github.com/faizisyellow/gobali/internal/repository"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+const (
+	auditCreate = "create"
+	auditUpdate = "update"
+	auditDelete = "delete"
+)
+
+// auditFailures counts the events recordAudit lost, published on /v1/configs/debug/vars.
+var auditFailures = expvar.NewInt("audit_failures")
+
+// recordAudit stores who changed which entity once the change is committed, so the log is best-effort:
+// a failure is logged and counted in auditFailures, it never fails the request.
+// A change that must not go unrecorded passes its auditEvent to the repository instead, like deleting a user.
+func (app *application) recordAudit(r *http.Request, action, entity string, entityId int, before, after any) {
+	event := app.auditEvent(r, action, entity, entityId, before, after)
+
+	if err := app.repository.Audit.Create(r.Context(), event); err != nil {
+		auditFailures.Add(1)
+		log.Error("error recording audit event", "action", action, "entity", entity, "id", entityId, "error", err.Error())
+	}
+}
+
+// auditEvent builds the event of a change, for the repositories recording it with the change itself.
+func (app *application) auditEvent(r *http.Request, action, entity string, entityId int, before, after any) *repository.AuditEvent {
+	ctx := r.Context()
+
+	event := &repository.AuditEvent{
+		Action:    action,
+		Entity:    entity,
+		EntityId:  entityId,
+		RequestId: middleware.GetReqID(ctx),
+		IP:        clientIP(r),
+	}
+
+	if user, ok := ctx.Value(userCtx).(*repository.User); ok {
+		event.ActorId = &user.Id
+	}
+
+	if impersonatorId, ok := getImpersonatorFromContext(r); ok {
+		event.ImpersonatorId = &impersonatorId
+	}
+
+	var err error
+
+	if before != nil {
+		if event.Before, err = json.Marshal(before); err != nil {
+			log.Error("error encoding audit snapshot", "entity", entity, "error", err.Error())
+		}
+	}
+
+	if after != nil {
+		if event.After, err = json.Marshal(after); err != nil {
+			log.Error("error encoding audit snapshot", "entity", entity, "error", err.Error())
+		}
+	}
+
+	return event
+}
+
+// clientIP strips the port, middleware.RealIP already replaced RemoteAddr with the forwarded address.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// @Summary		Get audit events
+// @Description	Get who changed what, filter by actor, action, entity and day range. The events are recorded after the change, a lost event is counted in audit_failures of the debug vars
+// @Tags			Admin
+// @Produce		json
+// @Param			limit		query	string	false	"limit each page"
+// @Param			offset		query	string	false	"skip rows"
+// @Param			cursor		query	string	false	"cursor of the next or prev page from meta"
+// @Param			sort		query	string	false	"sort latest(desc), older(asc)"
+// @Param			actor_id	query	int		false	"user who made the change"
+// @Param			action		query	string	false	"create, update, delete, ..."
+// @Param			entity		query	string	false	"villa, booking, user, ..."
+// @Param			entity_id	query	int		false	"ID of the entity"
+// @Param			from		query	string	false	"from day (2006-01-02)"
+// @Param			to			query	string	false	"to day inclusive (2006-01-02)"
+// @Security		JWT
+// @Success		200	{object}	main.jsonPageResponse.envelope{data=[]repository.AuditEvent,meta=pagination.Page}
+// @Failure		400	{object}	main.WriteJSONError.envelope
+// @Failure		500	{object}	main.WriteJSONError.envelope
+// @Router			/admin/audit [get]
+func (app *application) GetAuditEventsHandler(w http.ResponseWriter, r *http.Request) {
+	query, err := repository.AuditList.Parse(r.URL.Query())
+
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	events, page, err := app.repository.Audit.GetEvents(r.Context(), query)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonPageResponse(w, r, http.StatusOK, events, page); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/faizisyellow/gobali/internal/mailer"
@@ -14,8 +15,8 @@ import (
 )
 
 type RegisterPayload struct {
-	Username string `json:"username" validate:"required"`
-	Email    string `json:"email" validate:"required,min=5,email"`
+	Username string `json:"username" validate:"required,max=64"`
+	Email    string `json:"email" validate:"required,min=5,max=254,email"`
 	Password string `json:"password" validate:"required,min=5,withspace,validpassword"`
 }
 
@@ -95,7 +96,7 @@ func (app *application) RegisterHandler(w http.ResponseWriter, r *http.Request)
 		log.Error("error sending welcome email", "error", err.Error())
 
 		// rollback user creation if email fails (SAGA pattern)
-		if err := app.repository.Users.Delete(ctx, user.Id); err != nil {
+		if err := app.repository.Users.Delete(ctx, user.Id, nil); err != nil {
 			log.Error("error deleting user while rollback", "error", err.Error())
 		}
 
@@ -154,21 +155,73 @@ func (app *application) LoginHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	signedToken, err := app.issueToken(r, user)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, signedToken); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// issueToken starts a session and signs its token, every login method goes through it.
+func (app *application) issueToken(r *http.Request, user *repository.User) (string, error) {
+	return app.signSession(r, user, nil, app.configs.auth.token.exp)
+}
+
+// signSession records the session, an impersonator acts as the user and is carried as the "act" claim.
+func (app *application) signSession(r *http.Request, user, impersonator *repository.User, exp time.Duration) (string, error) {
+	expiresAt := time.Now().Add(exp)
+
+	userAgent := r.UserAgent()
+	if len(userAgent) > 512 {
+		userAgent = userAgent[:512]
+	}
+
+	session := &repository.Session{
+		Id:        uuid.New().String(),
+		UserId:    user.Id,
+		IP:        clientIP(r),
+		UserAgent: userAgent,
+	}
+
+	if impersonator != nil {
+		session.ImpersonatorId = &impersonator.Id
+	}
+
+	if err := app.repository.Sessions.Create(r.Context(), session, expiresAt); err != nil {
+		return "", err
+	}
+
 	claims := jwt.MapClaims{
 		"iss":  app.configs.auth.token.iss,
 		"sub":  app.configs.auth.token.sub,
-		"exp":  app.configs.auth.token.exp,
+		"exp":  expiresAt.Unix(),
 		"id":   user.Id,
+		"sid":  session.Id,
 		"role": user.Role.Name,
 	}
 
-	signedToken, err := app.authentication.GenerateToken(claims)
-	if err != nil {
-		app.internalServerError(w, r, err)
-		return
+	if impersonator != nil {
+		claims["act"] = impersonator.Id
 	}
 
-	if err := app.jsonResponse(w, http.StatusOK, signedToken); err != nil {
+	return app.authentication.GenerateToken(claims)
+}
+
+// @Summary		JSON Web Key Set
+// @Description	Public keys to verify tokens issued by this server
+// @Tags			Auth
+// @Produce		json
+// @Success		200	{object}	auth.JWKSet
+// @Router			/.well-known/jwks.json [GET]
+func (app *application) JWKSHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+
+	if err := writeJSON(w, http.StatusOK, app.authentication.JWKS()); err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
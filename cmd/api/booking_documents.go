@@ -0,0 +1,68 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/faizisyellow/gobali/internal/uploader"
+)
+
+// documentsDst is the private destination of the guest documents, they are never served publicly.
+var documentsDst = uploader.Private("documents")
+
+// @Summary		Upload Booking Documents
+// @Description	Upload the guest documents of the check-in registration, e.g. a passport
+// @Tags			Bookings
+// @Produce		json
+// @Accept			mpfd
+// @Param			bookingID	path		int		true	"booking id"
+// @Param			documents	formData	file	true	"PDF, JPEG or PNG file"
+// @Security		JWT
+// @Success		201	{object}	main.jsonResponse.envelope{data=string}
+// @Failure		400	{object}	main.WriteJSONError.envelope
+// @Failure		500	{object}	main.WriteJSONError.envelope
+// @Router			/bookings/{bookingID}/documents [post]
+func (app *application) AddBookingDocumentsHandler(w http.ResponseWriter, r *http.Request) {
+	booking := GetBookingFromContext(r)
+	names := r.Context().Value(filenameKey).([]string)
+
+	// the files of a failed save are left to the garbage collector, another booking may share them
+	if err := app.repository.Bookings.AddDocuments(r.Context(), booking.Id, names); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.recordAudit(r, auditUpdate, "booking", booking.Id, nil, map[string][]string{"documents": names})
+
+	if err := app.jsonResponse(w, http.StatusCreated, "documents uploaded successfully"); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// @Summary		Get Booking Documents
+// @Description	Get the guest documents of the booking with signed URLs that expire
+// @Tags			Bookings
+// @Produce		json
+// @Param			bookingID	path		int	true	"booking id"
+// @Security		JWT
+// @Success		200	{object}	main.jsonResponse.envelope{data=[]repository.BookingDocument}
+// @Failure		500	{object}	main.WriteJSONError.envelope
+// @Router			/bookings/{bookingID}/documents [get]
+func (app *application) GetBookingDocumentsHandler(w http.ResponseWriter, r *http.Request) {
+	booking := GetBookingFromContext(r)
+
+	documents, err := app.repository.Bookings.GetDocuments(r.Context(), booking.Id)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	for _, document := range documents {
+		document.URL = app.privateFileURL(documentsDst, document.Name, document.Name)
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, documents); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
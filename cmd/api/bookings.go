@@ -41,8 +41,9 @@ const (
 )
 
 var (
-	ErrAlreadyBooked error      = errors.New("this villa already booked between these days")
-	bookingctx       bookingkey = "bookings"
+	ErrAlreadyBooked     error      = errors.New("this villa already booked between these days")
+	ErrVillaNotAvailable error      = errors.New("villa is not available")
+	bookingctx           bookingkey = "bookings"
 )
 
 var Status = map[StatusBooking]string{
@@ -97,6 +98,8 @@ func (app *application) CheckInHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	app.recordAudit(r, "check_in", "booking", booking.Id, booking, map[string]string{"status": payload.Status})
+
 	if err := app.jsonResponse(w, http.StatusCreated, "check in successfully"); err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -149,6 +152,8 @@ func (app *application) CheckOutHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	app.recordAudit(r, "check_out", "booking", booking.Id, booking, map[string]string{"status": payload.Status})
+
 	if err := app.jsonResponse(w, http.StatusCreated, "check out successfully"); err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -202,6 +207,19 @@ func (app *application) CreateBookingHandler(w http.ResponseWriter, r *http.Requ
 	}
 
 	ctx := r.Context()
+
+	// the villas the public does not see can not be booked
+	villa, err := app.repository.Villas.GetById(ctx, payload.VillaId)
+	if err != nil && !errors.Is(err, repository.ErrNoRows) {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if villa == nil || !villa.Visible {
+		app.badRequestResponse(w, r, ErrVillaNotAvailable)
+		return
+	}
+
 	bookingExist, err := app.repository.Bookings.GetBookingVillaByDate(ctx, payload.StartAt, payload.EndAt, payload.VillaId)
 	if err != nil {
 		if !errors.Is(err, repository.ErrNoRows) {
@@ -232,6 +250,8 @@ func (app *application) CreateBookingHandler(w http.ResponseWriter, r *http.Requ
 			app.internalServerError(w, r, err)
 			return
 		}
+
+		app.recordAudit(r, auditCreate, "booking", newBook.Id, nil, newBook)
 	} else {
 		app.badRequestResponse(w, r, ErrAlreadyBooked)
 		return
@@ -253,32 +273,31 @@ func (app *application) CreateBookingHandler(w http.ResponseWriter, r *http.Requ
 //
 //	@Param			limit	query		string	false	"limit pages"
 //	@Param			offset	query		string	false	"skip rows"
+//	@Param			cursor	query		string	false	"cursor of the next or prev page from meta"
 //
-//	@Param			sort	query		string	false	"sort latest(desc) older(asc)"
+//	@Param			sort	query		string	false	"sort latest(desc), older(asc) or by fields e.g. -start_at or total_price"
+//	@Param			status	query		string	false	"statuses of the booking, comma separated"
+//	@Param			villa_id	query		int	false	"villa id of the booking"
 //
-//	@Success		200		{object}	main.jsonResponse.envelope{data=[]repository.Booking}
+//	@Success		200		{object}	main.jsonPageResponse.envelope{data=[]repository.Booking,meta=pagination.Page}
 //	@Failure		500		{object}	main.WriteJSONError.envelope
 //	@Router			/bookings [get]
 func (app *application) GetBookingsHandler(w http.ResponseWriter, r *http.Request) {
 
-	query, err := repository.PaginatedBookingsQuery{
-		Limit:  5,
-		Offset: 0,
-		Sort:   "asc",
-	}.Parse(r)
+	query, err := repository.BookingsList.Parse(r.URL.Query())
 
 	if err != nil {
 		app.badRequestResponse(w, r, err)
 		return
 	}
 
-	bookings, err := app.repository.Bookings.GetBookings(r.Context(), query)
+	bookings, page, err := app.repository.Bookings.GetBookings(r.Context(), query)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
 
-	if err := app.jsonResponse(w, http.StatusOK, bookings); err != nil {
+	if err := app.jsonPageResponse(w, r, http.StatusOK, bookings, page); err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
@@ -324,6 +343,8 @@ func (app *application) DeleteBookingHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	app.recordAudit(r, auditDelete, "booking", booking.Id, booking, nil)
+
 	if err := app.responseNoContent(w); err != nil {
 		app.internalServerError(w, r, err)
 		return
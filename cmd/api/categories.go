@@ -48,7 +48,8 @@ func (app *application) CreateCategoryHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	if err := app.repository.Categories.Create(r.Context(), payload.Name); err != nil {
+	id, err := app.repository.Categories.Create(r.Context(), payload.Name)
+	if err != nil {
 		switch err {
 		case repository.ErrDuplicateCategory:
 			app.conflictErrorResponse(w, r, err)
@@ -59,6 +60,8 @@ func (app *application) CreateCategoryHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	app.recordAudit(r, auditCreate, "category", id, nil, payload)
+
 	if err := app.jsonResponse(w, http.StatusCreated, "category created successfull"); err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -111,26 +114,24 @@ func (app *application) GetCategoryByIDHandler(w http.ResponseWriter, r *http.Re
 //
 //	@Param			limit	query		string	false	"limit pages"
 //	@Param			offset	query		string	false	"skip rows"
+//	@Param			cursor	query		string	false	"cursor of the next or prev page from meta"
 //
-// @Param			sort	query		string	false	"sort latest(desc) older(asc)"
+// @Param			sort	query		string	false	"sort latest(desc), older(asc) or by fields e.g. name"
+// @Param			name	query		string	false	"name of the category contains"
 //
-//	@Success		200		{object}	main.jsonResponse.envelope{data=[]CategoryResponse}
+//	@Success		200		{object}	main.jsonPageResponse.envelope{data=[]CategoryResponse,meta=pagination.Page}
 //	@Failure		404		{object}	main.WriteJSONError.envelope
 //	@Failure		500		{object}	main.WriteJSONError.envelope
 //	@Router			/categories [GET]
 func (app *application) GetCategoriesHandler(w http.ResponseWriter, r *http.Request) {
-	query, err := repository.PaginatedCategoriesQuery{
-		Limit:  10,
-		Offset: 0,
-		Sort:   "asc",
-	}.Parse(r)
+	query, err := repository.CategoriesList.Parse(r.URL.Query())
 
 	if err != nil {
 		app.badRequestResponse(w, r, err)
 		return
 	}
 
-	cats, err := app.repository.Categories.GetCategories(r.Context(), query)
+	cats, page, err := app.repository.Categories.GetCategories(r.Context(), query)
 	if err != nil {
 		app.internalServerError(w, r, err)
 
@@ -149,7 +150,7 @@ func (app *application) GetCategoriesHandler(w http.ResponseWriter, r *http.Requ
 		catsRes = append(catsRes, newCat)
 	}
 
-	if err := app.jsonResponse(w, http.StatusOK, catsRes); err != nil {
+	if err := app.jsonPageResponse(w, r, http.StatusOK, catsRes, page); err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
@@ -218,6 +219,8 @@ func (app *application) UpdateCategoryHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	app.recordAudit(r, auditUpdate, "category", id, cat, newCat)
+
 	if err := app.jsonResponse(w, http.StatusCreated, "update category successfull"); err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -261,6 +264,8 @@ func (app *application) DeleteCategoryHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	app.recordAudit(r, auditDelete, "category", cat.Id, cat, nil)
+
 	if err := app.responseNoContent(w); err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/faizisyellow/gobali/internal/uploader"
+	"github.com/go-chi/chi/v5"
+)
+
+// @Summary		Get private file
+// @Description	Stream a private file through a signed URL, the signature replaces the authentication
+// @Tags			Files
+// @Produce		octet-stream
+// @Param			group		path	string	true	"File group"
+// @Param			name		path	string	true	"File name"
+// @Param			expires		query	int		true	"Unix time the URL expires at"
+// @Param			disposition	query	string	true	"Content disposition"
+// @Param			signature	query	string	true	"Signature of the URL"
+// @Success		200
+// @Failure		403	{object}	main.WriteJSONError.envelope
+// @Failure		404	{object}	main.WriteJSONError.envelope
+// @Failure		500	{object}	main.WriteJSONError.envelope
+// @Router			/files/private/{group}/{name} [get]
+func (app *application) PrivateFileHandler(w http.ResponseWriter, r *http.Request) {
+	group, name := chi.URLParam(r, "group"), chi.URLParam(r, "name")
+
+	disposition, err := app.signer.Verify(uploader.Private(group), name, r.URL.Query(), time.Now())
+	if err != nil {
+		app.forbiddenErrorResponse(w, r)
+		return
+	}
+
+	file, err := app.upload.Open(r.Context(), uploader.Private(group), name)
+	if err != nil {
+		switch {
+		case errors.Is(err, uploader.ErrFileNotFound):
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+
+		return
+	}
+
+	defer file.Close()
+
+	// the type is sniffed like on upload, the first bytes are written after the headers
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", uploader.DetectContentType(head[:n]))
+	w.Header().Set("Content-Disposition", disposition)
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	w.WriteHeader(http.StatusOK)
+
+	// the status is sent already, a failure can only be logged
+	if _, err := w.Write(head[:n]); err != nil {
+		log.Error("error writing private file", "group", group, "name", name, "error", err.Error())
+		return
+	}
+
+	if _, err := io.Copy(w, file); err != nil {
+		log.Error("error writing private file", "group", group, "name", name, "error", err.Error())
+	}
+}
+
+// privateFileURL signs a private file for the guest or admin it is shown to.
+func (app *application) privateFileURL(dst, name, filename string) string {
+	return app.signer.URL(dst, name, app.configs.upload.signedURLExp, filename)
+}
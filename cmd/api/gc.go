@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/faizisyellow/gobali/internal/uploader"
+)
+
+// collectUploads removes the villa images and the guest documents nothing refers to anymore every interval,
+// `go run ./cmd/gc -dry-run` reports them without the server.
+func (app *application) collectUploads(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+
+		app.collectGroup(ctx, "villas", app.referencedImages, func(ctx context.Context, image string) (bool, error) {
+			return app.repository.Blobs.RemoveUnreferenced(ctx, app.villaImages(), image)
+		})
+		app.collectGroup(ctx, documentsDst, app.repository.Bookings.GetDocumentNames, nil)
+
+		cancel()
+	}
+}
+
+func (app *application) collectGroup(ctx context.Context, dst string, referencedBy func(context.Context) ([]string, error), remove uploader.Remover) {
+	referenced, err := referencedBy(ctx)
+	if err != nil {
+		log.Error("upload gc", "group", dst, "error", err)
+		return
+	}
+
+	report, err := uploader.CollectGarbage(ctx, app.upload, dst, referenced, app.configs.upload.gcGrace, false, remove)
+	if err != nil {
+		log.Error("upload gc", "group", dst, "error", err)
+	}
+
+	if report != nil {
+		log.Info("upload gc", "group", dst, "scanned", report.Scanned, "deleted", report.Deleted, "bytes", report.Bytes)
+	}
+}
+
+// referencedImages are the images of every villa and the shared images still counted as referenced.
+func (app *application) referencedImages(ctx context.Context) ([]string, error) {
+	names, err := app.repository.Villas.GetImageNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	counted, err := app.repository.Blobs.GetReferenced(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(names, counted...), nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/faizisyellow/gobali/internal/repository"
+)
+
+// impersonation tokens are short-lived, support only needs them for one ticket.
+const impersonationTokenExp = 15 * time.Minute
+
+type impersonatorKey string
+
+var impersonatorCtx impersonatorKey = "impersonator"
+
+var (
+	ErrImpersonateSelf       = errors.New("can not impersonate yourself")
+	ErrImpersonateInactive   = errors.New("can not impersonate an inactive user")
+	ErrImpersonationReadOnly = errors.New("not allowed while impersonating")
+)
+
+type ImpersonationResponse struct {
+	Token     string `json:"token"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+// @Summary		Impersonate user
+// @Description	Issue a short-lived token acting as the user for customer support. The user must not have more permissions than the admin
+// @Tags			Admin
+// @Produce		json
+// @Param			userID	path	int	true	"User ID"
+// @Security		JWT
+// @Success		201	{object}	main.jsonResponse.envelope{data=ImpersonationResponse}
+// @Failure		400	{object}	main.WriteJSONError.envelope
+// @Failure		403	{object}	main.WriteJSONError.envelope
+// @Failure		404	{object}	main.WriteJSONError.envelope
+// @Failure		500	{object}	main.WriteJSONError.envelope
+// @Router			/admin/users/{userID}/impersonate [post]
+func (app *application) ImpersonateUserHandler(w http.ResponseWriter, r *http.Request) {
+	actor := getUserFromContext(r)
+	account := GetAccountFromContext(r)
+
+	if account.Id == actor.Id {
+		app.badRequestResponse(w, r, ErrImpersonateSelf)
+		return
+	}
+
+	// load the permissions of the user, only active users can sign in
+	user, err := app.repository.Users.GetByID(r.Context(), account.Id)
+	if err != nil {
+		switch err {
+		case repository.ErrNoRows:
+			app.badRequestResponse(w, r, ErrImpersonateInactive)
+		default:
+			app.internalServerError(w, r, err)
+		}
+
+		return
+	}
+
+	// impersonating must not grant the actor anything they can not do already
+	if grantsMore(&user.Role, &actor.Role) {
+		app.forbiddenErrorResponse(w, r)
+		return
+	}
+
+	signedToken, err := app.signSession(r, user, actor, impersonationTokenExp)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.recordAudit(r, "impersonate", "user", user.Id, nil, nil)
+
+	response := ImpersonationResponse{
+		Token:     signedToken,
+		ExpiresAt: time.Now().Add(impersonationTokenExp).Format(time.RFC3339),
+	}
+
+	if err := app.jsonResponse(w, http.StatusCreated, response); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// DenyImpersonation blocks destructive or account changing endpoints while impersonating.
+func (app *application) DenyImpersonation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := getImpersonatorFromContext(r); ok {
+			app.impersonationForbiddenResponse(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (app *application) impersonationForbiddenResponse(w http.ResponseWriter, r *http.Request) {
+	log.Warn("blocked impersonated request", "path", r.URL, "method", r.Method)
+
+	WriteJSONError(w, http.StatusForbidden, &[]string{ErrImpersonationReadOnly.Error()})
+}
+
+func getImpersonatorFromContext(r *http.Request) (int, bool) {
+	impersonatorId, ok := r.Context().Value(impersonatorCtx).(int)
+
+	return impersonatorId, ok
+}
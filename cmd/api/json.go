@@ -8,6 +8,7 @@ import (
 	"strings"
 	"unicode"
 
+	"github.com/faizisyellow/gobali/internal/pagination"
 	"github.com/go-playground/validator/v10"
 )
 
@@ -94,6 +95,18 @@ func (app *application) jsonResponse(w http.ResponseWriter, status int, data any
 	return writeJSON(w, status, &envelope{Data: data})
 }
 
+// jsonPageResponse writes a page of a list, the meta and the Link header tell the client how to get the other pages.
+func (app *application) jsonPageResponse(w http.ResponseWriter, r *http.Request, status int, data any, page pagination.Page) error {
+	type envelope struct {
+		Data any             `json:"data"`
+		Meta pagination.Page `json:"meta"`
+	}
+
+	pagination.SetHeaders(w, r, page)
+
+	return writeJSON(w, status, &envelope{Data: data, Meta: page})
+}
+
 func (app *application) responseNoContent(w http.ResponseWriter) error {
 
 	w.WriteHeader(http.StatusNoContent)
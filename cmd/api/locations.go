@@ -10,13 +10,17 @@ import (
 )
 
 type CreateLocationPayload struct {
-	Area string `json:"area" validate:"required,min=3"`
+	Area      string   `json:"area" validate:"required,min=3"`
+	Latitude  *float64 `json:"latitude" validate:"required_with=Longitude,omitempty,gte=-90,lte=90"`
+	Longitude *float64 `json:"longitude" validate:"required_with=Latitude,omitempty,gte=-180,lte=180"`
 }
 
 type LocationResponse struct {
-	Id        int    `json:"id"`
-	Area      string `json:"area"`
-	CreatedAt string `json:"created_at"`
+	Id        int      `json:"id"`
+	Area      string   `json:"area"`
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+	CreatedAt string   `json:"created_at"`
 }
 
 type UpdateLocationPayload struct {
@@ -48,7 +52,11 @@ func (app *application) CreateLocationHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	err := app.repository.Location.Create(r.Context(), payload.Area)
+	id, err := app.repository.Location.Create(r.Context(), &repository.Location{
+		Area:      payload.Area,
+		Latitude:  payload.Latitude,
+		Longitude: payload.Longitude,
+	})
 	if err != nil {
 		switch err {
 		case repository.ErrDuplicateLocation:
@@ -60,6 +68,8 @@ func (app *application) CreateLocationHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	app.recordAudit(r, auditCreate, "location", id, nil, payload)
+
 	if err := app.jsonResponse(w, http.StatusCreated, "create location successfull"); err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -111,26 +121,24 @@ func (app *application) GetLocationByIdHandler(w http.ResponseWriter, r *http.Re
 //
 //	@Param			limit	query		string	false	"limit pages"
 //	@Param			offset	query		string	false	"skip rows"
+//	@Param			cursor	query		string	false	"cursor of the next or prev page from meta"
 //
-//	@Param			sort	query		string	false	"sort latest(desc) older(asc)"
+//	@Param			sort	query		string	false	"sort latest(desc), older(asc) or by fields e.g. area"
+//	@Param			area	query		string	false	"area of the location contains"
 //
-//	@Success		200		{object}	main.jsonResponse.envelope{data=[]LocationResponse{}}
+//	@Success		200		{object}	main.jsonPageResponse.envelope{data=[]LocationResponse{},meta=pagination.Page}
 //	@Failure		500		{object}	main.WriteJSONError.envelope
 //	@Router			/locations [get]
 func (app *application) GetLocationsHandler(w http.ResponseWriter, r *http.Request) {
 
-	query, err := repository.PaginatedLocationQuery{
-		Limit:  10,
-		Offset: 0,
-		Sort:   "asc",
-	}.Parse(r)
+	query, err := repository.LocationsList.Parse(r.URL.Query())
 
 	if err != nil {
 		app.badRequestResponse(w, r, err)
 		return
 	}
 
-	location, err := app.repository.Location.GetLocations(r.Context(), query)
+	location, page, err := app.repository.Location.GetLocations(r.Context(), query)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -143,12 +151,14 @@ func (app *application) GetLocationsHandler(w http.ResponseWriter, r *http.Reque
 
 		loc.Id = l.Id
 		loc.Area = l.Area
+		loc.Latitude = l.Latitude
+		loc.Longitude = l.Longitude
 		loc.CreatedAt = l.CreatedAt
 
 		locsRes = append(locsRes, loc)
 	}
 
-	if err := app.jsonResponse(w, http.StatusOK, locsRes); err != nil {
+	if err := app.jsonPageResponse(w, r, http.StatusOK, locsRes, page); err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
@@ -205,19 +215,28 @@ func (app *application) UpdateLocationHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	if payload.Area == location.Area {
+	// the coordinates can be set without renaming the area
+	if payload.Area == location.Area && payload.Latitude == nil {
 		alreadyExist := errors.New("can not update from previous area")
 		app.conflictErrorResponse(w, r, alreadyExist)
 		return
 	}
 
+	before := *location
+
 	location.Area = payload.Area
 
+	if payload.Latitude != nil {
+		location.Latitude, location.Longitude = payload.Latitude, payload.Longitude
+	}
+
 	if err := app.repository.Location.Update(ctx, location); err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
 
+	app.recordAudit(r, auditUpdate, "location", location.Id, before, location)
+
 	if err := app.jsonResponse(w, http.StatusCreated, "update location successfull"); err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -262,6 +281,8 @@ func (app *application) DeleteLocationHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	app.recordAudit(r, auditDelete, "location", location.Id, location, nil)
+
 	if err := app.responseNoContent(w); err != nil {
 		app.internalServerError(w, r, err)
 		return
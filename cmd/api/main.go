@@ -1,8 +1,10 @@
 package main
 
 import (
+	"crypto/rand"
 	"expvar"
 	"runtime"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/log"
@@ -11,7 +13,9 @@ import (
 	"github.com/faizisyellow/gobali/internal/db"
 	"github.com/faizisyellow/gobali/internal/env"
 	"github.com/faizisyellow/gobali/internal/mailer"
+	"github.com/faizisyellow/gobali/internal/oidc"
 	"github.com/faizisyellow/gobali/internal/repository"
+	"github.com/faizisyellow/gobali/internal/search"
 	"github.com/faizisyellow/gobali/internal/uploader"
 )
 
@@ -33,6 +37,11 @@ const version = "0.1"
 // @in							header
 // @name						Authorization
 
+// @securityDefinitions.apiKey	ApiKey
+// @in							header
+// @name						Authorization
+// @description				Partner key as "ApiKey gb_<prefix>_<secret>"
+
 // @schemes	http https
 //
 // @BasePath	/v1
@@ -59,10 +68,32 @@ func main() {
 		db:        dbConfig{addr: e.GetString("DB_ADDRESS", "nil"), maxOpenConn: 30, maxIdleConn: 30, maxIdleTime: "15m"},
 		mail:      mailConf,
 		clientURL: e.GetString("CLIENT_URL", "localhost:5173"),
-		upload:    uploadConfig{baseDir: "./internal/assets/"},
+		upload: uploadConfig{
+			storage:    e.GetString("UPLOAD_STORAGE", "local"),
+			baseDir:    "./internal/assets/",
+			privateDir: e.GetString("UPLOAD_PRIVATE_DIR", "./internal/private/"),
+			baseURL:    e.GetString("UPLOAD_BASE_URL", "http://"+e.GetString("ADDRESS", "localhost:8080")+"/files"),
+			s3: uploader.S3Config{
+				Endpoint:  e.GetString("S3_ENDPOINT", ""),
+				Region:    e.GetString("S3_REGION", "us-east-1"),
+				Bucket:    e.GetString("S3_BUCKET", ""),
+				AccessKey: e.GetString("S3_ACCESS_KEY", ""),
+				SecretKey: e.GetString("S3_SECRET_KEY", ""),
+				PublicURL: e.GetString("S3_PUBLIC_URL", ""),
+			},
+			signingKey:   e.GetString("FILES_SIGNING_KEY", ""),
+			privateURL:   e.GetString("PRIVATE_FILES_URL", "http://"+e.GetString("ADDRESS", "localhost:8080")+"/v1/files/private"),
+			signedURLExp: time.Minute * time.Duration(e.GetInt("SIGNED_URL_EXP_MINUTES", 15)),
+			gcInterval:   time.Hour * time.Duration(e.GetInt("UPLOAD_GC_INTERVAL_HOURS", 0)),
+			gcGrace:      time.Hour * time.Duration(e.GetInt("UPLOAD_GC_GRACE_HOURS", 24)),
+		},
+		cache: cacheConfig{ttl: time.Second * time.Duration(e.GetInt("CACHE_TTL_SECONDS", 30))},
 		auth: authConfig{
 			tokenConfig{
 				privateKey: e.GetString("PRIVATE_KEY", ""),
+				keysDir:    e.GetString("JWT_KEYS_DIR", ""),
+				activeKid:  e.GetString("JWT_ACTIVE_KID", ""),
+				keyGrace:   time.Hour * time.Duration(e.GetInt("JWT_KEY_GRACE_HOURS", 24*3)),
 				iss:        "auth-server",
 				sub:        "user",
 				exp:        time.Hour * 24 * 3,
@@ -74,6 +105,24 @@ func main() {
 		},
 	}
 
+	// every provider in OIDC_PROVIDERS reads OIDC_<NAME>_ISSUER, _CLIENT_ID, _CLIENT_SECRET and _REDIRECT_URL
+	for _, name := range strings.Split(e.GetString("OIDC_PROVIDERS", ""), ",") {
+		name = strings.TrimSpace(strings.ToLower(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+
+		conf.oidc = append(conf.oidc, oidc.Config{
+			Name:         name,
+			Issuer:       e.GetString(prefix+"ISSUER", ""),
+			ClientID:     e.GetString(prefix+"CLIENT_ID", ""),
+			ClientSecret: e.GetString(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:  e.GetString(prefix+"REDIRECT_URL", conf.clientURL+"/oauth/"+name+"/callback"),
+		})
+	}
+
 	db, err := db.New(conf.db.addr, conf.db.maxOpenConn, conf.db.maxIdleConn, conf.db.maxIdleTime)
 	if err != nil {
 		log.Fatal(err)
@@ -85,16 +134,64 @@ func main() {
 
 	sendGridMail := mailer.NewSendGrid(conf.mail.sendGrid.apiKey, conf.mail.fromEmail)
 
-	localUpload := uploader.NewLocalUpload(conf.upload.baseDir)
+	upload, err := uploader.New(conf.upload.storage, conf.upload.baseDir, conf.upload.privateDir, conf.upload.baseURL, conf.upload.s3)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	signingKey := []byte(conf.upload.signingKey)
+
+	// the URLs stop working on restart and between replicas without a configured key
+	if len(signingKey) == 0 {
+		signingKey = make([]byte, 32)
+		if _, err := rand.Read(signingKey); err != nil {
+			log.Fatal(err)
+		}
+
+		log.Warn("FILES_SIGNING_KEY is not set, signed file URLs use a random key")
+	}
+
+	var authenticator auth.Authenticator = auth.NewJwtAuth(conf.auth.token.privateKey, conf.auth.token.iss, conf.auth.token.sub)
+
+	// asymmetric keys take over the shared secret once a key directory is configured
+	if conf.auth.token.keysDir != "" {
+		keys, err := auth.LoadKeyDir(conf.auth.token.keysDir, conf.auth.token.activeKid)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		authenticator, err = auth.NewKeyRingAuth(keys, conf.auth.token.keyGrace, conf.auth.token.iss, conf.auth.token.sub)
+		if err != nil {
+			log.Fatal(err)
+		}
 
-	jwtAuth := auth.NewJwtAuth(conf.auth.token.privateKey, conf.auth.token.iss, conf.auth.token.sub)
+		log.Info("jwt key ring loaded", "active kid", conf.auth.token.activeKid, "keys", len(keys))
+	}
+
+	oidcProviders := make(map[string]*oidc.Provider, len(conf.oidc))
+	for _, provider := range conf.oidc {
+		oidcProviders[provider.Name] = oidc.NewProvider(provider, nil)
+	}
+
+	repo := repository.NewRepository(db)
+
+	// users and roles are looked up by every authenticated request
+	if conf.cache.ttl > 0 {
+		repo = repository.WithCache(repo, db, conf.cache.ttl)
+	}
 
 	app := &application{
 		configs:        conf,
-		repository:     repository.NewRepository(db),
+		repository:     repo,
 		mailer:         sendGridMail,
-		upload:         localUpload,
-		authentication: jwtAuth,
+		upload:         upload,
+		signer:         uploader.NewURLSigner(signingKey, conf.upload.privateURL),
+		search:         search.NewMySQL(db),
+		authentication: authenticator,
+		oidc: oidcLogin{
+			providers: oidcProviders,
+			states:    oidc.NewStateStore(time.Minute * 10),
+		},
 	}
 
 	// server metrics
@@ -106,6 +203,10 @@ func main() {
 		return runtime.NumGoroutine()
 	}))
 
+	if conf.upload.gcInterval > 0 {
+		go app.collectUploads(conf.upload.gcInterval)
+	}
+
 	mux := app.mount()
 
 	if err := app.run(mux); err != nil {
@@ -4,43 +4,96 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"mime"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/charmbracelet/log"
+	"github.com/faizisyellow/gobali/internal/auth"
 	"github.com/faizisyellow/gobali/internal/repository"
 	"github.com/faizisyellow/gobali/internal/uploader"
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/golang-jwt/jwt/v5"
 )
 
 type filenamectxKey string
 type userKey string
+type apiKeyKey string
+type sessionKey string
 
 var (
 	filenameKey filenamectxKey = "filenames"
 	userCtx     userKey        = "user"
+	apiKeyCtx   apiKeyKey      = "api_key"
+	sessionCtx  sessionKey     = "session"
 )
 
-func (app *application) UploadImagesMiddleware(next http.HandlerFunc, dst string) http.HandlerFunc {
+const (
+	requestTimeout = 60 * time.Second
+	uploadTimeout  = 10 * time.Minute
+)
+
+// TimeoutMiddleware cancels the context of a request after requestTimeout,
+// an upload is given uploadTimeout since the storage and the database are called with its context.
+func (app *application) TimeoutMiddleware(next http.Handler) http.Handler {
+	request := middleware.Timeout(requestTimeout)(next)
+	upload := middleware.Timeout(uploadTimeout)(next)
+
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the read deadline of the connection is only lifted by the upload routes, see uploadMiddleware
+		if isUpload(r) {
+			upload.ServeHTTP(w, r)
+			return
+		}
 
-		allowMime := []string{"image/png", "image/jpeg"}
+		request.ServeHTTP(w, r)
+	})
+}
 
-		var maxMem int64 = 3 * 1024 * 1024 // 3 mb
+func isUpload(r *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
 
-		r.ParseMultipartForm(maxMem)
+	return (r.Method == http.MethodPost || r.Method == http.MethodPut) && mediaType == "multipart/form-data"
+}
 
-		fileFields := r.MultipartForm.File
+func (app *application) UploadImagesMiddleware(next http.HandlerFunc, dst string) http.HandlerFunc {
+	// villa photography is 5-15 mb a photo, the files are streamed one at a time
+	limits := uploader.Limits{
+		MaxFileSize:    20 * 1024 * 1024,  // 20 mb
+		MaxRequestSize: 200 * 1024 * 1024, // 200 mb
+		MaxFiles:       20,
+	}
 
-		if len(fileFields) == 0 {
-			app.badRequestResponse(w, r, fmt.Errorf("image file required"))
-			return
-		}
+	return app.uploadMiddleware(next, dst, limits, []string{"image/png", "image/jpeg"})
+}
 
-		filenames, err := app.upload.Upload(r, dst, maxMem, allowMime)
+// UploadDocumentsMiddleware stores the guest documents of the check-in, a scan or a photo of a passport.
+func (app *application) UploadDocumentsMiddleware(next http.HandlerFunc, dst string) http.HandlerFunc {
+	limits := uploader.Limits{
+		MaxFileSize:    10 * 1024 * 1024, // 10 mb
+		MaxRequestSize: 50 * 1024 * 1024, // 50 mb
+		MaxFiles:       5,
+	}
+
+	return app.uploadMiddleware(next, dst, limits, []string{"image/png", "image/jpeg", "application/pdf"})
+}
+
+// uploadMiddleware stores the files of the request in dst and passes their names to next.
+func (app *application) uploadMiddleware(next http.HandlerFunc, dst string, limits uploader.Limits, allowMime []string) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		// the server timeouts are made for small requests, a gallery takes a while on a slow connection
+		rc := http.NewResponseController(w)
+		rc.SetReadDeadline(time.Now().Add(uploadTimeout))
+		rc.SetWriteDeadline(time.Now().Add(uploadTimeout + 30*time.Second))
+
+		filenames, err := app.upload.Upload(r, dst, limits, allowMime)
 		if err != nil {
 			switch err {
-			case uploader.ErrExtNotAllowed, uploader.ErrSizeTooLarger:
+			case uploader.ErrExtNotAllowed, uploader.ErrSizeTooLarger, uploader.ErrTooManyFiles, uploader.ErrInvalidImage, uploader.ErrImageTooLarge, http.ErrNotMultipart:
 				app.badRequestResponse(w, r, err)
 			default:
 				app.internalServerError(w, r, err)
@@ -49,12 +102,26 @@ func (app *application) UploadImagesMiddleware(next http.HandlerFunc, dst string
 			return
 		}
 
-		ctx := context.WithValue(r.Context(), filenameKey, filenames)
+		if len(filenames) == 0 {
+			app.badRequestResponse(w, r, fmt.Errorf("file required"))
+			return
+		}
+
+		// the same file sent twice is stored once and referred to once
+		unique := []string{}
+		for _, name := range filenames {
+			if !slices.Contains(unique, name) {
+				unique = append(unique, name)
+			}
+		}
+
+		ctx := context.WithValue(r.Context(), filenameKey, unique)
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// AuthTokenMiddleware accepts a user's "Bearer <jwt>" or a partner's "ApiKey <key>".
 func (app *application) AuthTokenMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
@@ -66,43 +133,142 @@ func (app *application) AuthTokenMiddleware(next http.Handler) http.Handler {
 		}
 
 		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
+		if len(parts) != 2 {
 			app.unAuthorizedErrorResponse(w, r, fmt.Errorf("authorization header is malformed"))
 			return
 		}
 
-		// signed token
-		token := parts[1]
+		ctx := r.Context()
 
-		// validate token and decode the token
-		jwtToken, err := app.authentication.VerifyToken(token)
-		if err != nil {
-			app.unAuthorizedErrorResponse(w, r, err)
-			return
-		}
+		var user *repository.User
+		var err error
 
-		// claim's token
-		claims := jwtToken.Claims.(jwt.MapClaims)
+		switch parts[0] {
+		case "Bearer":
+			var session *repository.Session
 
-		userID, err := strconv.ParseInt(fmt.Sprintf("%.f", claims["id"]), 10, 64)
-		if err != nil {
-			app.unAuthorizedErrorResponse(w, r, err)
-			return
+			user, session, err = app.authenticateBearer(ctx, parts[1])
+			if err == nil {
+				ctx = context.WithValue(ctx, sessionCtx, session)
+			}
+		case "ApiKey":
+			var key *repository.ApiKey
+
+			user, key, err = app.authenticateAPIKey(ctx, parts[1])
+			if err == nil {
+				ctx = context.WithValue(ctx, apiKeyCtx, key)
+			}
+		default:
+			err = fmt.Errorf("authorization header is malformed")
 		}
 
-		ctx := r.Context()
-		user, err := app.repository.Users.GetByID(ctx, int(userID))
 		if err != nil {
 			app.unAuthorizedErrorResponse(w, r, err)
 			return
 		}
 
+		// impersonated requests are tagged in the logs and can never delete
+		if session, ok := ctx.Value(sessionCtx).(*repository.Session); ok && session.ImpersonatorId != nil {
+			log.Info("impersonated request",
+				"impersonator", *session.ImpersonatorId,
+				"user", user.Id,
+				"method", r.Method,
+				"path", r.URL,
+				"request_id", middleware.GetReqID(ctx),
+			)
+
+			if r.Method == http.MethodDelete {
+				app.impersonationForbiddenResponse(w, r)
+				return
+			}
+
+			ctx = context.WithValue(ctx, impersonatorCtx, *session.ImpersonatorId)
+		}
+
 		ctx = context.WithValue(ctx, userCtx, user)
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// authenticateBearer accepts the token while its session is still active, so a signed out token stops working.
+func (app *application) authenticateBearer(ctx context.Context, token string) (*repository.User, *repository.Session, error) {
+	// validate token and decode the token
+	jwtToken, err := app.authentication.VerifyToken(token)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// claim's token
+	claims := jwtToken.Claims.(jwt.MapClaims)
+
+	userID, err := strconv.ParseInt(fmt.Sprintf("%.f", claims["id"]), 10, 64)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sessionID, _ := claims["sid"].(string)
+	if sessionID == "" {
+		return nil, nil, fmt.Errorf("token has no session")
+	}
+
+	session, err := app.repository.Sessions.GetActive(ctx, sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if session.UserId != int(userID) {
+		return nil, nil, fmt.Errorf("token does not belong to the session")
+	}
+
+	user, err := app.repository.Users.GetByID(ctx, int(userID))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if session.TouchDue() {
+		if err := app.repository.Sessions.Touch(ctx, session.Id); err != nil {
+			log.Error("error updating session last seen", "session", session.Id, "error", err.Error())
+		}
+	}
+
+	return user, session, nil
+}
+
+// authenticateAPIKey acts as the key's owner, limited to the scopes of the key.
+func (app *application) authenticateAPIKey(ctx context.Context, plainKey string) (*repository.User, *repository.ApiKey, error) {
+	prefix, err := auth.APIKeyPrefix(plainKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key, err := app.repository.ApiKeys.GetActiveByPrefix(ctx, prefix)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !auth.CompareAPIKey(plainKey, key.Hash) {
+		return nil, nil, fmt.Errorf("api key does not match")
+	}
+
+	user, err := app.repository.Users.GetByID(ctx, key.UserId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user.Role.Permissions = slices.DeleteFunc(user.Role.Permissions, func(permission string) bool {
+		return !slices.Contains(key.Scopes, permission)
+	})
+
+	if key.TouchDue() {
+		if err := app.repository.ApiKeys.Touch(ctx, key.Id); err != nil {
+			log.Error("error updating api key last used", "key", key.Prefix, "error", err.Error())
+		}
+	}
+
+	return user, key, nil
+}
+
 func (app *application) AuthBasicMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
@@ -137,57 +303,40 @@ func (app *application) AuthBasicMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-func (app *application) OfficerOnlyAccess(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ctx := r.Context()
-		user := getUserFromContext(r)
-
-		granted, err := app.CheckRolePresedence(ctx, user, "admin")
-		if err != nil {
-			app.internalServerError(w, r, err)
-			return
-		}
+// RequirePermission lets the request through when the user's role is granted the permission.
+func (app *application) RequirePermission(permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user := getUserFromContext(r)
 
-		if !granted {
-			app.forbiddenErrorResponse(w, r)
-			return
-		}
+			if !user.Role.HasPermission(permission) {
+				app.forbiddenErrorResponse(w, r)
+				return
+			}
 
-		next.ServeHTTP(w, r)
-	})
+			next.ServeHTTP(w, r)
+		})
+	}
 }
 
-func (app *application) UserAction(next http.HandlerFunc) http.HandlerFunc {
+func (app *application) BookingAccess(permission string, next http.HandlerFunc) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		booking := GetBookingFromContext(r)
 		user := getUserFromContext(r)
 
-		if user.Role.Name != "user" {
+		// an api key only acts within its scopes, on the bookings of its owner too
+		if key, ok := r.Context().Value(apiKeyCtx).(*repository.ApiKey); ok && !slices.Contains(key.Scopes, permission) {
 			app.forbiddenErrorResponse(w, r)
 			return
 		}
 
-		next.ServeHTTP(w, r)
-	})
-}
-
-func (app *application) BookingAccess(requiredRole string, next http.HandlerFunc) http.HandlerFunc {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		booking := GetBookingFromContext(r)
-		user := getUserFromContext(r)
-
 		// if the booking's is the user then access it, but can not remove it
 		if booking != nil && r.Method != "DELETE" && user.Id == booking.UserId {
 			next.ServeHTTP(w, r)
 			return
 		}
 
-		granted, err := app.CheckRolePresedence(r.Context(), user, requiredRole)
-		if err != nil {
-			app.internalServerError(w, r, err)
-			return
-		}
-
-		if !granted {
+		if !user.Role.HasPermission(permission) {
 			app.forbiddenErrorResponse(w, r)
 			return
 		}
@@ -195,12 +344,3 @@ func (app *application) BookingAccess(requiredRole string, next http.HandlerFunc
 		next.ServeHTTP(w, r)
 	})
 }
-
-func (app *application) CheckRolePresedence(ctx context.Context, user *repository.User, rolename string) (bool, error) {
-	role, err := app.repository.Roles.GetByName(ctx, rolename)
-	if err != nil {
-		return false, err
-	}
-
-	return user.Role.Level >= role.Level, nil
-}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/faizisyellow/gobali/internal/oidc"
+	"github.com/faizisyellow/gobali/internal/repository"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+var ErrEmailNotVerified = errors.New("the identity provider did not verify the email")
+
+type OIDCAuthorizeResponse struct {
+	AuthorizationUrl string `json:"authorization_url"`
+}
+
+type OIDCCallbackPayload struct {
+	Code  string `json:"code" validate:"required"`
+	State string `json:"state" validate:"required"`
+}
+
+// @Summary		Start social login
+// @Description	Get the URL of the identity provider to sign in with, the provider redirects back with code and state
+// @Tags			Auth
+// @Produce		json
+// @Param			provider	path		string	true	"provider name, e.g. google"
+// @Success		200			{object}	main.jsonResponse.envelope{data=OIDCAuthorizeResponse}
+// @Failure		404			{object}	main.WriteJSONError.envelope
+// @Failure		500			{object}	main.WriteJSONError.envelope
+// @Router			/authentication/oidc/{provider} [GET]
+func (app *application) OIDCAuthorizeHandler(w http.ResponseWriter, r *http.Request) {
+	provider, ok := app.oidc.providers[chi.URLParam(r, "provider")]
+	if !ok {
+		app.notFoundResponse(w, r, oidc.ErrUnknownProvider)
+		return
+	}
+
+	verifier, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	state, err := oidc.RandomString(24)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	nonce, err := oidc.RandomString(24)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	authURL, err := provider.AuthCodeURL(r.Context(), state, nonce, challenge)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.oidc.states.Save(state, oidc.Pending{Provider: provider.Name(), Verifier: verifier, Nonce: nonce})
+
+	if err := app.jsonResponse(w, http.StatusOK, OIDCAuthorizeResponse{AuthorizationUrl: authURL}); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// @Summary		Finish social login
+// @Description	Exchange the code from the identity provider for a session token, the account is linked by verified email
+// @Tags			Auth
+// @Accept			json
+// @Produce		json
+// @Param			provider	path		string				true	"provider name, e.g. google"
+// @Param			Payload		body		OIDCCallbackPayload	true	"code and state from the provider redirect"
+// @Success		200			{object}	main.jsonResponse.envelope{data=string}
+// @Failure		400			{object}	main.WriteJSONError.envelope
+// @Failure		401			{object}	main.WriteJSONError.envelope
+// @Failure		404			{object}	main.WriteJSONError.envelope
+// @Failure		409			{object}	main.WriteJSONError.envelope
+// @Failure		500			{object}	main.WriteJSONError.envelope
+// @Router			/authentication/oidc/{provider}/callback [POST]
+func (app *application) OIDCCallbackHandler(w http.ResponseWriter, r *http.Request) {
+	provider, ok := app.oidc.providers[chi.URLParam(r, "provider")]
+	if !ok {
+		app.notFoundResponse(w, r, oidc.ErrUnknownProvider)
+		return
+	}
+
+	payload := &OIDCCallbackPayload{}
+
+	if err := readJSON(w, r, payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	pending, err := app.oidc.states.Take(payload.State)
+	if err != nil || pending.Provider != provider.Name() {
+		app.badRequestResponse(w, r, oidc.ErrInvalidState)
+		return
+	}
+
+	ctx := r.Context()
+
+	identity, err := provider.Exchange(ctx, payload.Code, pending.Verifier, pending.Nonce)
+	if err != nil {
+		app.unAuthorizedErrorResponse(w, r, err)
+		return
+	}
+
+	user, err := app.userFromIdentity(r, identity)
+	if err != nil {
+		switch err {
+		case ErrEmailNotVerified:
+			app.unAuthorizedErrorResponse(w, r, err)
+		case repository.ErrDuplicateEmail:
+			app.conflictErrorResponse(w, r, errors.New("an account with this email is waiting for activation"))
+		default:
+			app.internalServerError(w, r, err)
+		}
+
+		return
+	}
+
+	signedToken, err := app.issueToken(r, user)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, signedToken); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// userFromIdentity returns the linked user, links an existing user with the same verified email
+// or registers a new one.
+func (app *application) userFromIdentity(r *http.Request, identity *oidc.Identity) (*repository.User, error) {
+	ctx := r.Context()
+
+	userId, err := app.repository.Identities.GetUserID(ctx, identity.Provider, identity.Subject)
+	if err == nil {
+		return app.repository.Users.GetByID(ctx, userId)
+	}
+
+	if err != repository.ErrNoRows {
+		return nil, err
+	}
+
+	// linking by an email the provider did not verify would let anyone claim an account
+	if !identity.EmailVerified || identity.Email == "" {
+		return nil, ErrEmailNotVerified
+	}
+
+	link := &repository.Identity{Provider: identity.Provider, Subject: identity.Subject, Email: identity.Email}
+
+	user, err := app.repository.Users.GetUserByEmail(ctx, identity.Email)
+	switch err {
+	case nil:
+		link.UserId = user.Id
+
+		if err := app.repository.Identities.Link(ctx, link); err != nil {
+			return nil, err
+		}
+
+		return user, nil
+	case repository.ErrNoRows:
+	default:
+		return nil, err
+	}
+
+	username := identity.Name
+	if username == "" || len(username) > 64 {
+		username = identity.Email[:min(len(identity.Email), 64)]
+	}
+
+	user = &repository.User{
+		Username: username,
+		Email:    identity.Email,
+	}
+
+	// the account has no usable password until the user sets one
+	if err := user.Password.Set(uuid.New().String()); err != nil {
+		return nil, err
+	}
+
+	if err := app.repository.Identities.CreateUserWithIdentity(ctx, user, link); err != nil {
+		return nil, err
+	}
+
+	// load the default role the user was registered with
+	return app.repository.Users.GetByID(ctx, user.Id)
+}
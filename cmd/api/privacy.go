@@ -0,0 +1,206 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/faizisyellow/gobali/internal/repository"
+)
+
+type UserDataExport struct {
+	ExportedAt string                `json:"exported_at"`
+	Profile    ProfileUserResponse   `json:"profile"`
+	Bookings   []*repository.Booking `json:"bookings"`
+	Consents   []*repository.Consent `json:"consents"`
+}
+
+type UpdateConsentPayload struct {
+	Purpose string `json:"purpose" validate:"required,oneof=terms marketing_email"`
+	Granted *bool  `json:"granted" validate:"required"`
+}
+
+type DeleteAccountPayload struct {
+	Password string `json:"password" validate:"required"`
+}
+
+// @Summary		Export personal data
+// @Description	Export profile, bookings and consents of the user who is logged in
+// @Tags			Users
+// @Produce		json
+// @Produce		application/zip
+// @Param			format	query	string	false	"json (default) or zip"
+// @Security		JWT
+// @Success		200	{object}	UserDataExport
+// @Failure		400	{object}	main.WriteJSONError.envelope
+// @Failure		500	{object}	main.WriteJSONError.envelope
+// @Router			/users/me/export [get]
+func (app *application) ExportUserDataHandler(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+
+	if format != "json" && format != "zip" {
+		app.badRequestResponse(w, r, fmt.Errorf("format must be json or zip"))
+		return
+	}
+
+	user := getUserFromContext(r)
+	ctx := r.Context()
+
+	bookings, err := app.repository.Bookings.GetByUserID(ctx, user.Id)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	consents, err := app.repository.Consents.GetByUserID(ctx, user.Id)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	export := UserDataExport{
+		ExportedAt: time.Now().UTC().Format(time.RFC3339),
+		Profile:    newProfileResponse(user),
+		Bookings:   bookings,
+		Consents:   consents,
+	}
+
+	filename := fmt.Sprintf("gobali-user-%d-export", user.Id)
+
+	if format == "json" {
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"`, filename))
+
+		if err := writeJSON(w, http.StatusOK, export); err != nil {
+			app.internalServerError(w, r, err)
+		}
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.zip"`, filename))
+	w.WriteHeader(http.StatusOK)
+
+	archive := zip.NewWriter(w)
+
+	files := map[string]any{
+		"profile.json":  export.Profile,
+		"bookings.json": export.Bookings,
+		"consents.json": export.Consents,
+	}
+
+	for _, name := range []string{"profile.json", "bookings.json", "consents.json"} {
+		file, err := archive.Create(name)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+
+		if err := encoder.Encode(files[name]); err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// @Summary		Update consent
+// @Description	Grant or withdraw a consent of the user who is logged in
+// @Tags			Users
+// @Accept			json
+// @Produce		json
+// @Param			payload	body	UpdateConsentPayload	true	"Consent purpose and decision"
+// @Security		JWT
+// @Success		200	{object}	main.jsonResponse.envelope{data=[]repository.Consent}
+// @Failure		400	{object}	main.WriteJSONError.envelope
+// @Failure		500	{object}	main.WriteJSONError.envelope
+// @Router			/users/me/consents [put]
+func (app *application) UpdateConsentHandler(w http.ResponseWriter, r *http.Request) {
+	payload := &UpdateConsentPayload{}
+
+	if err := readJSON(w, r, payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := getUserFromContext(r)
+	ctx := r.Context()
+
+	if err := app.repository.Consents.Set(ctx, user.Id, payload.Purpose, *payload.Granted); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	consents, err := app.repository.Consents.GetByUserID(ctx, user.Id)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, consents); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// @Summary		Delete account
+// @Description	Delete the account of the user who is logged in, personal details on past bookings are anonymized
+// @Tags			Users
+// @Accept			json
+// @Param			payload	body	DeleteAccountPayload	true	"Current password"
+// @Security		JWT
+// @Success		204
+// @Failure		400	{object}	main.WriteJSONError.envelope
+// @Failure		401	{object}	main.WriteJSONError.envelope
+// @Failure		500	{object}	main.WriteJSONError.envelope
+// @Router			/users/me [delete]
+func (app *application) DeleteAccountHandler(w http.ResponseWriter, r *http.Request) {
+	payload := &DeleteAccountPayload{}
+
+	if err := readJSON(w, r, payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := getUserFromContext(r)
+
+	if err := user.Password.Compare(payload.Password); err != nil {
+		app.unAuthorizedErrorResponse(w, r, err)
+		return
+	}
+
+	// the actor is gone with the account, the snapshot keeps only the id
+	event := app.auditEvent(r, auditDelete, "user", user.Id, map[string]int{"id": user.Id}, nil)
+
+	if err := app.repository.Users.Delete(r.Context(), user.Id, event); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.responseNoContent(w); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
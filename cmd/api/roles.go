@@ -0,0 +1,362 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/faizisyellow/gobali/internal/repository"
+	"github.com/go-chi/chi/v5"
+)
+
+type roleCtxKey string
+
+const roleKey roleCtxKey = "role"
+
+// built-in roles are referenced by name in the code, they can not be renamed or removed.
+var builtinRoles = []string{"admin", "user"}
+
+var (
+	ErrBuiltinRole    = errors.New("built-in role can not be renamed or deleted")
+	ErrOwnRolesManage = errors.New("can not remove roles:manage from your own role")
+)
+
+type CreateRolePayload struct {
+	Name        string   `json:"name" validate:"required,min=3,max=32"`
+	Level       int      `json:"level" validate:"gte=0"`
+	Description string   `json:"description" validate:"max=255"`
+	Permissions []string `json:"permissions"`
+}
+
+type UpdateRolePayload struct {
+	Name        *string `json:"name" validate:"omitempty,min=3,max=32"`
+	Level       *int    `json:"level" validate:"omitempty,gte=0"`
+	Description *string `json:"description" validate:"omitempty,max=255"`
+}
+
+type SetRolePermissionsPayload struct {
+	Permissions []string `json:"permissions" validate:"required"`
+}
+
+func (u *UpdateRolePayload) Apply(role *repository.Role) {
+	if u.Name != nil {
+		role.Name = *u.Name
+	}
+
+	if u.Level != nil {
+		role.Level = *u.Level
+	}
+
+	if u.Description != nil {
+		role.Description = *u.Description
+	}
+}
+
+// @Summary		Create Role
+// @Description	Create role with its permissions
+// @Tags			Admin
+// @Accept			json
+// @Produce		json
+// @Param			payload	body	CreateRolePayload	true	"Payload create role"
+// @Security		JWT
+// @Success		201	{object}	main.jsonResponse.envelope{data=repository.Role}
+// @Failure		400	{object}	main.WriteJSONError.envelope
+// @Failure		409	{object}	main.WriteJSONError.envelope
+// @Failure		500	{object}	main.WriteJSONError.envelope
+// @Router			/admin/roles [post]
+func (app *application) CreateRoleHandler(w http.ResponseWriter, r *http.Request) {
+	payload := &CreateRolePayload{}
+
+	if err := readJSON(w, r, payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	role := &repository.Role{
+		Name:        payload.Name,
+		Level:       payload.Level,
+		Description: payload.Description,
+		Permissions: payload.Permissions,
+	}
+
+	if err := app.repository.Roles.Create(r.Context(), role); err != nil {
+		switch err {
+		case repository.ErrDuplicateRole:
+			app.conflictErrorResponse(w, r, err)
+		case repository.ErrPermissionNotExist:
+			app.badRequestResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+
+		return
+	}
+
+	app.recordAudit(r, auditCreate, "role", role.Id, nil, role)
+
+	if err := app.jsonResponse(w, http.StatusCreated, role); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// @Summary		Get Roles
+// @Description	Get all roles with their permissions
+// @Tags			Admin
+// @Produce		json
+// @Security		JWT
+// @Success		200	{object}	main.jsonResponse.envelope{data=[]repository.Role}
+// @Failure		500	{object}	main.WriteJSONError.envelope
+// @Router			/admin/roles [get]
+func (app *application) GetRolesHandler(w http.ResponseWriter, r *http.Request) {
+	roles, err := app.repository.Roles.GetRoles(r.Context())
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, roles); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// @Summary		Get Role
+// @Description	Get role by ID
+// @Tags			Admin
+// @Produce		json
+// @Param			roleID	path	int	true	"Role ID"
+// @Security		JWT
+// @Success		200	{object}	main.jsonResponse.envelope{data=repository.Role}
+// @Failure		404	{object}	main.WriteJSONError.envelope
+// @Failure		500	{object}	main.WriteJSONError.envelope
+// @Router			/admin/roles/{roleID} [get]
+func (app *application) GetRoleByIdHandler(w http.ResponseWriter, r *http.Request) {
+	role := GetRoleFromContext(r)
+
+	if err := app.jsonResponse(w, http.StatusOK, role); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// @Summary		Update Role
+// @Description	Update role name, level or description by ID
+// @Tags			Admin
+// @Accept			json
+// @Produce		json
+// @Param			roleID	path	int					true	"Role ID"
+// @Param			payload	body	UpdateRolePayload	true	"Payload update role"
+// @Security		JWT
+// @Success		200	{object}	main.jsonResponse.envelope{data=repository.Role}
+// @Failure		400	{object}	main.WriteJSONError.envelope
+// @Failure		404	{object}	main.WriteJSONError.envelope
+// @Failure		409	{object}	main.WriteJSONError.envelope
+// @Failure		500	{object}	main.WriteJSONError.envelope
+// @Router			/admin/roles/{roleID} [put]
+func (app *application) UpdateRoleHandler(w http.ResponseWriter, r *http.Request) {
+	payload := &UpdateRolePayload{}
+
+	if err := readJSON(w, r, payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	role := GetRoleFromContext(r)
+
+	if payload.Name != nil && *payload.Name != role.Name && slices.Contains(builtinRoles, role.Name) {
+		app.badRequestResponse(w, r, ErrBuiltinRole)
+		return
+	}
+
+	before := *role
+
+	payload.Apply(role)
+
+	if err := app.repository.Roles.Update(r.Context(), role); err != nil {
+		switch err {
+		case repository.ErrDuplicateRole:
+			app.conflictErrorResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+
+		return
+	}
+
+	app.recordAudit(r, auditUpdate, "role", role.Id, before, role)
+
+	if err := app.jsonResponse(w, http.StatusOK, role); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// @Summary		Set Role Permissions
+// @Description	Replace every permission of the role
+// @Tags			Admin
+// @Accept			json
+// @Produce		json
+// @Param			roleID	path	int							true	"Role ID"
+// @Param			payload	body	SetRolePermissionsPayload	true	"Permission names"
+// @Security		JWT
+// @Success		200	{object}	main.jsonResponse.envelope{data=repository.Role}
+// @Failure		400	{object}	main.WriteJSONError.envelope
+// @Failure		404	{object}	main.WriteJSONError.envelope
+// @Failure		500	{object}	main.WriteJSONError.envelope
+// @Router			/admin/roles/{roleID}/permissions [put]
+func (app *application) SetRolePermissionsHandler(w http.ResponseWriter, r *http.Request) {
+	payload := &SetRolePermissionsPayload{}
+
+	if err := readJSON(w, r, payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	before := GetRoleFromContext(r)
+	ctx := r.Context()
+
+	// the admin would lock themselves out of the roles
+	if before.Id == getUserFromContext(r).Role.Id && !slices.Contains(payload.Permissions, repository.RolesManagePermission) {
+		app.badRequestResponse(w, r, ErrOwnRolesManage)
+		return
+	}
+
+	if err := app.repository.Roles.SetPermissions(ctx, before.Id, payload.Permissions); err != nil {
+		switch err {
+		case repository.ErrPermissionNotExist, repository.ErrLastRolesManager:
+			app.badRequestResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+
+		return
+	}
+
+	role, err := app.repository.Roles.GetByID(ctx, before.Id)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.recordAudit(r, "set_permissions", "role", role.Id, before, role)
+
+	if err := app.jsonResponse(w, http.StatusOK, role); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// @Summary		Delete Role
+// @Description	Delete role by ID, the role must not be assigned to any user and some other role must keep roles:manage
+// @Tags			Admin
+// @Param			roleID	path	int	true	"Role ID"
+// @Security		JWT
+// @Success		204
+// @Failure		400	{object}	main.WriteJSONError.envelope
+// @Failure		404	{object}	main.WriteJSONError.envelope
+// @Failure		409	{object}	main.WriteJSONError.envelope
+// @Failure		500	{object}	main.WriteJSONError.envelope
+// @Router			/admin/roles/{roleID} [delete]
+func (app *application) DeleteRoleHandler(w http.ResponseWriter, r *http.Request) {
+	role := GetRoleFromContext(r)
+
+	if slices.Contains(builtinRoles, role.Name) {
+		app.badRequestResponse(w, r, ErrBuiltinRole)
+		return
+	}
+
+	if err := app.repository.Roles.Delete(r.Context(), role.Id); err != nil {
+		switch err {
+		case repository.ErrLastRolesManager:
+			app.badRequestResponse(w, r, err)
+		case repository.ErrRoleInUse:
+			app.conflictErrorResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+
+		return
+	}
+
+	app.recordAudit(r, auditDelete, "role", role.Id, role, nil)
+
+	if err := app.responseNoContent(w); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// @Summary		Get Permissions
+// @Description	Get every permission that can be granted to a role
+// @Tags			Admin
+// @Produce		json
+// @Security		JWT
+// @Success		200	{object}	main.jsonResponse.envelope{data=[]repository.Permission}
+// @Failure		500	{object}	main.WriteJSONError.envelope
+// @Router			/admin/permissions [get]
+func (app *application) GetPermissionsHandler(w http.ResponseWriter, r *http.Request) {
+	permissions, err := app.repository.Roles.GetPermissions(r.Context())
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, permissions); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+func (app *application) RoleContentMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		roleId := chi.URLParam(r, "roleID")
+
+		id, err := strconv.Atoi(roleId)
+		if err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+
+		ctx := r.Context()
+
+		role, err := app.repository.Roles.GetByID(ctx, id)
+		if err != nil {
+			switch err {
+			case repository.ErrNoRows:
+				app.notFoundResponse(w, r, err)
+			default:
+				app.internalServerError(w, r, err)
+			}
+
+			return
+		}
+
+		ctx = context.WithValue(ctx, roleKey, role)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func GetRoleFromContext(r *http.Request) *repository.Role {
+	role := r.Context().Value(roleKey).(*repository.Role)
+
+	return role
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/faizisyellow/gobali/internal/repository"
+	"github.com/go-chi/chi/v5"
+)
+
+// @Summary		Get sessions
+// @Description	Get the devices the user is signed in on, the session of this request is marked current
+// @Tags			Users
+// @Produce		json
+// @Security		JWT
+// @Success		200	{object}	main.jsonResponse.envelope{data=[]repository.Session}
+// @Failure		401	{object}	main.WriteJSONError.envelope
+// @Failure		500	{object}	main.WriteJSONError.envelope
+// @Router			/users/sessions [get]
+func (app *application) GetSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+
+	sessions, err := app.repository.Sessions.GetByUserID(r.Context(), user.Id)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	// api keys have no session
+	if current, ok := r.Context().Value(sessionCtx).(*repository.Session); ok {
+		for _, session := range sessions {
+			session.Current = session.Id == current.Id
+		}
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, sessions); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// @Summary		Sign out session
+// @Description	Sign out one of the user's sessions, the token of the session stops working
+// @Tags			Users
+// @Param			sessionID	path	string	true	"Session ID"
+// @Security		JWT
+// @Success		204
+// @Failure		401	{object}	main.WriteJSONError.envelope
+// @Failure		404	{object}	main.WriteJSONError.envelope
+// @Failure		500	{object}	main.WriteJSONError.envelope
+// @Router			/users/sessions/{sessionID} [delete]
+func (app *application) RevokeSessionHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+
+	if err := app.repository.Sessions.Revoke(r.Context(), user.Id, chi.URLParam(r, "sessionID")); err != nil {
+		switch err {
+		case repository.ErrNoRows:
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+
+		return
+	}
+
+	if err := app.responseNoContent(w); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// @Summary		Get user's sessions
+// @Description	Get the active sessions of a user
+// @Tags			Admin
+// @Produce		json
+// @Param			userID	path	int	true	"User ID"
+// @Security		JWT
+// @Success		200	{object}	main.jsonResponse.envelope{data=[]repository.Session}
+// @Failure		404	{object}	main.WriteJSONError.envelope
+// @Failure		500	{object}	main.WriteJSONError.envelope
+// @Router			/admin/users/{userID}/sessions [get]
+func (app *application) GetUserSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	account := GetAccountFromContext(r)
+
+	sessions, err := app.repository.Sessions.GetByUserID(r.Context(), account.Id)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, sessions); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// @Summary		Sign out user's session
+// @Description	Sign out one session of a user
+// @Tags			Admin
+// @Param			userID		path	int		true	"User ID"
+// @Param			sessionID	path	string	true	"Session ID"
+// @Security		JWT
+// @Success		204
+// @Failure		404	{object}	main.WriteJSONError.envelope
+// @Failure		500	{object}	main.WriteJSONError.envelope
+// @Router			/admin/users/{userID}/sessions/{sessionID} [delete]
+func (app *application) RevokeUserSessionHandler(w http.ResponseWriter, r *http.Request) {
+	account := GetAccountFromContext(r)
+	sessionId := chi.URLParam(r, "sessionID")
+
+	if err := app.repository.Sessions.Revoke(r.Context(), account.Id, sessionId); err != nil {
+		switch err {
+		case repository.ErrNoRows:
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+
+		return
+	}
+
+	app.recordAudit(r, "revoke", "session", account.Id, map[string]string{"session_id": sessionId}, nil)
+
+	if err := app.responseNoContent(w); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// @Summary		Sign out user everywhere
+// @Description	Sign out every session of a user, e.g. when the account is compromised
+// @Tags			Admin
+// @Param			userID	path	int	true	"User ID"
+// @Security		JWT
+// @Success		204
+// @Failure		404	{object}	main.WriteJSONError.envelope
+// @Failure		500	{object}	main.WriteJSONError.envelope
+// @Router			/admin/users/{userID}/sessions [delete]
+func (app *application) RevokeUserSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	account := GetAccountFromContext(r)
+
+	if err := app.repository.Sessions.RevokeAll(r.Context(), account.Id); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.recordAudit(r, "revoke", "session", account.Id, nil, nil)
+
+	if err := app.responseNoContent(w); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
@@ -48,7 +48,8 @@ func (app *application) CreateTypeHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	if err := app.repository.Types.Create(r.Context(), payload.Name); err != nil {
+	id, err := app.repository.Types.Create(r.Context(), payload.Name)
+	if err != nil {
 		switch err {
 		case repository.ErrDuplicateTypes:
 			app.conflictErrorResponse(w, r, err)
@@ -59,6 +60,8 @@ func (app *application) CreateTypeHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	app.recordAudit(r, auditCreate, "type", id, nil, payload)
+
 	if err := app.jsonResponse(w, http.StatusCreated, "type created successfull"); err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -202,6 +205,8 @@ func (app *application) UpdateTypeHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	app.recordAudit(r, auditUpdate, "type", id, ty, newType)
+
 	if err := app.jsonResponse(w, http.StatusCreated, "update type successfull"); err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -245,6 +250,8 @@ func (app *application) DeleteTypeHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	app.recordAudit(r, auditDelete, "type", ty.Id, ty, nil)
+
 	if err := app.responseNoContent(w); err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -1,21 +1,64 @@
 package main
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 
+	"github.com/charmbracelet/log"
+	"github.com/faizisyellow/gobali/internal/mailer"
 	"github.com/faizisyellow/gobali/internal/repository"
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 )
 
 type CreateUserPayload struct {
-	Username string `json:"username" validate:"required"`
-	Email    string `json:"email" validate:"required,max=21,email"`
+	Username string `json:"username" validate:"required,max=64"`
+	Email    string `json:"email" validate:"required,max=254,email"`
 	Password string `json:"password" validate:"required,max=12,withspace,validpassword"`
 }
 
+// ProfileUserResponse is encoded with the field names as keys, the existing clients read the profile by them.
 type ProfileUserResponse struct {
-	Id              int
-	Username, Email string
+	Id                int
+	Username, Email   string
+	FullName          *string
+	Phone             *string
+	PreferredLanguage string
+}
+
+type UpdateProfilePayload struct {
+	Username          *string `json:"username" validate:"omitempty,min=1,max=64"`
+	FullName          *string `json:"full_name" validate:"omitempty,max=255"`
+	Phone             *string `json:"phone" validate:"omitempty,e164"`
+	PreferredLanguage *string `json:"preferred_language" validate:"omitempty,max=8,bcp47_language_tag"`
+}
+
+type ChangeEmailPayload struct {
+	Email    string `json:"email" validate:"required,max=254,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+func (u *UpdateProfilePayload) Apply(user *repository.User) {
+	if u.Username != nil {
+		user.Username = *u.Username
+	}
+
+	if u.FullName != nil {
+		user.FullName = u.FullName
+	}
+
+	if u.Phone != nil {
+		user.Phone = u.Phone
+	}
+
+	if u.PreferredLanguage != nil {
+		user.PreferredLanguage = *u.PreferredLanguage
+	}
 }
 
 // @Summary		Create user
@@ -57,6 +100,8 @@ func (app *application) CreateUserHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	app.recordAudit(r, auditCreate, "user", user.Id, nil, newProfileResponse(user))
+
 	if err := app.jsonResponse(w, http.StatusCreated, "user created successfuly"); err != nil {
 
 		app.internalServerError(w, r, err)
@@ -99,19 +144,175 @@ func (app *application) ActivateUserHandler(w http.ResponseWriter, r *http.Reque
 func (app *application) ProfileUser(w http.ResponseWriter, r *http.Request) {
 	user := getUserFromContext(r)
 
-	profileUser := ProfileUserResponse{}
+	if err := app.jsonResponse(w, http.StatusOK, newProfileResponse(user)); err != nil {
 
-	profileUser.Id = user.Id
-	profileUser.Email = user.Email
-	profileUser.Username = user.Username
+		app.internalServerError(w, r, err)
+		return
+	}
+}
 
-	if err := app.jsonResponse(w, http.StatusOK, profileUser); err != nil {
+// @Summary		Update profile
+// @Description	Update username, full name, phone or preferred language of the user who is logged in
+// @Tags			Users
+// @Accept			json
+// @Produce		json
+// @Param			payload	body	UpdateProfilePayload	true	"Payload update profile"
+// @Security		JWT
+// @Success		200	{object}	main.jsonResponse.envelope{data=ProfileUserResponse}
+// @Failure		400	{object}	main.WriteJSONError.envelope
+// @Failure		500	{object}	main.WriteJSONError.envelope
+// @Router			/users/profile [put]
+func (app *application) UpdateProfileHandler(w http.ResponseWriter, r *http.Request) {
+	payload := &UpdateProfilePayload{}
 
+	if err := readJSON(w, r, payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := getUserFromContext(r)
+	before := newProfileResponse(user)
+
+	payload.Apply(user)
+
+	if err := app.repository.Users.UpdateProfile(r.Context(), user); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.recordAudit(r, auditUpdate, "user", user.Id, before, newProfileResponse(user))
+
+	if err := app.jsonResponse(w, http.StatusOK, newProfileResponse(user)); err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
 }
 
+// @Summary		Change email
+// @Description	Send a confirmation link to the new email, the email is changed after the link is confirmed
+// @Tags			Users
+// @Accept			json
+// @Produce		json
+// @Param			payload	body	ChangeEmailPayload	true	"New email and current password"
+// @Security		JWT
+// @Success		202	{object}	main.jsonResponse.envelope{data=string}
+// @Failure		400	{object}	main.WriteJSONError.envelope
+// @Failure		401	{object}	main.WriteJSONError.envelope
+// @Failure		500	{object}	main.WriteJSONError.envelope
+// @Router			/users/profile/email [post]
+func (app *application) ChangeEmailHandler(w http.ResponseWriter, r *http.Request) {
+	payload := &ChangeEmailPayload{}
+
+	if err := readJSON(w, r, payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := getUserFromContext(r)
+
+	if payload.Email == user.Email {
+		app.badRequestResponse(w, r, errors.New("new email is the same as the current email"))
+		return
+	}
+
+	// a stolen token alone must not be enough to take over the account
+	if err := user.Password.Compare(payload.Password); err != nil {
+		app.unAuthorizedErrorResponse(w, r, err)
+		return
+	}
+
+	plainToken := uuid.New().String()
+	hash := sha256.Sum256([]byte(plainToken))
+	hashToken := hex.EncodeToString(hash[:])
+
+	ctx := r.Context()
+
+	if err := app.repository.Users.CreateEmailChange(ctx, user.Id, payload.Email, hashToken, app.configs.mail.exp); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	// the links is from the frontend router (http://localhost:5173/confirm-email/{plaintoken})
+	confirmUrl := fmt.Sprintf("%s/confirm-email/%s", app.configs.clientURL, plainToken)
+
+	vars := struct {
+		Username   string
+		ConfirmUrl string
+	}{
+		Username:   user.Username,
+		ConfirmUrl: confirmUrl,
+	}
+
+	isDevEnv := app.configs.env == "Development"
+
+	status, err := app.mailer.Send(mailer.EmailChangeTemplate, user.Username, payload.Email, vars, isDevEnv)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	log.Info("Email sent", "status code", status)
+
+	if err := app.jsonResponse(w, http.StatusAccepted, "confirmation sent to the new email"); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// @Summary		Confirm email change
+// @Description	Swap the email of the user after the confirmation link is opened
+// @Tags			Users
+// @Produce		json
+// @Param			token	path		string	true	"token email change"
+// @Success		200		{object}	main.jsonResponse.envelope{data=string}
+// @Failure		400		{object}	main.WriteJSONError.envelope
+// @Failure		404		{object}	main.WriteJSONError.envelope
+// @Failure		500		{object}	main.WriteJSONError.envelope
+// @Router			/users/email/confirm/{token} [PUT]
+func (app *application) ConfirmEmailChangeHandler(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+
+	err := app.repository.Users.ConfirmEmailChange(r.Context(), token)
+	if err != nil {
+		switch err {
+		case repository.ErrNoRows:
+			app.notFoundResponse(w, r, err)
+		case repository.ErrDuplicateEmail:
+			app.badRequestResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, "email changed successfully"); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+func newProfileResponse(user *repository.User) ProfileUserResponse {
+	return ProfileUserResponse{
+		Id:                user.Id,
+		Username:          user.Username,
+		Email:             user.Email,
+		FullName:          user.FullName,
+		Phone:             user.Phone,
+		PreferredLanguage: user.PreferredLanguage,
+	}
+}
+
 // TODO: Consider using DTO for response
 
 // @Summary		User's Bookings
@@ -124,24 +325,20 @@ func (app *application) ProfileUser(w http.ResponseWriter, r *http.Request) {
 // @Router			/users/bookings [get]
 func (app *application) UserBookingsHandler(w http.ResponseWriter, r *http.Request) {
 	user := getUserFromContext(r)
-	query, err := repository.PaginatedUserBookingsQuery{
-		Limit:  6,
-		Offset: 0,
-		Sort:   "asc",
-	}.Parse(r)
+	query, err := repository.UserBookingsList.Parse(r.URL.Query())
 
 	if err != nil {
 		app.badRequestResponse(w, r, err)
 		return
 	}
 
-	user, err = app.repository.Users.GetUserBookings(r.Context(), user.Id, query)
+	user, page, err := app.repository.Users.GetUserBookings(r.Context(), user.Id, query)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
 
-	if err := app.jsonResponse(w, http.StatusOK, user); err != nil {
+	if err := app.jsonPageResponse(w, r, http.StatusOK, user, page); err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
@@ -151,3 +348,277 @@ func getUserFromContext(r *http.Request) *repository.User {
 
 	return r.Context().Value(userCtx).(*repository.User)
 }
+
+type accountCtxKey string
+
+const accountKey accountCtxKey = "account"
+
+var ErrSelfModification = errors.New("can not change your own account from the admin endpoints")
+
+type UpdateUserRolePayload struct {
+	Role string `json:"role" validate:"required"`
+}
+
+type UpdateUserActivationPayload struct {
+	IsActive *bool `json:"is_active" validate:"required"`
+}
+
+// @Summary		Get Users
+// @Description	Get all users, filter by role, activation and email
+// @Tags			Admin
+// @Produce		json
+// @Param			limit	query	string	false	"limit each page"
+// @Param			offset	query	string	false	"skip rows"
+// @Param			cursor	query	string	false	"cursor of the next or prev page from meta"
+// @Param			sort	query	string	false	"sort latest(desc), older(asc) or by fields e.g. email"
+// @Param			role	query	string	false	"role name"
+// @Param			active	query	bool	false	"activation status"
+// @Param			search	query	string	false	"part of the email"
+// @Security		JWT
+// @Success		200	{object}	main.jsonPageResponse.envelope{data=[]repository.User,meta=pagination.Page}
+// @Failure		400	{object}	main.WriteJSONError.envelope
+// @Failure		500	{object}	main.WriteJSONError.envelope
+// @Router			/admin/users [get]
+func (app *application) GetUsersHandler(w http.ResponseWriter, r *http.Request) {
+	query, err := repository.UsersList.Parse(r.URL.Query())
+
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	users, page, err := app.repository.Users.GetUsers(r.Context(), query)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonPageResponse(w, r, http.StatusOK, users, page); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// @Summary		Get User
+// @Description	Get user by ID whether active or not
+// @Tags			Admin
+// @Produce		json
+// @Param			userID	path	int	true	"User ID"
+// @Security		JWT
+// @Success		200	{object}	main.jsonResponse.envelope{data=repository.User}
+// @Failure		404	{object}	main.WriteJSONError.envelope
+// @Failure		500	{object}	main.WriteJSONError.envelope
+// @Router			/admin/users/{userID} [get]
+func (app *application) GetUserByIdHandler(w http.ResponseWriter, r *http.Request) {
+	account := GetAccountFromContext(r)
+
+	if err := app.jsonResponse(w, http.StatusOK, account); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// @Summary		Change User Role
+// @Description	Assign a role to the user, neither the current nor the new role may hold a permission the admin lacks
+// @Tags			Admin
+// @Accept			json
+// @Produce		json
+// @Param			userID	path	int						true	"User ID"
+// @Param			payload	body	UpdateUserRolePayload	true	"Role name"
+// @Security		JWT
+// @Success		200	{object}	main.jsonResponse.envelope{data=repository.User}
+// @Failure		400	{object}	main.WriteJSONError.envelope
+// @Failure		403	{object}	main.WriteJSONError.envelope
+// @Failure		404	{object}	main.WriteJSONError.envelope
+// @Failure		500	{object}	main.WriteJSONError.envelope
+// @Router			/admin/users/{userID}/role [put]
+func (app *application) UpdateUserRoleHandler(w http.ResponseWriter, r *http.Request) {
+	payload := &UpdateUserRolePayload{}
+
+	if err := readJSON(w, r, payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	account := GetAccountFromContext(r)
+
+	if account.Id == getUserFromContext(r).Id {
+		app.badRequestResponse(w, r, ErrSelfModification)
+		return
+	}
+
+	ctx := r.Context()
+
+	role, err := app.repository.Roles.GetByName(ctx, payload.Role)
+	if err != nil {
+		switch err {
+		case repository.ErrNoRows:
+			app.badRequestResponse(w, r, fmt.Errorf("role %q not exist", payload.Role))
+		default:
+			app.internalServerError(w, r, err)
+		}
+
+		return
+	}
+
+	// the role of an account can only be changed between roles the actor could hold itself
+	actor := getUserFromContext(r)
+	if grantsMore(&account.Role, &actor.Role) || grantsMore(role, &actor.Role) {
+		app.forbiddenErrorResponse(w, r)
+		return
+	}
+
+	before := *account
+
+	if err := app.repository.Users.UpdateRole(ctx, account.Id, role.Id); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	account.RoleId = role.Id
+	account.Role = *role
+
+	app.recordAudit(r, "role_change", "user", account.Id, before, account)
+
+	if err := app.jsonResponse(w, http.StatusOK, account); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// @Summary		Activate or Deactivate User
+// @Description	Toggle the user activation, an inactive user can not log in
+// @Tags			Admin
+// @Accept			json
+// @Produce		json
+// @Param			userID	path	int							true	"User ID"
+// @Param			payload	body	UpdateUserActivationPayload	true	"Activation status"
+// @Security		JWT
+// @Success		200	{object}	main.jsonResponse.envelope{data=repository.User}
+// @Failure		400	{object}	main.WriteJSONError.envelope
+// @Failure		404	{object}	main.WriteJSONError.envelope
+// @Failure		500	{object}	main.WriteJSONError.envelope
+// @Router			/admin/users/{userID}/activation [put]
+func (app *application) UpdateUserActivationHandler(w http.ResponseWriter, r *http.Request) {
+	payload := &UpdateUserActivationPayload{}
+
+	if err := readJSON(w, r, payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	account := GetAccountFromContext(r)
+
+	if account.Id == getUserFromContext(r).Id {
+		app.badRequestResponse(w, r, ErrSelfModification)
+		return
+	}
+
+	before := *account
+
+	if err := app.repository.Users.SetActive(r.Context(), account.Id, *payload.IsActive); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	account.IsActive = *payload.IsActive
+
+	action := "deactivate"
+	if account.IsActive {
+		action = "activate"
+	}
+
+	app.recordAudit(r, action, "user", account.Id, before, account)
+
+	if err := app.jsonResponse(w, http.StatusOK, account); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// @Summary		Delete User
+// @Description	Delete user by ID
+// @Tags			Admin
+// @Param			userID	path	int	true	"User ID"
+// @Security		JWT
+// @Success		204
+// @Failure		400	{object}	main.WriteJSONError.envelope
+// @Failure		404	{object}	main.WriteJSONError.envelope
+// @Failure		500	{object}	main.WriteJSONError.envelope
+// @Router			/admin/users/{userID} [delete]
+func (app *application) DeleteUserHandler(w http.ResponseWriter, r *http.Request) {
+	account := GetAccountFromContext(r)
+
+	if account.Id == getUserFromContext(r).Id {
+		app.badRequestResponse(w, r, ErrSelfModification)
+		return
+	}
+
+	event := app.auditEvent(r, auditDelete, "user", account.Id, account, nil)
+
+	if err := app.repository.Users.Delete(r.Context(), account.Id, event); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.responseNoContent(w); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+func (app *application) AccountContentMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userId := chi.URLParam(r, "userID")
+
+		id, err := strconv.Atoi(userId)
+		if err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+
+		ctx := r.Context()
+
+		account, err := app.repository.Users.GetAccountByID(ctx, id)
+		if err != nil {
+			switch err {
+			case repository.ErrNoRows:
+				app.notFoundResponse(w, r, err)
+			default:
+				app.internalServerError(w, r, err)
+			}
+
+			return
+		}
+
+		ctx = context.WithValue(ctx, accountKey, account)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// grantsMore tells whether the role holds a permission the actor's role lacks.
+func grantsMore(role, actor *repository.Role) bool {
+	for _, permission := range role.Permissions {
+		if !actor.HasPermission(permission) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetAccountFromContext returns the user targeted by an admin endpoint, not the one who is logged in.
+func GetAccountFromContext(r *http.Request) *repository.User {
+	return r.Context().Value(accountKey).(*repository.User)
+}
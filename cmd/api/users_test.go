@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/faizisyellow/gobali/internal/auth"
+	"github.com/faizisyellow/gobali/internal/repository"
+)
+
+func (s *stubUsers) GetAccountByID(ctx context.Context, userId int) (*repository.User, error) {
+	return s.GetByID(ctx, userId)
+}
+
+func (s *stubUsers) UpdateRole(ctx context.Context, userId, roleId int) error {
+	s.roleChanges++
+	return nil
+}
+
+// stubRoles finds the roles by name, the other methods are never reached.
+type stubRoles struct {
+	*repository.RolesRepository
+	roles map[string]repository.Role
+}
+
+func (s *stubRoles) GetByName(ctx context.Context, name string) (*repository.Role, error) {
+	role, ok := s.roles[name]
+	if !ok {
+		return nil, repository.ErrNoRows
+	}
+
+	return &role, nil
+}
+
+type stubAudit struct {
+	*repository.AuditRepository
+}
+
+func (s *stubAudit) Create(ctx context.Context, event *repository.AuditEvent) error {
+	return nil
+}
+
+func TestUpdateUserRoleHandler(t *testing.T) {
+	key, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	roles := map[string]repository.Role{
+		"admin":   {Id: 1, Name: "admin", Permissions: []string{"users:manage", "roles:manage", "bookings:read"}},
+		"support": {Id: 2, Name: "support", Permissions: []string{"users:manage", "bookings:read"}},
+		"user":    {Id: 3, Name: "user", Permissions: []string{"bookings:read"}},
+	}
+
+	users := &stubUsers{users: map[int]repository.User{
+		7: {Id: 7, Username: "support", RoleId: 2, Role: roles["support"]},
+		8: {Id: 8, Username: "guest", RoleId: 3, Role: roles["user"]},
+		9: {Id: 9, Username: "owner", RoleId: 1, Role: roles["admin"]},
+	}}
+
+	app := &application{
+		repository: repository.Repository{
+			ApiKeys: &stubApiKeys{key: &repository.ApiKey{Id: 1, Prefix: prefix, Hash: hash, UserId: 7, Scopes: roles["support"].Permissions}},
+			Users:   users,
+			Roles:   &stubRoles{roles: roles},
+			Audit:   &stubAudit{},
+		},
+	}
+
+	router := app.mount()
+
+	tests := []struct {
+		name    string
+		account int
+		role    string
+		want    int
+	}{
+		{name: "should assign a role the admin holds the permissions of", account: 8, role: "support", want: http.StatusOK},
+		{name: "should assign a role with fewer permissions", account: 8, role: "user", want: http.StatusOK},
+		{name: "should refuse to assign a role with a permission the admin lacks", account: 8, role: "admin", want: http.StatusForbidden},
+		{name: "should refuse to change the role of an account with a permission the admin lacks", account: 9, role: "user", want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users.roleChanges = 0
+
+			req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/v1/admin/users/%d/role", tt.account), strings.NewReader(fmt.Sprintf(`{"role":%q}`, tt.role)))
+			req.Header.Set("Authorization", "ApiKey "+key)
+			req.Header.Set("Content-Type", "application/json")
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tt.want {
+				t.Fatalf("expected: %v but got: %v %s", tt.want, rr.Code, rr.Body)
+			}
+
+			if changed := users.roleChanges == 1; changed != (tt.want == http.StatusOK) {
+				t.Errorf("expected the role to be changed: %v but got: %v changes", tt.want == http.StatusOK, users.roleChanges)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path"
+	"slices"
+
+	"github.com/charmbracelet/log"
+	"github.com/faizisyellow/gobali/internal/repository"
+	"github.com/faizisyellow/gobali/internal/uploader"
+	"github.com/go-chi/chi/v5"
+)
+
+var (
+	ErrImageNotExist = errors.New("the villa has no such image")
+	ErrLastImage     = errors.New("can not remove the last image of the villa")
+	ErrImageOrder    = errors.New("images must contain every image of the villa exactly once")
+)
+
+type OrderVillaImagesPayload struct {
+	Images []string `json:"images" validate:"required_without=Cover"`
+	Cover  string   `json:"cover" validate:"required_without=Images"`
+}
+
+// @Summary		Add villa images
+// @Description	Append images to the villa, the first image stays the cover
+// @Tags			Villas
+// @Accept			mpfd
+// @Produce		json
+// @Param			villaID	path		int		true	"Villa ID"
+// @Param			images	formData	file	true	"Image file"
+// @Security		JWT
+// @Success		201	{object}	main.jsonResponse.envelope{data=[]string}
+// @Failure		400	{object}	main.WriteJSONError.envelope
+// @Failure		403	{object}	main.WriteJSONError.envelope
+// @Failure		404	{object}	main.WriteJSONError.envelope
+// @Failure		500	{object}	main.WriteJSONError.envelope
+// @Router			/villas/{villaID}/images [post]
+func (app *application) AddVillaImagesHandler(w http.ResponseWriter, r *http.Request) {
+	villa := GetVillaFromContext(r)
+	uploaded := r.Context().Value(filenameKey).([]string)
+
+	var before []string
+
+	images, err := app.repository.Villas.UpdateImages(r.Context(), villa.Id, imageVariants(uploaded), app.villaImages(), func(images []string) ([]string, error) {
+		before = images
+
+		// an image the villa already has is not added twice
+		added := slices.DeleteFunc(slices.Clone(uploaded), func(image string) bool { return slices.Contains(images, image) })
+
+		return append(slices.Clone(images), added...), nil
+	})
+	if err != nil {
+		app.discardUploads(uploaded)
+
+		switch {
+		case errors.Is(err, repository.ErrNoRows):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, repository.ErrBlobGone):
+			app.badRequestResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+
+		return
+	}
+
+	app.recordAudit(r, auditUpdate, "villa_images", villa.Id, before, images)
+
+	if err := app.jsonResponse(w, http.StatusCreated, app.imageURLs(images)); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// @Summary		Remove villa image
+// @Description	Remove one image of the villa by filename, the file is deleted as well
+// @Tags			Villas
+// @Produce		json
+// @Param			villaID		path	int		true	"Villa ID"
+// @Param			filename	path	string	true	"Image filename"
+// @Security		JWT
+// @Success		200	{object}	main.jsonResponse.envelope{data=[]string}
+// @Failure		400	{object}	main.WriteJSONError.envelope
+// @Failure		403	{object}	main.WriteJSONError.envelope
+// @Failure		404	{object}	main.WriteJSONError.envelope
+// @Failure		500	{object}	main.WriteJSONError.envelope
+// @Router			/villas/{villaID}/images/{filename} [delete]
+func (app *application) DeleteVillaImageHandler(w http.ResponseWriter, r *http.Request) {
+	villa := GetVillaFromContext(r)
+	filename := chi.URLParam(r, "filename")
+
+	var before []string
+
+	images, err := app.repository.Villas.UpdateImages(r.Context(), villa.Id, nil, app.villaImages(), func(images []string) ([]string, error) {
+		before = images
+
+		if !slices.Contains(images, filename) {
+			return nil, ErrImageNotExist
+		}
+
+		if len(images) == 1 {
+			return nil, ErrLastImage
+		}
+
+		return slices.DeleteFunc(slices.Clone(images), func(image string) bool { return image == filename }), nil
+	})
+	if err != nil {
+		switch err {
+		case ErrImageNotExist, repository.ErrNoRows:
+			app.notFoundResponse(w, r, err)
+		case ErrLastImage:
+			app.badRequestResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+
+		return
+	}
+
+	app.recordAudit(r, auditUpdate, "villa_images", villa.Id, before, images)
+
+	if err := app.jsonResponse(w, http.StatusOK, app.imageURLs(images)); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// @Summary		Order villa images
+// @Description	Reorder the images of the villa, the cover is moved to the first position
+// @Tags			Villas
+// @Accept			json
+// @Produce		json
+// @Param			villaID	path	int						true	"Villa ID"
+// @Param			payload	body	OrderVillaImagesPayload	true	"new order and/or cover image"
+// @Security		JWT
+// @Success		200	{object}	main.jsonResponse.envelope{data=[]string}
+// @Failure		400	{object}	main.WriteJSONError.envelope
+// @Failure		403	{object}	main.WriteJSONError.envelope
+// @Failure		404	{object}	main.WriteJSONError.envelope
+// @Failure		500	{object}	main.WriteJSONError.envelope
+// @Router			/villas/{villaID}/images/order [put]
+func (app *application) OrderVillaImagesHandler(w http.ResponseWriter, r *http.Request) {
+	payload := &OrderVillaImagesPayload{}
+
+	if err := readJSON(w, r, payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	// the images may be given as the URLs the villa was returned with
+	for i, image := range payload.Images {
+		payload.Images[i] = path.Base(image)
+	}
+
+	if payload.Cover != "" {
+		payload.Cover = path.Base(payload.Cover)
+	}
+
+	villa := GetVillaFromContext(r)
+
+	var before []string
+
+	images, err := app.repository.Villas.UpdateImages(r.Context(), villa.Id, nil, app.villaImages(), func(images []string) ([]string, error) {
+		before = images
+
+		ordered := slices.Clone(images)
+
+		if len(payload.Images) > 0 {
+			sorted, given := slices.Sorted(slices.Values(images)), slices.Sorted(slices.Values(payload.Images))
+			if !slices.Equal(sorted, given) {
+				return nil, ErrImageOrder
+			}
+
+			ordered = slices.Clone(payload.Images)
+		}
+
+		if payload.Cover != "" {
+			i := slices.Index(ordered, payload.Cover)
+			if i < 0 {
+				return nil, ErrImageNotExist
+			}
+
+			ordered = append([]string{payload.Cover}, slices.Delete(ordered, i, i+1)...)
+		}
+
+		return ordered, nil
+	})
+	if err != nil {
+		switch err {
+		case ErrImageOrder, ErrImageNotExist:
+			app.badRequestResponse(w, r, err)
+		case repository.ErrNoRows:
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+
+		return
+	}
+
+	app.recordAudit(r, auditUpdate, "villa_images", villa.Id, before, images)
+
+	if err := app.jsonResponse(w, http.StatusOK, app.imageURLs(images)); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// villaImages is the storage of the villa images whose references are counted.
+func (app *application) villaImages() uploader.ImageStore {
+	return uploader.ImageStore{Storage: app.upload, Dst: "villas"}
+}
+
+// discardUploads removes the uploads of a villa that failed to save, unless another villa shares them.
+// A failure leaves orphan files behind for the garbage collector, it is only logged.
+func (app *application) discardUploads(images []string) {
+	// the request may be gone already, the files are removed anyway
+	if err := app.repository.Blobs.Discard(context.Background(), app.villaImages(), images); err != nil {
+		log.Error("discard uploads", "error", err)
+	}
+}
+
+// imageURLs turns the stored image names into the absolute URLs of the storage.
+func (app *application) imageURLs(images []string) []string {
+	urls := make([]string, 0, len(images))
+
+	for _, image := range images {
+		urls = append(urls, app.upload.URL("villas", image))
+	}
+
+	return urls
+}
+
+// villaResponse copies the villa with absolute image URLs, the villa itself keeps the names.
+func (app *application) villaResponse(villa *repository.Villa) *repository.Villa {
+	res := *villa
+	res.ImageUrls = app.imageURLs(villa.ImageUrls)
+
+	if villa.ImageVariants != nil {
+		res.ImageVariants = make(map[string]map[string]string, len(villa.ImageVariants))
+
+		for image, variants := range villa.ImageVariants {
+			urls := make(map[string]string, len(variants))
+			for name, variant := range variants {
+				urls[name] = app.upload.URL("villas", variant)
+			}
+
+			res.ImageVariants[app.upload.URL("villas", image)] = urls
+		}
+	}
+
+	return &res
+}
+
+// imageVariants lists the resized copies the uploader stored for every image.
+func imageVariants(images []string) map[string]map[string]string {
+	variants := make(map[string]map[string]string, len(images))
+
+	for _, image := range images {
+		variants[image] = uploader.VariantNames(image)
+	}
+
+	return variants
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/faizisyellow/gobali/internal/repository"
+)
+
+var (
+	ErrInvalidTransition = errors.New("villa can not move to this status")
+	ErrPublishAtStatus   = errors.New("publish_at is only set when publishing")
+	ErrPublishedVilla    = errors.New("a published villa is only changed by a publisher, archive it to edit it as a draft")
+)
+
+// villaTransitions are the moves of the publishing of a villa and the permission each one needs,
+// editors submit their drafts and managers publish, reject or archive them.
+var villaTransitions = map[[2]string]string{
+	{repository.VillaDraft, repository.VillaInReview}:     "villas:submit",
+	{repository.VillaInReview, repository.VillaDraft}:     "villas:publish",
+	{repository.VillaInReview, repository.VillaPublished}: "villas:publish",
+	{repository.VillaPublished, repository.VillaArchived}: "villas:publish",
+	{repository.VillaArchived, repository.VillaDraft}:     "villas:publish",
+}
+
+type UpdateVillaStatusPayload struct {
+	Status string `json:"status" validate:"required,oneof=draft in_review published archived"`
+	// PublishAt schedules the publishing, left out the villa is published now
+	PublishAt *time.Time `json:"publish_at"`
+}
+
+// @Summary		Update Villa Status
+// @Description	Move the villa through draft, in_review, published and archived, editors submit and managers publish
+// @Tags			Villas
+// @Produce		json
+// @Accept			json
+// @Param			villaID	path	int							true	"Villa ID"
+// @Param			payload	body	UpdateVillaStatusPayload	true	"payload villa status"	example({"status":"published","publish_at":"2026-11-01T09:00:00Z"})
+// @Security		JWT
+// @Success		200	{object}	main.jsonResponse.envelope{data=string}
+// @Failure		400	{object}	main.WriteJSONError.envelope
+// @Failure		403	{object}	main.WriteJSONError.envelope
+// @Failure		409	{object}	main.WriteJSONError.envelope
+// @Failure		500	{object}	main.WriteJSONError.envelope
+// @Router			/villas/{villaID}/status [patch]
+func (app *application) UpdateVillaStatusHandler(w http.ResponseWriter, r *http.Request) {
+	payload := &UpdateVillaStatusPayload{}
+
+	if err := readJSON(w, r, payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	villa := GetVillaFromContext(r)
+
+	permission, ok := villaTransitions[[2]string{villa.Status, payload.Status}]
+	if !ok {
+		app.badRequestResponse(w, r, fmt.Errorf("%w: %s to %s", ErrInvalidTransition, villa.Status, payload.Status))
+		return
+	}
+
+	user := getUserFromContext(r)
+	if !user.Role.HasPermission(permission) {
+		app.forbiddenErrorResponse(w, r)
+		return
+	}
+
+	var publishAt *string
+	if payload.PublishAt != nil {
+		if payload.Status != repository.VillaPublished {
+			app.badRequestResponse(w, r, ErrPublishAtStatus)
+			return
+		}
+
+		at := payload.PublishAt.UTC().Format(time.DateTime)
+		publishAt = &at
+	}
+
+	err := app.repository.Villas.UpdateStatus(r.Context(), villa.Id, villa.Status, payload.Status, publishAt)
+	if err != nil {
+		switch err {
+		case repository.ErrVillaStatusChanged:
+			app.conflictErrorResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+
+		return
+	}
+
+	app.recordAudit(r, auditUpdate, "villa", villa.Id,
+		map[string]any{"status": villa.Status, "publish_at": villa.PublishAt},
+		map[string]any{"status": payload.Status, "publish_at": publishAt},
+	)
+
+	if err := app.jsonResponse(w, http.StatusOK, "villa status updated successfully"); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// VisibleVillaMiddleware hides the villas the public does not see yet, behind VillaContentMiddleware.
+func (app *application) VisibleVillaMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !GetVillaFromContext(r).Visible {
+			app.notFoundResponse(w, r, repository.ErrNoRows)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// PublishedVillaMiddleware keeps the changes of a published villa to the publishers, behind VillaContentMiddleware.
+// The change of an editor would go live without a review otherwise.
+func (app *application) PublishedVillaMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		villa := GetVillaFromContext(r)
+
+		if villa.Status == repository.VillaPublished && !getUserFromContext(r).Role.HasPermission("villas:publish") {
+			WriteJSONError(w, http.StatusForbidden, &[]string{ErrPublishedVilla.Error()})
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
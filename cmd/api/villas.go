@@ -2,12 +2,13 @@ package main
 
 import (
 	"context"
+	"errors"
 	"net/http"
-	"path/filepath"
 	"strconv"
 
-	"github.com/faizisyellow/gobali/internal/helpers"
 	"github.com/faizisyellow/gobali/internal/repository"
+	"github.com/faizisyellow/gobali/internal/search"
+	"github.com/faizisyellow/gobali/internal/uploader"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -25,6 +26,9 @@ type CreateVillaProp struct {
 	AmenityId   []int   `json:"amenity_id"`
 	LocationId  int     `json:"location_id"`
 	CategoryId  int     `json:"category_id"`
+	// Latitude and Longitude of the villa, left out the villa is placed at its location
+	Latitude  *float64 `json:"latitude" validate:"required_with=Longitude,omitempty,gte=-90,lte=90"`
+	Longitude *float64 `json:"longitude" validate:"required_with=Latitude,omitempty,gte=-180,lte=180"`
 }
 
 type UpdateVillaPayload struct {
@@ -36,6 +40,8 @@ type UpdateVillaPayload struct {
 	Baths       *int     `json:"baths"`
 	LocationId  *int     `json:"location_id"`
 	CategoryId  *int     `json:"category_id"`
+	Latitude    *float64 `json:"latitude" validate:"required_with=Longitude,omitempty,gte=-90,lte=90"`
+	Longitude   *float64 `json:"longitude" validate:"required_with=Latitude,omitempty,gte=-180,lte=180"`
 }
 
 func (u *UpdateVillaPayload) Apply(villa *repository.Villa) {
@@ -70,6 +76,10 @@ func (u *UpdateVillaPayload) Apply(villa *repository.Villa) {
 	if u.Price != nil {
 		villa.Price = *u.Price
 	}
+
+	if u.Latitude != nil {
+		villa.Latitude, villa.Longitude = u.Latitude, u.Longitude
+	}
 }
 
 // @Summary		Create Villa
@@ -79,7 +89,7 @@ func (u *UpdateVillaPayload) Apply(villa *repository.Villa) {
 // @Accept			mpfd
 // @Param			thumbnail	formData	file	true	"Image file"
 // @Param			others		formData	file	false	"Image file"
-// @Param			properties	formData	string	true	"CreateVillaProp JSON string"	example({"name":"villa name","description":"villa description","min_guest":1,"bedrooms":1,"price":25,"location_id":3,"category_id":2,"baths":1,"amenity_id":[4]})
+// @Param			properties	formData	string	true	"CreateVillaProp JSON string"	example({"name":"villa name","description":"villa description","min_guest":1,"bedrooms":1,"price":25,"location_id":3,"category_id":2,"baths":1,"amenity_id":[4],"latitude":-8.6913,"longitude":115.1682})
 // @Security		JWT
 // @Success		201	{object}	main.jsonResponse.envelope{data=string}
 // @Success		400	{object}	main.WriteJSONError.envelope
@@ -94,18 +104,14 @@ func (app *application) CreateVillaHandler(w http.ResponseWriter, r *http.Reques
 	payload := &CreateVillaProp{}
 
 	if err := readJsonMultiPartForm(r, "properties", payload); err != nil {
-		for _, image := range images {
-			helpers.RemoveFile(filepath.Join(app.configs.upload.baseDir, "villas", image))
-		}
+		app.discardUploads(images)
 
 		app.badRequestResponse(w, r, err)
 		return
 	}
 
 	if err := Validate.Struct(payload); err != nil {
-		for _, image := range images {
-			helpers.RemoveFile(filepath.Join(app.configs.upload.baseDir, "villas", image))
-		}
+		app.discardUploads(images)
 
 		app.badRequestResponse(w, r, err)
 		return
@@ -118,26 +124,29 @@ func (app *application) CreateVillaHandler(w http.ResponseWriter, r *http.Reques
 	}
 
 	newVilla := &repository.Villa{
-		Name:        payload.Name,
-		Description: payload.Description,
-		MinGuest:    payload.MinGuest,
-		Bedrooms:    payload.Bedrooms,
-		Baths:       payload.Baths,
-		Price:       payload.Price,
-		ImageUrls:   images,
-		CategoryId:  payload.CategoryId,
-		LocationId:  payload.LocationId,
-		Amenity:     amenity,
-	}
-
-	err := app.repository.Villas.CreateVillaWithAmenity(ctx, newVilla)
+		Name:          payload.Name,
+		Description:   payload.Description,
+		MinGuest:      payload.MinGuest,
+		Bedrooms:      payload.Bedrooms,
+		Baths:         payload.Baths,
+		Price:         payload.Price,
+		ImageUrls:     images,
+		ImageVariants: imageVariants(images),
+		CategoryId:    payload.CategoryId,
+		LocationId:    payload.LocationId,
+		Latitude:      payload.Latitude,
+		Longitude:     payload.Longitude,
+		Amenity:       amenity,
+		Status:        repository.VillaDraft,
+	}
+
+	err := app.repository.Villas.CreateVillaWithAmenity(ctx, newVilla, app.villaImages())
 	if err != nil {
-		for _, image := range images {
-			helpers.RemoveFile(filepath.Join(app.configs.upload.baseDir, "villas", image))
-		}
+		app.discardUploads(images)
 
-		switch err {
-		case repository.ErrCatOrLocNotExist, repository.ErrDuplicateAmenities, repository.ErrAmenitiesNotExist, repository.ErrDuplicateVilla:
+		switch {
+		case errors.Is(err, repository.ErrCatOrLocNotExist), errors.Is(err, repository.ErrDuplicateAmenities),
+			errors.Is(err, repository.ErrAmenitiesNotExist), errors.Is(err, repository.ErrDuplicateVilla), errors.Is(err, repository.ErrBlobGone):
 			app.badRequestResponse(w, r, err)
 		default:
 			app.internalServerError(w, r, err)
@@ -147,6 +156,8 @@ func (app *application) CreateVillaHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	app.recordAudit(r, auditCreate, "villa", newVilla.Id, nil, newVilla)
+
 	if err := app.jsonResponse(w, http.StatusCreated, "villa created successfully"); err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -154,7 +165,7 @@ func (app *application) CreateVillaHandler(w http.ResponseWriter, r *http.Reques
 }
 
 // @Summary		Update Villa
-// @Description	Update Villa by ID
+// @Description	Update Villa by ID, a published villa is only updated with villas:publish
 // @Tags			Villas
 // @Produce		json
 // @Param			ID	path	int	true	"Villa ID"
@@ -164,6 +175,7 @@ func (app *application) CreateVillaHandler(w http.ResponseWriter, r *http.Reques
 // @Param			properties	formData	string	false	"Update Villa Props JSON string"	example({"name":"villa name","description":"villa description","min_guest":1,"bedrooms":1,"price":25,"location_id":3,"category_id":2,"baths":1})
 // @Security		JWT
 // @Success		201	{object}	main.jsonResponse.envelope{data=string}
+// @Failure		403	{object}	main.WriteJSONError.envelope
 // @Failure		404	{object}	main.WriteJSONError.envelope
 // @Failure		500	{object}	main.WriteJSONError.envelope
 // @Router			/villas/{ID} [PUT]
@@ -182,28 +194,45 @@ func (app *application) UpdateVillaHandler(w http.ResponseWriter, r *http.Reques
 	}
 
 	if err := readJsonMultiPartForm(r, "properties", payload); err != nil {
+		app.discardUploads(imagesUpdated)
+
 		app.badRequestResponse(w, r, err)
 		return
 	}
 
 	if err := Validate.Struct(payload); err != nil {
+		app.discardUploads(imagesUpdated)
+
 		app.badRequestResponse(w, r, err)
 		return
 	}
 
 	villa := GetVillaFromContext(r)
+	before := *villa
 
 	payload.Apply(villa)
 
 	if images != nil {
 		villa.ImageUrls = imagesUpdated
+		villa.ImageVariants = imageVariants(imagesUpdated)
 	}
 
-	if err := app.repository.Villas.Update(ctx, villa); err != nil {
-		app.internalServerError(w, r, err)
+	// uploading replaces every image, the old files are released with the save
+	if err := app.repository.Villas.Update(ctx, villa, app.villaImages()); err != nil {
+		app.discardUploads(imagesUpdated)
+
+		switch {
+		case errors.Is(err, repository.ErrBlobGone):
+			app.badRequestResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+
 		return
 	}
 
+	app.recordAudit(r, auditUpdate, "villa", villa.Id, before, villa)
+
 	if err := app.jsonResponse(w, http.StatusCreated, "updated villa successfully"); err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -224,7 +253,7 @@ func (app *application) GetVillaByIdHandler(w http.ResponseWriter, r *http.Reque
 
 	villa := GetVillaFromContext(r)
 
-	if err := app.jsonResponse(w, http.StatusOK, villa); err != nil {
+	if err := app.jsonResponse(w, http.StatusOK, app.villaResponse(villa)); err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
@@ -236,48 +265,199 @@ func (app *application) GetVillaByIdHandler(w http.ResponseWriter, r *http.Reque
 // @Produce		json
 // @Param			limit		query		string	false	"limit each page"
 // @Param			offset		query		string	false	"skip rows"
-// @Param			sort		query		string	false	"sort villa latest(desc), older(asc)"
-// @Param			location	query		string	false	"location villa"
-// @Param			category	query		string	false	"category villa"
+// @Param			cursor		query		string	false	"cursor of the next or prev page from meta"
+// @Param			sort		query		string	false	"sort direction asc or desc, or fields e.g. -price,bedrooms"
+// @Param			sort_by		query		string	false	"sort by newest, price, bedrooms, relevance or distance"
+// @Param			location	query		string	false	"locations villa, comma separated"
+// @Param			category	query		string	false	"categories villa, comma separated"
 // @Param			bedrooms	query		string	false	"bedrooms villa"
 // @Param			min_guest	query		string	false	"min guest villa"
-// @Success		200			{object}	main.jsonResponse.envelope{data=[]repository.Villa}
+// @Param			price_min	query		number	false	"min price villa"
+// @Param			price_max	query		number	false	"max price villa"
+// @Param			amenities	query		string	false	"amenity ids the villa has all of, comma separated"
+// @Param			q			query		string	false	"search the name, description, location and amenities, sorted by relevance"
+// @Param			near		query		string	false	"lat,lng of a point, sorted by distance"
+// @Param			radius_km	query		number	false	"radius around near in km, default 5"
+// @Param			bbox		query		string	false	"min_lat,min_lng,max_lat,max_lng of a map view"
+// @Success		200			{object}	main.jsonPageResponse.envelope{data=[]repository.Villa,meta=pagination.Page}
 // @Failure		500			{object}	main.WriteJSONError.envelope
 // @Router			/villas [get]
 func (app *application) GetVillasHandler(w http.ResponseWriter, r *http.Request) {
+	app.listVillas(w, r, false)
+}
+
+// @Summary		Get Villas Of Every Status
+// @Description	Get the villas of every status, with the filters of the villa list
+// @Tags			Villas
+// @Produce		json
+// @Param			limit		query		string	false	"limit each page"
+// @Param			offset		query		string	false	"skip rows"
+// @Param			cursor		query		string	false	"cursor of the next or prev page from meta"
+// @Param			sort		query		string	false	"sort direction asc or desc, or fields e.g. -price,bedrooms"
+// @Param			status		query		string	false	"draft, in_review, published or archived, comma separated"
+// @Param			q			query		string	false	"search the name, description, location and amenities, sorted by relevance"
+// @Security		JWT
+// @Success		200			{object}	main.jsonPageResponse.envelope{data=[]repository.Villa,meta=pagination.Page}
+// @Failure		400			{object}	main.WriteJSONError.envelope
+// @Failure		500			{object}	main.WriteJSONError.envelope
+// @Router			/admin/villas [get]
+func (app *application) GetAdminVillasHandler(w http.ResponseWriter, r *http.Request) {
+	app.listVillas(w, r, true)
+}
+
+// listVillas writes a page of the villas, all lists the ones the public does not see too.
+func (app *application) listVillas(w http.ResponseWriter, r *http.Request, all bool) {
+	vq, hits, ok := app.villaQuery(w, r)
+	if !ok {
+		return
+	}
 
-	vq, err := repository.PaginatedVillaQuery{
-		Limit:    5,
-		Offset:   0,
-		Sort:     "asc",
-		Location: "",
-		Category: "",
-		MinGuest: "",
-		Bedrooms: "",
-	}.Parse(r)
+	vq.All = all
 
+	villas, page, err := app.repository.Villas.GetVillas(r.Context(), vq)
 	if err != nil {
-		app.badRequestResponse(w, r, err)
+		app.internalServerError(w, r, err)
 		return
 	}
 
-	if err := Validate.Struct(vq); err != nil {
-		app.badRequestResponse(w, r, err)
+	res := make([]*repository.Villa, 0, len(villas))
+	for _, villa := range villas {
+		if hit, ok := hits[villa.Id]; ok {
+			villa.Snippet, villa.Score = hit.Snippet, hit.Score
+		}
+
+		res = append(res, app.villaResponse(villa))
+	}
+
+	if err := app.jsonPageResponse(w, r, http.StatusOK, res, page); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// @Summary		Get Villa Facets
+// @Description	Count the villas by category, location, amenity and price for the filters
+// @Tags			Villas
+// @Produce		json
+// @Param			location	query		string	false	"locations villa, comma separated"
+// @Param			category	query		string	false	"categories villa, comma separated"
+// @Param			bedrooms	query		string	false	"bedrooms villa"
+// @Param			min_guest	query		string	false	"min guest villa"
+// @Param			price_min	query		number	false	"min price villa"
+// @Param			price_max	query		number	false	"max price villa"
+// @Param			amenities	query		string	false	"amenity ids the villa has all of, comma separated"
+// @Param			q			query		string	false	"search the name, description, location and amenities"
+// @Param			near		query		string	false	"lat,lng of a point"
+// @Param			radius_km	query		number	false	"radius around near in km, default 5"
+// @Param			bbox		query		string	false	"min_lat,min_lng,max_lat,max_lng of a map view"
+// @Success		200			{object}	main.jsonResponse.envelope{data=repository.VillaFacets}
+// @Failure		400			{object}	main.WriteJSONError.envelope
+// @Failure		500			{object}	main.WriteJSONError.envelope
+// @Router			/villas/facets [get]
+func (app *application) GetVillaFacetsHandler(w http.ResponseWriter, r *http.Request) {
+
+	vq, _, ok := app.villaQuery(w, r)
+	if !ok {
+		return
+	}
+
+	facets, err := app.repository.Villas.GetFacets(r.Context(), vq)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, facets); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+
+// @Summary		Get Villa Pins
+// @Description	Get the villas within the box of a map view, with the filters of the villa list
+// @Tags			Villas
+// @Produce		json
+// @Param			bbox		query		string	true	"min_lat,min_lng,max_lat,max_lng of the map view"
+// @Param			location	query		string	false	"locations villa, comma separated"
+// @Param			category	query		string	false	"categories villa, comma separated"
+// @Param			price_min	query		number	false	"min price villa"
+// @Param			price_max	query		number	false	"max price villa"
+// @Param			amenities	query		string	false	"amenity ids the villa has all of, comma separated"
+// @Param			q			query		string	false	"search the name, description, location and amenities"
+// @Success		200			{object}	main.jsonResponse.envelope{data=[]repository.VillaPin}
+// @Failure		400			{object}	main.WriteJSONError.envelope
+// @Failure		500			{object}	main.WriteJSONError.envelope
+// @Router			/villas/map [get]
+func (app *application) GetVillaPinsHandler(w http.ResponseWriter, r *http.Request) {
+
+	vq, _, ok := app.villaQuery(w, r)
+	if !ok {
 		return
 	}
 
-	villas, err := app.repository.Villas.GetVillas(r.Context(), vq)
+	if vq.Bounds == nil {
+		app.badRequestResponse(w, r, errors.New("bbox is required"))
+		return
+	}
+
+	pins, err := app.repository.Villas.GetPins(r.Context(), vq)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
 
-	if err := app.jsonResponse(w, http.StatusOK, villas); err != nil {
+	for _, pin := range pins {
+		if pin.Cover != "" {
+			pin.Cover = app.upload.URL("villas", uploader.VariantNames(pin.Cover)["thumb"])
+		}
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, pins); err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
 }
 
+// villaQuery reads the filters of the villa list, with a search the villas are limited to the hits.
+// It writes the error response itself and reports whether the request can go on.
+func (app *application) villaQuery(w http.ResponseWriter, r *http.Request) (repository.PaginatedVillaQuery, map[int]search.Hit, bool) {
+	vq, err := repository.ParseVillaQuery(r.URL.Query())
+
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return vq, nil, false
+	}
+
+	if err := Validate.Struct(vq); err != nil {
+		app.badRequestResponse(w, r, err)
+		return vq, nil, false
+	}
+
+	hits := map[int]search.Hit{}
+
+	if vq.Search != "" {
+		found, err := app.search.Search(r.Context(), vq.Search)
+		if err != nil {
+			switch err {
+			case search.ErrEmptyQuery:
+				app.badRequestResponse(w, r, err)
+			default:
+				app.internalServerError(w, r, err)
+			}
+
+			return vq, nil, false
+		}
+
+		vq.Ids = make([]int, 0, len(found))
+		for _, hit := range found {
+			vq.Ids = append(vq.Ids, hit.VillaId)
+			hits[hit.VillaId] = hit
+		}
+	}
+
+	return vq, hits, true
+}
+
 // @Summary		Delete Villa
 // @Description	Delete Villa By ID
 // @Tags			Villas
@@ -293,12 +473,14 @@ func (app *application) DeleteVillaByIdHandler(w http.ResponseWriter, r *http.Re
 	villa := GetVillaFromContext(r)
 
 	ctx := r.Context()
-	err := app.repository.Villas.Delete(ctx, villa.Id)
+	err := app.repository.Villas.Delete(ctx, villa.Id, app.villaImages())
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
 
+	app.recordAudit(r, auditDelete, "villa", villa.Id, villa, nil)
+
 	err = app.responseNoContent(w)
 	if err != nil {
 		app.internalServerError(w, r, err)
@@ -0,0 +1,109 @@
+// Command gc deletes the uploaded villa images no villa refers to anymore,
+// and the guest documents of bookings that are gone.
+//
+//	go run ./cmd/gc -dry-run          report the orphans only
+//	go run ./cmd/gc -grace 72h        delete the orphans older than three days
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/faizisyellow/gobali/internal/db"
+	"github.com/faizisyellow/gobali/internal/env"
+	"github.com/faizisyellow/gobali/internal/repository"
+	"github.com/faizisyellow/gobali/internal/uploader"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report the unreferenced files without deleting them")
+	grace := flag.Duration("grace", 24*time.Hour, "keep files younger than this, their villa may not be saved yet")
+	flag.Parse()
+
+	e := &env.Env{}
+	if err := e.Set(); err != nil {
+		log.Fatal(err)
+	}
+
+	// the storage is configured like the api server
+	upload, err := uploader.New(
+		e.GetString("UPLOAD_STORAGE", "local"),
+		"./internal/assets/",
+		e.GetString("UPLOAD_PRIVATE_DIR", "./internal/private/"),
+		e.GetString("UPLOAD_BASE_URL", ""),
+		uploader.S3Config{
+			Endpoint:  e.GetString("S3_ENDPOINT", ""),
+			Region:    e.GetString("S3_REGION", "us-east-1"),
+			Bucket:    e.GetString("S3_BUCKET", ""),
+			AccessKey: e.GetString("S3_ACCESS_KEY", ""),
+			SecretKey: e.GetString("S3_SECRET_KEY", ""),
+		},
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db, err := db.New(e.GetString("DB_ADDRESS", "nil"), 2, 2, "15m")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	defer db.Close()
+
+	repo := repository.NewRepository(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	referenced, err := repo.Villas.GetImageNames(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// a shared image still counted as referenced is kept as well
+	counted, err := repo.Blobs.GetReferenced(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	referenced = append(referenced, counted...)
+
+	documents, err := repo.Bookings.GetDocumentNames(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	images := uploader.ImageStore{Storage: upload, Dst: "villas"}
+
+	// an image saved by a villa since the references were read is kept
+	removeImage := func(ctx context.Context, image string) (bool, error) {
+		return repo.Blobs.RemoveUnreferenced(ctx, images, image)
+	}
+
+	groups := []struct {
+		dst        string
+		referenced []string
+		remove     uploader.Remover
+	}{
+		{"villas", referenced, removeImage},
+		{uploader.Private("documents"), documents, nil},
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	for _, group := range groups {
+		report, err := uploader.CollectGarbage(ctx, upload, group.dst, group.referenced, *grace, *dryRun, group.remove)
+		if report != nil {
+			encoder.Encode(report)
+		}
+
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+}
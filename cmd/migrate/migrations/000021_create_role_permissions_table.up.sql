@@ -0,0 +1,8 @@
+CREATE TABLE
+    role_permissions (
+        role_id INT NOT NULL,
+        permission_id INT NOT NULL,
+        PRIMARY KEY (role_id, permission_id),
+        FOREIGN KEY (role_id) REFERENCES roles (id) ON DELETE CASCADE,
+        FOREIGN KEY (permission_id) REFERENCES permissions (id) ON DELETE CASCADE
+    );
@@ -0,0 +1,5 @@
+DELETE FROM role_permissions;
+
+DELETE FROM permissions;
+
+DELETE FROM roles WHERE name = 'front_desk';
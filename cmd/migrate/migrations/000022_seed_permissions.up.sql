@@ -0,0 +1,29 @@
+INSERT INTO permissions (name, description) VALUES
+    ('villas:create', 'Create villas'),
+    ('villas:update', 'Update villas'),
+    ('villas:delete', 'Delete villas'),
+    ('categories:manage', 'Create, read, update and delete categories'),
+    ('locations:manage', 'Create, read, update and delete locations'),
+    ('types:manage', 'Create, read, update and delete amenity types'),
+    ('amenities:manage', 'Create, read, update and delete amenities'),
+    ('bookings:create', 'Book a villa'),
+    ('bookings:read', 'Read every booking'),
+    ('bookings:check-in', 'Check guests in'),
+    ('bookings:check-out', 'Check guests out'),
+    ('bookings:delete', 'Delete bookings'),
+    ('roles:manage', 'Create, update and delete roles and their permissions');
+
+INSERT IGNORE INTO roles (name, level, description) VALUES
+    ('front_desk', 2, 'Front desk staff, check guests in and out');
+
+-- admin holds every permission
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p WHERE r.name = 'admin';
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p
+ON p.name IN ('bookings:create', 'bookings:read', 'bookings:check-in', 'bookings:check-out')
+WHERE r.name = 'front_desk';
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'bookings:create' WHERE r.name = 'user';
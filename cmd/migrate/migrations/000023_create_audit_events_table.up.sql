@@ -0,0 +1,16 @@
+CREATE TABLE
+    audit_events (
+        id BIGINT PRIMARY KEY AUTO_INCREMENT,
+        actor_id INT,
+        action VARCHAR(64) NOT NULL,
+        entity VARCHAR(64) NOT NULL,
+        entity_id INT NOT NULL,
+        before_data JSON,
+        after_data JSON,
+        request_id VARCHAR(128) NOT NULL DEFAULT '',
+        ip VARCHAR(45) NOT NULL DEFAULT '',
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+        INDEX idx_audit_entity (entity, entity_id),
+        INDEX idx_audit_actor (actor_id),
+        FOREIGN KEY (actor_id) REFERENCES users (id) ON DELETE SET NULL
+    );
@@ -0,0 +1,4 @@
+INSERT INTO permissions (name, description) VALUES ('users:manage', 'List, activate, deactivate, change role of and delete users');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'users:manage' WHERE r.name = 'admin';
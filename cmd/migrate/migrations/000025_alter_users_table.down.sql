@@ -0,0 +1,6 @@
+ALTER TABLE users
+DROP COLUMN preferred_language,
+DROP COLUMN phone,
+DROP COLUMN full_name,
+MODIFY email VARCHAR(28) NOT NULL,
+MODIFY username VARCHAR(16) NOT NULL;
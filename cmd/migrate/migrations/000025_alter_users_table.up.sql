@@ -0,0 +1,6 @@
+ALTER TABLE users
+MODIFY username VARCHAR(64) NOT NULL,
+MODIFY email VARCHAR(254) NOT NULL,
+ADD COLUMN full_name VARCHAR(255),
+ADD COLUMN phone VARCHAR(32),
+ADD COLUMN preferred_language VARCHAR(8) NOT NULL DEFAULT 'en';
@@ -0,0 +1,8 @@
+CREATE TABLE
+    email_changes (
+        token VARBINARY(72) NOT NULL PRIMARY KEY,
+        user_id INT NOT NULL,
+        new_email VARCHAR(254) NOT NULL,
+        expire DATETIME NOT NULL,
+        FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+    );
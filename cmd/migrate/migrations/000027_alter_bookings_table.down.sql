@@ -0,0 +1,4 @@
+ALTER TABLE bookings DROP FOREIGN KEY bookings_user_fk;
+
+-- user_id stays nullable, the bookings of deleted accounts are kept for the accounting
+ALTER TABLE bookings ADD CONSTRAINT bookings_ibfk_1 FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE;
@@ -0,0 +1,5 @@
+ALTER TABLE bookings DROP FOREIGN KEY bookings_ibfk_1;
+
+ALTER TABLE bookings MODIFY user_id INT NULL;
+
+ALTER TABLE bookings ADD CONSTRAINT bookings_user_fk FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE SET NULL;
@@ -0,0 +1,9 @@
+CREATE TABLE
+    user_consents (
+        user_id INT NOT NULL,
+        purpose VARCHAR(64) NOT NULL,
+        granted BOOLEAN NOT NULL DEFAULT 0,
+        updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+        PRIMARY KEY (user_id, purpose),
+        FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+    );
@@ -0,0 +1,16 @@
+CREATE TABLE
+    api_keys (
+        id INT PRIMARY KEY AUTO_INCREMENT,
+        name VARCHAR(255) NOT NULL,
+        prefix VARCHAR(16) NOT NULL UNIQUE,
+        key_hash CHAR(64) NOT NULL,
+        user_id INT NOT NULL,
+        scopes JSON NOT NULL,
+        created_by INT,
+        last_used_at DATETIME,
+        expires_at DATETIME,
+        revoked_at DATETIME,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+        FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+        FOREIGN KEY (created_by) REFERENCES users (id) ON DELETE SET NULL
+    );
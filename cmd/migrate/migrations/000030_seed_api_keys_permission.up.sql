@@ -0,0 +1,4 @@
+INSERT INTO permissions (name, description) VALUES ('api_keys:manage', 'Issue and revoke partner API keys');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'api_keys:manage' WHERE r.name = 'admin';
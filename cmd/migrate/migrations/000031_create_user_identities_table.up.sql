@@ -0,0 +1,10 @@
+CREATE TABLE
+    user_identities (
+        provider VARCHAR(64) NOT NULL,
+        subject VARCHAR(255) NOT NULL,
+        user_id INT NOT NULL,
+        email VARCHAR(254) NOT NULL,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+        PRIMARY KEY (provider, subject),
+        FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+    );
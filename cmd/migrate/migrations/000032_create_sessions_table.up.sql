@@ -0,0 +1,13 @@
+CREATE TABLE
+    sessions (
+        id CHAR(36) PRIMARY KEY,
+        user_id INT NOT NULL,
+        ip VARCHAR(45) NOT NULL,
+        user_agent VARCHAR(512) NOT NULL,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+        last_seen_at DATETIME NOT NULL,
+        expires_at DATETIME NOT NULL,
+        revoked_at DATETIME,
+        INDEX idx_sessions_user (user_id),
+        FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+    );
@@ -0,0 +1,3 @@
+ALTER TABLE audit_events DROP FOREIGN KEY audit_events_impersonator_fk, DROP COLUMN impersonator_id;
+
+ALTER TABLE sessions DROP FOREIGN KEY sessions_impersonator_fk, DROP COLUMN impersonator_id;
@@ -0,0 +1,7 @@
+ALTER TABLE sessions
+ADD COLUMN impersonator_id INT NULL,
+ADD CONSTRAINT sessions_impersonator_fk FOREIGN KEY (impersonator_id) REFERENCES users (id) ON DELETE CASCADE;
+
+ALTER TABLE audit_events
+ADD COLUMN impersonator_id INT NULL,
+ADD CONSTRAINT audit_events_impersonator_fk FOREIGN KEY (impersonator_id) REFERENCES users (id) ON DELETE SET NULL;
@@ -0,0 +1,4 @@
+INSERT INTO permissions (name, description) VALUES ('users:impersonate', 'Act as another user for customer support');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'users:impersonate' WHERE r.name = 'admin';
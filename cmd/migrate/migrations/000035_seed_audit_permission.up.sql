@@ -0,0 +1,4 @@
+INSERT INTO permissions (name, description) VALUES ('audit:read', 'Read the audit log of changes');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'audit:read' WHERE r.name = 'admin';
@@ -0,0 +1 @@
+ALTER TABLE villas DROP COLUMN image_variants;
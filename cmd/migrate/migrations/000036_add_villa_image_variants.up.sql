@@ -0,0 +1 @@
+ALTER TABLE villas ADD COLUMN image_variants JSON NULL AFTER image_urls;
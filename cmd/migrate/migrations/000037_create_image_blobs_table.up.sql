@@ -0,0 +1,7 @@
+CREATE TABLE
+    image_blobs (
+        name VARCHAR(255) PRIMARY KEY,
+        ref_count INT NOT NULL DEFAULT 0,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+        updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+    );
@@ -0,0 +1,5 @@
+ALTER TABLE amenities DROP INDEX ft_amenities_name;
+
+ALTER TABLE locations DROP INDEX ft_locations_area;
+
+ALTER TABLE villas DROP INDEX ft_villas_search;
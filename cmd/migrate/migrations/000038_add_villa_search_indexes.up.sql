@@ -0,0 +1,5 @@
+ALTER TABLE villas ADD FULLTEXT INDEX ft_villas_search (name, description);
+
+ALTER TABLE locations ADD FULLTEXT INDEX ft_locations_area (area);
+
+ALTER TABLE amenities ADD FULLTEXT INDEX ft_amenities_name (name);
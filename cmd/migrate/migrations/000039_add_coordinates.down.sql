@@ -0,0 +1,5 @@
+ALTER TABLE villas DROP INDEX idx_villas_coordinates;
+
+ALTER TABLE villas DROP COLUMN latitude, DROP COLUMN longitude;
+
+ALTER TABLE locations DROP COLUMN latitude, DROP COLUMN longitude;
@@ -0,0 +1,5 @@
+ALTER TABLE locations ADD COLUMN latitude DECIMAL(9,6) NULL, ADD COLUMN longitude DECIMAL(9,6) NULL;
+
+ALTER TABLE villas ADD COLUMN latitude DECIMAL(9,6) NULL, ADD COLUMN longitude DECIMAL(9,6) NULL;
+
+ALTER TABLE villas ADD INDEX idx_villas_coordinates (latitude, longitude);
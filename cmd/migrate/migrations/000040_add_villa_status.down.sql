@@ -0,0 +1,15 @@
+-- the editors and managers are left with the permissions of a user
+UPDATE users SET role_id = (SELECT id FROM roles WHERE name = 'user')
+WHERE role_id IN (SELECT id FROM roles WHERE name IN ('editor', 'manager'));
+
+DELETE FROM role_permissions
+WHERE role_id IN (SELECT id FROM roles WHERE name IN ('editor', 'manager'))
+OR permission_id IN (SELECT id FROM permissions WHERE name IN ('villas:submit', 'villas:publish'));
+
+DELETE FROM permissions WHERE name IN ('villas:submit', 'villas:publish');
+
+DELETE FROM roles WHERE name IN ('editor', 'manager');
+
+ALTER TABLE villas DROP INDEX idx_villas_status;
+
+ALTER TABLE villas DROP COLUMN status, DROP COLUMN publish_at;
@@ -0,0 +1,29 @@
+ALTER TABLE villas
+    ADD COLUMN status ENUM('draft', 'in_review', 'published', 'archived') NOT NULL DEFAULT 'draft',
+    ADD COLUMN publish_at DATETIME NULL;
+
+-- the villas listed so far stay listed
+UPDATE villas SET status = 'published';
+
+ALTER TABLE villas ADD INDEX idx_villas_status (status, publish_at);
+
+INSERT INTO permissions (name, description) VALUES
+    ('villas:submit', 'Submit draft villas for review'),
+    ('villas:publish', 'Publish, reject and archive villas');
+
+INSERT IGNORE INTO roles (name, level, description) VALUES
+    ('editor', 2, 'Write villas and submit them for review'),
+    ('manager', 2, 'Review and publish villas');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name IN ('villas:submit', 'villas:publish') WHERE r.name = 'admin';
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p
+ON p.name IN ('villas:create', 'villas:update', 'villas:submit')
+WHERE r.name = 'editor';
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p
+ON p.name IN ('villas:update', 'villas:publish')
+WHERE r.name = 'manager';
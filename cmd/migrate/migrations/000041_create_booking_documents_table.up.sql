@@ -0,0 +1,9 @@
+CREATE TABLE
+    booking_documents (
+        id INT PRIMARY KEY AUTO_INCREMENT,
+        booking_id INT NOT NULL,
+        name VARCHAR(255) NOT NULL,
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+        INDEX idx_booking_documents_name (name),
+        FOREIGN KEY (booking_id) REFERENCES bookings (id) ON DELETE CASCADE
+    );
@@ -24,41 +24,41 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/amenities": {
+        "/.well-known/jwks.json": {
+            "get": {
+                "description": "Public keys to verify tokens issued by this server",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "JSON Web Key Set",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth.JWKSet"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/api-keys": {
             "get": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Get all Amenities",
+                "description": "Get every partner API key, without the secret",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Amenities"
-                ],
-                "summary": "Get Amenities",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "limit pages",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "skip rows",
-                        "name": "offset",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "sort latest(desc) older(asc)",
-                        "name": "sort",
-                        "in": "query"
-                    }
+                    "Admin"
                 ],
+                "summary": "Get API keys",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -73,7 +73,7 @@ const docTemplate = `{
                                         "data": {
                                             "type": "array",
                                             "items": {
-                                                "$ref": "#/definitions/main.AmenityResponse"
+                                                "$ref": "#/definitions/repository.ApiKey"
                                             }
                                         }
                                     }
@@ -81,12 +81,6 @@ const docTemplate = `{
                             ]
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/main.WriteJSONError.envelope"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -101,7 +95,7 @@ const docTemplate = `{
                         "JWT": []
                     }
                 ],
-                "description": "Create Amenity",
+                "description": "Issue a partner API key acting as the user, limited to the scopes. The key is only shown once",
                 "consumes": [
                     "application/json"
                 ],
@@ -109,17 +103,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Amenities"
+                    "Admin"
                 ],
-                "summary": "Create Amenity",
+                "summary": "Issue API key",
                 "parameters": [
                     {
-                        "description": "json format payload",
+                        "description": "Payload issue api key",
                         "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.CreateAmenityPayload"
+                            "$ref": "#/definitions/main.CreateApiKeyPayload"
                         }
                     }
                 ],
@@ -135,7 +129,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "string"
+                                            "$ref": "#/definitions/main.CreateApiKeyResponse"
                                         }
                                     }
                                 }
@@ -148,8 +142,42 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.WriteJSONError.envelope"
                         }
                     },
-                    "409": {
-                        "description": "Conflict",
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/api-keys/{keyID}": {
+            "delete": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Revoke partner API key by ID",
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Revoke API key",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "API key ID",
+                        "name": "keyID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/main.WriteJSONError.envelope"
                         }
@@ -163,28 +191,81 @@ const docTemplate = `{
                 }
             }
         },
-        "/amenities/{ID}": {
+        "/admin/audit": {
             "get": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Get Amenity by ID",
+                "description": "Get who changed what, filter by actor, action, entity and day range. The events are recorded after the change, a lost event is counted in audit_failures of the debug vars",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Amenities"
+                    "Admin"
                 ],
-                "summary": "Get Amenity",
+                "summary": "Get audit events",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "limit each page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "skip rows",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor of the next or prev page from meta",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sort latest(desc), older(asc)",
+                        "name": "sort",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "amenity id",
-                        "name": "ID",
-                        "in": "path",
-                        "required": true
+                        "description": "user who made the change",
+                        "name": "actor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "create, update, delete, ...",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "villa, booking, user, ...",
+                        "name": "entity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID of the entity",
+                        "name": "entity_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "from day (2006-01-02)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "to day inclusive (2006-01-02)",
+                        "name": "to",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -193,21 +274,27 @@ const docTemplate = `{
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/main.jsonResponse.envelope"
+                                    "$ref": "#/definitions/main.jsonPageResponse.envelope"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/repository.Amenity"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/repository.AuditEvent"
+                                            }
+                                        },
+                                        "meta": {
+                                            "$ref": "#/definitions/pagination.Page"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/main.WriteJSONError.envelope"
                         }
@@ -219,45 +306,26 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/admin/permissions": {
+            "get": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Update Amenity by ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get every permission that can be granted to a role",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Amenities"
-                ],
-                "summary": "Update Amenity",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Amenity ID",
-                        "name": "ID",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Payload update Amenity",
-                        "name": "Payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.UpdateAmenityPayload"
-                        }
-                    }
+                    "Admin"
                 ],
+                "summary": "Get Permissions",
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "allOf": [
                                 {
@@ -267,19 +335,16 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "string"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/repository.Permission"
+                                            }
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/main.WriteJSONError.envelope"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -287,35 +352,43 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/admin/roles": {
+            "get": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Delete Amenity by ID",
-                "tags": [
-                    "Amenities"
+                "description": "Get all roles with their permissions",
+                "produces": [
+                    "application/json"
                 ],
-                "summary": "Delete Amenity",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Amenity ID",
-                        "name": "ID",
-                        "in": "path",
-                        "required": true
-                    }
+                "tags": [
+                    "Admin"
                 ],
+                "summary": "Get Roles",
                 "responses": {
-                    "204": {
-                        "description": "No Content"
-                    },
-                    "404": {
-                        "description": "Not Found",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/main.jsonResponse.envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/repository.Role"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "500": {
@@ -325,11 +398,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/authentication/login": {
+            },
             "post": {
-                "description": "Login user",
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Create role with its permissions",
                 "consumes": [
                     "application/json"
                 ],
@@ -337,23 +413,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Admin"
                 ],
-                "summary": "Login user",
+                "summary": "Create Role",
                 "parameters": [
                     {
-                        "description": "Payload credential user, password: Tester_1234",
-                        "name": "Payload",
+                        "description": "Payload create role",
+                        "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.LoginPayload"
+                            "$ref": "#/definitions/main.CreateRolePayload"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
                             "allOf": [
                                 {
@@ -363,7 +439,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "string"
+                                            "$ref": "#/definitions/repository.Role"
                                         }
                                     }
                                 }
@@ -376,8 +452,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.WriteJSONError.envelope"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/main.WriteJSONError.envelope"
                         }
@@ -391,33 +467,33 @@ const docTemplate = `{
                 }
             }
         },
-        "/authentication/register": {
-            "post": {
-                "description": "Register new user",
-                "consumes": [
-                    "application/json"
+        "/admin/roles/{roleID}": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
                 ],
+                "description": "Get role by ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Admin"
                 ],
-                "summary": "Register user",
+                "summary": "Get Role",
                 "parameters": [
                     {
-                        "description": "Payload register new user",
-                        "name": "Payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.RegisterPayload"
-                        }
+                        "type": "integer",
+                        "description": "Role ID",
+                        "name": "roleID",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "allOf": [
                                 {
@@ -427,7 +503,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "string"
+                                            "$ref": "#/definitions/repository.Role"
                                         }
                                     }
                                 }
@@ -447,16 +523,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/bookings": {
-            "get": {
+            },
+            "put": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Get All Bookings",
+                "description": "Update role name, level or description by ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -464,27 +538,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Bookings"
+                    "Admin"
                 ],
-                "summary": "Get Bookings",
+                "summary": "Update Role",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "limit pages",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "skip rows",
-                        "name": "offset",
-                        "in": "query"
+                        "type": "integer",
+                        "description": "Role ID",
+                        "name": "roleID",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "sort latest(desc) older(asc)",
-                        "name": "sort",
-                        "in": "query"
+                        "description": "Payload update role",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdateRolePayload"
+                        }
                     }
                 ],
                 "responses": {
@@ -499,16 +571,31 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/repository.Booking"
-                                            }
+                                            "$ref": "#/definitions/repository.Role"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -517,13 +604,65 @@ const docTemplate = `{
                     }
                 }
             },
-            "post": {
+            "delete": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Create Booking",
+                "description": "Delete role by ID, the role must not be assigned to any user and some other role must keep roles:manage",
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Delete Role",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Role ID",
+                        "name": "roleID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/roles/{roleID}/permissions": {
+            "put": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Replace every permission of the role",
                 "consumes": [
                     "application/json"
                 ],
@@ -531,23 +670,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Bookings"
+                    "Admin"
                 ],
-                "summary": "Create Booking",
+                "summary": "Set Role Permissions",
                 "parameters": [
                     {
-                        "description": "payload create booking",
+                        "type": "integer",
+                        "description": "Role ID",
+                        "name": "roleID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Permission names",
                         "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.CreateBookingPayload"
+                            "$ref": "#/definitions/main.SetRolePermissionsPayload"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "allOf": [
                                 {
@@ -557,7 +703,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "string"
+                                            "$ref": "#/definitions/repository.Role"
                                         }
                                     }
                                 }
@@ -570,6 +716,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.WriteJSONError.envelope"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -579,29 +731,125 @@ const docTemplate = `{
                 }
             }
         },
-        "/bookings/{Id}": {
+        "/admin/users": {
             "get": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Get Booking By ID",
-                "consumes": [
+                "description": "Get all users, filter by role, activation and email",
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Get Users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "limit each page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "skip rows",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor of the next or prev page from meta",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sort latest(desc), older(asc) or by fields e.g. email",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "role name",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "activation status",
+                        "name": "active",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "part of the email",
+                        "name": "search",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/main.jsonPageResponse.envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/repository.User"
+                                            }
+                                        },
+                                        "meta": {
+                                            "$ref": "#/definitions/pagination.Page"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{userID}": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get user by ID whether active or not",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Bookings"
+                    "Admin"
                 ],
-                "summary": "Get Booking",
+                "summary": "Get User",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Booking ID",
-                        "name": "Id",
+                        "description": "User ID",
+                        "name": "userID",
                         "in": "path",
                         "required": true
                     }
@@ -618,7 +866,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/repository.Booking"
+                                            "$ref": "#/definitions/repository.User"
                                         }
                                     }
                                 }
@@ -645,22 +893,16 @@ const docTemplate = `{
                         "JWT": []
                     }
                 ],
-                "description": "Delete Booking By ID",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
+                "description": "Delete user by ID",
                 "tags": [
-                    "Bookings"
+                    "Admin"
                 ],
-                "summary": "Delete Booking",
+                "summary": "Delete User",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Booking ID",
-                        "name": "Id",
+                        "description": "User ID",
+                        "name": "userID",
                         "in": "path",
                         "required": true
                     }
@@ -669,6 +911,12 @@ const docTemplate = `{
                     "204": {
                         "description": "No Content"
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -684,14 +932,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/bookings/{Id}/check-in": {
-            "patch": {
+        "/admin/users/{userID}/activation": {
+            "put": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Check in Booking By ID",
+                "description": "Toggle the user activation, an inactive user can not log in",
                 "consumes": [
                     "application/json"
                 ],
@@ -699,30 +947,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Bookings"
+                    "Admin"
                 ],
-                "summary": "Check in Booking",
+                "summary": "Activate or Deactivate User",
                 "parameters": [
                     {
-                        "description": "status booking",
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Activation status",
                         "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.UpdateBookingStatus"
+                            "$ref": "#/definitions/main.UpdateUserActivationPayload"
                         }
-                    },
-                    {
-                        "type": "integer",
-                        "description": "booking id",
-                        "name": "Id",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "allOf": [
                                 {
@@ -732,7 +980,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "string"
+                                            "$ref": "#/definitions/repository.User"
                                         }
                                     }
                                 }
@@ -745,6 +993,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.WriteJSONError.envelope"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -754,38 +1008,26 @@ const docTemplate = `{
                 }
             }
         },
-        "/bookings/{Id}/check-out": {
-            "patch": {
+        "/admin/users/{userID}/impersonate": {
+            "post": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Check out Booking By ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Issue a short-lived token acting as the user for customer support. The user must not have more permissions than the admin",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Bookings"
+                    "Admin"
                 ],
-                "summary": "Check out Booking",
+                "summary": "Impersonate user",
                 "parameters": [
-                    {
-                        "description": "status booking",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.UpdateBookingStatus"
-                        }
-                    },
                     {
                         "type": "integer",
-                        "description": "booking id",
-                        "name": "Id",
+                        "description": "User ID",
+                        "name": "userID",
                         "in": "path",
                         "required": true
                     }
@@ -802,7 +1044,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "string"
+                                            "$ref": "#/definitions/main.ImpersonationResponse"
                                         }
                                     }
                                 }
@@ -815,71 +1057,11 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.WriteJSONError.envelope"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/main.WriteJSONError.envelope"
                         }
-                    }
-                }
-            }
-        },
-        "/categories": {
-            "get": {
-                "security": [
-                    {
-                        "JWT": []
-                    }
-                ],
-                "description": "Get all categories",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Categories"
-                ],
-                "summary": "Get categories",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "limit pages",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "skip rows",
-                        "name": "offset",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "sort latest(desc) older(asc)",
-                        "name": "sort",
-                        "in": "query"
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/main.jsonResponse.envelope"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/main.CategoryResponse"
-                                            }
-                                        }
-                                    }
-                                }
-                            ]
-                        }
                     },
                     "404": {
                         "description": "Not Found",
@@ -894,14 +1076,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/admin/users/{userID}/role": {
+            "put": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Create category",
+                "description": "Assign a role to the user, neither the current nor the new role may hold a permission the admin lacks",
                 "consumes": [
                     "application/json"
                 ],
@@ -909,23 +1093,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Categories"
+                    "Admin"
                 ],
-                "summary": "Create category",
+                "summary": "Change User Role",
                 "parameters": [
                     {
-                        "description": "json format payload",
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Role name",
                         "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.CreateCategoryPayload"
+                            "$ref": "#/definitions/main.UpdateUserRolePayload"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "allOf": [
                                 {
@@ -935,7 +1126,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "string"
+                                            "$ref": "#/definitions/repository.User"
                                         }
                                     }
                                 }
@@ -948,8 +1139,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.WriteJSONError.envelope"
                         }
                     },
-                    "409": {
-                        "description": "Conflict",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/main.WriteJSONError.envelope"
                         }
@@ -963,26 +1160,26 @@ const docTemplate = `{
                 }
             }
         },
-        "/categories/{ID}": {
+        "/admin/users/{userID}/sessions": {
             "get": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Get category by ID",
+                "description": "Get the active sessions of a user",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Categories"
+                    "Admin"
                 ],
-                "summary": "Get category",
+                "summary": "Get user's sessions",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "category id",
-                        "name": "ID",
+                        "description": "User ID",
+                        "name": "userID",
                         "in": "path",
                         "required": true
                     }
@@ -999,7 +1196,10 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/repository.Category"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/repository.Session"
+                                            }
                                         }
                                     }
                                 }
@@ -1020,59 +1220,29 @@ const docTemplate = `{
                     }
                 }
             },
-            "put": {
+            "delete": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Update Category by ID",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
+                "description": "Sign out every session of a user, e.g. when the account is compromised",
                 "tags": [
-                    "Categories"
+                    "Admin"
                 ],
-                "summary": "Update Category",
+                "summary": "Sign out user everywhere",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Category ID",
-                        "name": "ID",
+                        "description": "User ID",
+                        "name": "userID",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Payload update category",
-                        "name": "Payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.UpdateCategoryPayload"
-                        }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/main.jsonResponse.envelope"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "type": "string"
-                                        }
-                                    }
-                                }
-                            ]
-                        }
+                    "204": {
+                        "description": "No Content"
                     },
                     "404": {
                         "description": "Not Found",
@@ -1087,23 +1257,32 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/admin/users/{userID}/sessions/{sessionID}": {
             "delete": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Delete Category by ID",
+                "description": "Sign out one session of a user",
                 "tags": [
-                    "Categories"
+                    "Admin"
                 ],
-                "summary": "Delete Category",
+                "summary": "Sign out user's session",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Category ID",
-                        "name": "ID",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "sessionID",
                         "in": "path",
                         "required": true
                     }
@@ -1127,37 +1306,90 @@ const docTemplate = `{
                 }
             }
         },
-        "/health": {
+        "/admin/villas": {
             "get": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Check Response",
+                "description": "Get the villas of every status, with the filters of the villa list",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "Health"
+                    "Villas"
+                ],
+                "summary": "Get Villas Of Every Status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "limit each page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "skip rows",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor of the next or prev page from meta",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sort direction asc or desc, or fields e.g. -price,bedrooms",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "draft, in_review, published or archived, comma separated",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "search the name, description, location and amenities, sorted by relevance",
+                        "name": "q",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Health",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/main.jsonResponse.envelope"
+                                    "$ref": "#/definitions/main.jsonPageResponse.envelope"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "string"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/repository.Villa"
+                                            }
+                                        },
+                                        "meta": {
+                                            "$ref": "#/definitions/pagination.Page"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1167,21 +1399,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/locations": {
+        "/amenities": {
             "get": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Get All Locations",
+                "description": "Get all Amenities",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Locations"
+                    "Amenities"
                 ],
-                "summary": "Get locations",
+                "summary": "Get Amenities",
                 "parameters": [
                     {
                         "type": "string",
@@ -1197,9 +1429,27 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "sort latest(desc) older(asc)",
+                        "description": "cursor of the next or prev page from meta",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sort latest(desc), older(asc) or by fields e.g. name,-created_at",
                         "name": "sort",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "name of the amenity contains",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "type id of the amenity",
+                        "name": "type_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -1208,7 +1458,7 @@ const docTemplate = `{
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/main.jsonResponse.envelope"
+                                    "$ref": "#/definitions/main.jsonPageResponse.envelope"
                                 },
                                 {
                                     "type": "object",
@@ -1216,14 +1466,23 @@ const docTemplate = `{
                                         "data": {
                                             "type": "array",
                                             "items": {
-                                                "$ref": "#/definitions/main.LocationResponse"
+                                                "$ref": "#/definitions/main.AmenityResponse"
                                             }
+                                        },
+                                        "meta": {
+                                            "$ref": "#/definitions/pagination.Page"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1238,7 +1497,7 @@ const docTemplate = `{
                         "JWT": []
                     }
                 ],
-                "description": "Create new location",
+                "description": "Create Amenity",
                 "consumes": [
                     "application/json"
                 ],
@@ -1246,17 +1505,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Locations"
+                    "Amenities"
                 ],
-                "summary": "Create location",
+                "summary": "Create Amenity",
                 "parameters": [
                     {
-                        "description": "Payload create new location",
-                        "name": "Payload",
+                        "description": "json format payload",
+                        "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.CreateLocationPayload"
+                            "$ref": "#/definitions/main.CreateAmenityPayload"
                         }
                     }
                 ],
@@ -1300,25 +1559,25 @@ const docTemplate = `{
                 }
             }
         },
-        "/locations/{ID}": {
+        "/amenities/{ID}": {
             "get": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Get Location By ID",
+                "description": "Get Amenity by ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Locations"
+                    "Amenities"
                 ],
-                "summary": "Get location",
+                "summary": "Get Amenity",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Location ID",
+                        "description": "amenity id",
                         "name": "ID",
                         "in": "path",
                         "required": true
@@ -1336,7 +1595,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/repository.Location"
+                                            "$ref": "#/definitions/repository.Amenity"
                                         }
                                     }
                                 }
@@ -1363,7 +1622,7 @@ const docTemplate = `{
                         "JWT": []
                     }
                 ],
-                "description": "Update new Location",
+                "description": "Update Amenity by ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -1371,24 +1630,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Locations"
+                    "Amenities"
                 ],
-                "summary": "Update Location",
+                "summary": "Update Amenity",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Location Id",
+                        "description": "Amenity ID",
                         "name": "ID",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "body new location",
-                        "name": "payload",
+                        "description": "Payload update Amenity",
+                        "name": "Payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.UpdateLocationPayload"
+                            "$ref": "#/definitions/main.UpdateAmenityPayload"
                         }
                     }
                 ],
@@ -1411,24 +1670,12 @@ const docTemplate = `{
                             ]
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/main.WriteJSONError.envelope"
-                        }
-                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/main.WriteJSONError.envelope"
                         }
                     },
-                    "409": {
-                        "description": "Conflict",
-                        "schema": {
-                            "$ref": "#/definitions/main.WriteJSONError.envelope"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1443,15 +1690,15 @@ const docTemplate = `{
                         "JWT": []
                     }
                 ],
-                "description": "Delete Location by ID",
+                "description": "Delete Amenity by ID",
                 "tags": [
-                    "Locations"
+                    "Amenities"
                 ],
-                "summary": "Delete Location",
+                "summary": "Delete Amenity",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Location ID",
+                        "description": "Amenity ID",
                         "name": "ID",
                         "in": "path",
                         "required": true
@@ -1476,21 +1723,30 @@ const docTemplate = `{
                 }
             }
         },
-        "/types": {
-            "get": {
-                "security": [
-                    {
-                        "JWT": []
-                    }
+        "/authentication/login": {
+            "post": {
+                "description": "Login user",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "Get all types",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Types"
+                    "Auth"
+                ],
+                "summary": "Login user",
+                "parameters": [
+                    {
+                        "description": "Payload credential user, password: Tester_1234",
+                        "name": "Payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.LoginPayload"
+                        }
+                    }
                 ],
-                "summary": "Get types",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -1503,18 +1759,21 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/main.TypeResponse"
-                                            }
+                                            "type": "string"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/main.WriteJSONError.envelope"
                         }
@@ -1526,38 +1785,30 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "security": [
-                    {
-                        "JWT": []
-                    }
-                ],
-                "description": "Create Type",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/authentication/oidc/{provider}": {
+            "get": {
+                "description": "Get the URL of the identity provider to sign in with, the provider redirects back with code and state",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Types"
+                    "Auth"
                 ],
-                "summary": "Create Type",
+                "summary": "Start social login",
                 "parameters": [
                     {
-                        "description": "json format payload",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.CreateTypePayload"
-                        }
+                        "type": "string",
+                        "description": "provider name, e.g. google",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "allOf": [
                                 {
@@ -1567,21 +1818,15 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "string"
+                                            "$ref": "#/definitions/main.OIDCAuthorizeResponse"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/main.WriteJSONError.envelope"
-                        }
-                    },
-                    "409": {
-                        "description": "Conflict",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/main.WriteJSONError.envelope"
                         }
@@ -1595,28 +1840,35 @@ const docTemplate = `{
                 }
             }
         },
-        "/types/{ID}": {
-            "get": {
-                "security": [
-                    {
-                        "JWT": []
-                    }
+        "/authentication/oidc/{provider}/callback": {
+            "post": {
+                "description": "Exchange the code from the identity provider for a session token, the account is linked by verified email",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "Get Type by ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Types"
+                    "Auth"
                 ],
-                "summary": "Get Type",
+                "summary": "Finish social login",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Type id",
-                        "name": "ID",
+                        "type": "string",
+                        "description": "provider name, e.g. google",
+                        "name": "provider",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "code and state from the provider redirect",
+                        "name": "Payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.OIDCCallbackPayload"
+                        }
                     }
                 ],
                 "responses": {
@@ -1631,19 +1883,37 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/repository.Type"
+                                            "type": "string"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/main.WriteJSONError.envelope"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1651,14 +1921,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
-                "security": [
-                    {
-                        "JWT": []
-                    }
-                ],
-                "description": "Update type by ID",
+            }
+        },
+        "/authentication/register": {
+            "post": {
+                "description": "Register new user",
                 "consumes": [
                     "application/json"
                 ],
@@ -1666,24 +1933,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Types"
+                    "Auth"
                 ],
-                "summary": "Update type",
+                "summary": "Register user",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "type ID",
-                        "name": "ID",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Payload update type",
+                        "description": "Payload register new user",
                         "name": "Payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.UpdateTypePayload"
+                            "$ref": "#/definitions/main.RegisterPayload"
                         }
                     }
                 ],
@@ -1719,35 +1979,87 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/bookings": {
+            "get": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Delete Type by ID",
+                "description": "Get All Bookings",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "Types"
+                    "Bookings"
                 ],
-                "summary": "Delete Type",
+                "summary": "Get Bookings",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "limit pages",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "skip rows",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor of the next or prev page from meta",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sort latest(desc), older(asc) or by fields e.g. -start_at or total_price",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "statuses of the booking, comma separated",
+                        "name": "status",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "Type ID",
-                        "name": "ID",
-                        "in": "path",
-                        "required": true
+                        "description": "villa id of the booking",
+                        "name": "villa_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
-                    },
-                    "404": {
-                        "description": "Not Found",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/main.jsonPageResponse.envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/repository.Booking"
+                                            }
+                                        },
+                                        "meta": {
+                                            "$ref": "#/definitions/pagination.Page"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "500": {
@@ -1757,16 +2069,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/users": {
+            },
             "post": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Create user",
+                "description": "Create Booking",
                 "consumes": [
                     "application/json"
                 ],
@@ -1774,17 +2084,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Bookings"
                 ],
-                "summary": "Create user",
+                "summary": "Create Booking",
                 "parameters": [
                     {
-                        "description": "Payload create user",
+                        "description": "payload create booking",
                         "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.CreateUserPayload"
+                            "$ref": "#/definitions/main.CreateBookingPayload"
                         }
                     }
                 ],
@@ -1807,6 +2117,111 @@ const docTemplate = `{
                             ]
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/bookings/{Id}": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get Booking By ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Bookings"
+                ],
+                "summary": "Get Booking",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Booking ID",
+                        "name": "Id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/main.jsonResponse.envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/repository.Booking"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Delete Booking By ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Bookings"
+                ],
+                "summary": "Delete Booking",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Booking ID",
+                        "name": "Id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -1822,21 +2237,38 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/activate/{token}": {
-            "put": {
-                "description": "Activate user after register account",
+        "/bookings/{Id}/check-in": {
+            "patch": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Check in Booking By ID",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Bookings"
                 ],
-                "summary": "Activate user",
+                "summary": "Check in Booking",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "token activation",
-                        "name": "token",
+                        "description": "status booking",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdateBookingStatus"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "booking id",
+                        "name": "Id",
                         "in": "path",
                         "required": true
                     }
@@ -1860,6 +2292,12 @@ const docTemplate = `{
                             ]
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1869,24 +2307,2354 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/bookings": {
-            "get": {
+        "/bookings/{Id}/check-out": {
+            "patch": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Get all User's Bookings",
+                "description": "Check out Booking By ID",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Bookings"
+                ],
+                "summary": "Check out Booking",
+                "parameters": [
+                    {
+                        "description": "status booking",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdateBookingStatus"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "booking id",
+                        "name": "Id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/main.jsonResponse.envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/bookings/{bookingID}/documents": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get the guest documents of the booking with signed URLs that expire",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Bookings"
+                ],
+                "summary": "Get Booking Documents",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "booking id",
+                        "name": "bookingID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/main.jsonResponse.envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/repository.BookingDocument"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Upload the guest documents of the check-in registration, e.g. a passport",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Bookings"
+                ],
+                "summary": "Upload Booking Documents",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "booking id",
+                        "name": "bookingID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "PDF, JPEG or PNG file",
+                        "name": "documents",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/main.jsonResponse.envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/categories": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get all categories",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Categories"
+                ],
+                "summary": "Get categories",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "limit pages",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "skip rows",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor of the next or prev page from meta",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sort latest(desc), older(asc) or by fields e.g. name",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "name of the category contains",
+                        "name": "name",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/main.jsonPageResponse.envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/main.CategoryResponse"
+                                            }
+                                        },
+                                        "meta": {
+                                            "$ref": "#/definitions/pagination.Page"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Create category",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Categories"
+                ],
+                "summary": "Create category",
+                "parameters": [
+                    {
+                        "description": "json format payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.CreateCategoryPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/main.jsonResponse.envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/categories/{ID}": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get category by ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Categories"
+                ],
+                "summary": "Get category",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "category id",
+                        "name": "ID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/main.jsonResponse.envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/repository.Category"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Update Category by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Categories"
+                ],
+                "summary": "Update Category",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Category ID",
+                        "name": "ID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Payload update category",
+                        "name": "Payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdateCategoryPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/main.jsonResponse.envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Delete Category by ID",
+                "tags": [
+                    "Categories"
+                ],
+                "summary": "Delete Category",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Category ID",
+                        "name": "ID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/files/private/{group}/{name}": {
+            "get": {
+                "description": "Stream a private file through a signed URL, the signature replaces the authentication",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "Files"
+                ],
+                "summary": "Get private file",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "File group",
+                        "name": "group",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "File name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Unix time the URL expires at",
+                        "name": "expires",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Content disposition",
+                        "name": "disposition",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Signature of the URL",
+                        "name": "signature",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Check Response",
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Health",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/main.jsonResponse.envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/locations": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get All Locations",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Locations"
+                ],
+                "summary": "Get locations",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "limit pages",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "skip rows",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor of the next or prev page from meta",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sort latest(desc), older(asc) or by fields e.g. area",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "area of the location contains",
+                        "name": "area",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/main.jsonPageResponse.envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/main.LocationResponse"
+                                            }
+                                        },
+                                        "meta": {
+                                            "$ref": "#/definitions/pagination.Page"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Create new location",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Locations"
+                ],
+                "summary": "Create location",
+                "parameters": [
+                    {
+                        "description": "Payload create new location",
+                        "name": "Payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.CreateLocationPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/main.jsonResponse.envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/locations/{ID}": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get Location By ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Locations"
+                ],
+                "summary": "Get location",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Location ID",
+                        "name": "ID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/main.jsonResponse.envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/repository.Location"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Update new Location",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Locations"
+                ],
+                "summary": "Update Location",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Location Id",
+                        "name": "ID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "body new location",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdateLocationPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/main.jsonResponse.envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Delete Location by ID",
+                "tags": [
+                    "Locations"
+                ],
+                "summary": "Delete Location",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Location ID",
+                        "name": "ID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/types": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get all types",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Types"
+                ],
+                "summary": "Get types",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/main.jsonResponse.envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/main.TypeResponse"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Create Type",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Types"
+                ],
+                "summary": "Create Type",
+                "parameters": [
+                    {
+                        "description": "json format payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.CreateTypePayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/main.jsonResponse.envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/types/{ID}": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get Type by ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Types"
+                ],
+                "summary": "Get Type",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Type id",
+                        "name": "ID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/main.jsonResponse.envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/repository.Type"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Update type by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Types"
+                ],
+                "summary": "Update type",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "type ID",
+                        "name": "ID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Payload update type",
+                        "name": "Payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdateTypePayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/main.jsonResponse.envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Delete Type by ID",
+                "tags": [
+                    "Types"
+                ],
+                "summary": "Delete Type",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Type ID",
+                        "name": "ID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Create user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Create user",
+                "parameters": [
+                    {
+                        "description": "Payload create user",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.CreateUserPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/main.jsonResponse.envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/activate/{token}": {
+            "put": {
+                "description": "Activate user after register account",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Activate user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "token activation",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/main.jsonResponse.envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/bookings": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get all User's Bookings",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "User's Bookings",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/main.jsonResponse.envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/repository.User"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/email/confirm/{token}": {
+            "put": {
+                "description": "Swap the email of the user after the confirmation link is opened",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Confirm email change",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "token email change",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/main.jsonResponse.envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me": {
+            "delete": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Delete the account of the user who is logged in, personal details on past bookings are anonymized",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Delete account",
+                "parameters": [
+                    {
+                        "description": "Current password",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.DeleteAccountPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/consents": {
+            "put": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Grant or withdraw a consent of the user who is logged in",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Update consent",
+                "parameters": [
+                    {
+                        "description": "Consent purpose and decision",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdateConsentPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/main.jsonResponse.envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/repository.Consent"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/export": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Export profile, bookings and consents of the user who is logged in",
+                "produces": [
+                    "application/json",
+                    "application/zip"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Export personal data",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "json (default) or zip",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.UserDataExport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/profile": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Profile user after login",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Profile user",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/main.jsonResponse.envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/main.ProfileUserResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Update username, full name, phone or preferred language of the user who is logged in",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Update profile",
+                "parameters": [
+                    {
+                        "description": "Payload update profile",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdateProfilePayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/main.jsonResponse.envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/main.ProfileUserResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/profile/email": {
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Send a confirmation link to the new email, the email is changed after the link is confirmed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Change email",
+                "parameters": [
+                    {
+                        "description": "New email and current password",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.ChangeEmailPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/main.jsonResponse.envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Get the devices the user is signed in on, the session of this request is marked current",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get sessions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/main.jsonResponse.envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/repository.Session"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/sessions/{sessionID}": {
+            "delete": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Sign out one of the user's sessions, the token of the session stops working",
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Sign out session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "sessionID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/villas": {
+            "get": {
+                "description": "Get All Villas",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Villas"
+                ],
+                "summary": "Get Villa",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "limit each page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "skip rows",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor of the next or prev page from meta",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sort direction asc or desc, or fields e.g. -price,bedrooms",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sort by newest, price, bedrooms, relevance or distance",
+                        "name": "sort_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "locations villa, comma separated",
+                        "name": "location",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "categories villa, comma separated",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "bedrooms villa",
+                        "name": "bedrooms",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "min guest villa",
+                        "name": "min_guest",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "min price villa",
+                        "name": "price_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "max price villa",
+                        "name": "price_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "amenity ids the villa has all of, comma separated",
+                        "name": "amenities",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "search the name, description, location and amenities, sorted by relevance",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "lat,lng of a point, sorted by distance",
+                        "name": "near",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "radius around near in km, default 5",
+                        "name": "radius_km",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "min_lat,min_lng,max_lat,max_lng of a map view",
+                        "name": "bbox",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/main.jsonPageResponse.envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/repository.Villa"
+                                            }
+                                        },
+                                        "meta": {
+                                            "$ref": "#/definitions/pagination.Page"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Create Villa",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Villas"
+                ],
+                "summary": "Create Villa",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Image file",
+                        "name": "thumbnail",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Image file",
+                        "name": "others",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "example": "{\"name\":\"villa name\",\"description\":\"villa description\",\"min_guest\":1,\"bedrooms\":1,\"price\":25,\"location_id\":3,\"category_id\":2,\"baths\":1,\"amenity_id\":[4],\"latitude\":-8.6913,\"longitude\":115.1682}",
+                        "description": "CreateVillaProp JSON string",
+                        "name": "properties",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/main.jsonResponse.envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/villas/facets": {
+            "get": {
+                "description": "Count the villas by category, location, amenity and price for the filters",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Villas"
+                ],
+                "summary": "Get Villa Facets",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "locations villa, comma separated",
+                        "name": "location",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "categories villa, comma separated",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "bedrooms villa",
+                        "name": "bedrooms",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "min guest villa",
+                        "name": "min_guest",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "min price villa",
+                        "name": "price_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "max price villa",
+                        "name": "price_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "amenity ids the villa has all of, comma separated",
+                        "name": "amenities",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "search the name, description, location and amenities",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "lat,lng of a point",
+                        "name": "near",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "radius around near in km, default 5",
+                        "name": "radius_km",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "min_lat,min_lng,max_lat,max_lng of a map view",
+                        "name": "bbox",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/main.jsonResponse.envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/repository.VillaFacets"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/villas/map": {
+            "get": {
+                "description": "Get the villas within the box of a map view, with the filters of the villa list",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Villas"
+                ],
+                "summary": "Get Villa Pins",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "min_lat,min_lng,max_lat,max_lng of the map view",
+                        "name": "bbox",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "locations villa, comma separated",
+                        "name": "location",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "categories villa, comma separated",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "min price villa",
+                        "name": "price_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "max price villa",
+                        "name": "price_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "amenity ids the villa has all of, comma separated",
+                        "name": "amenities",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "search the name, description, location and amenities",
+                        "name": "q",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/main.jsonResponse.envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/repository.VillaPin"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    }
+                }
+            }
+        },
+        "/villas/{ID}": {
+            "put": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Update Villa by ID, a published villa is only updated with villas:publish",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Villas"
+                ],
+                "summary": "Update Villa",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Villa ID",
+                        "name": "ID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Image file",
+                        "name": "thumbnail",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "file",
+                        "description": "Image file",
+                        "name": "others",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "example": "{\"name\":\"villa name\",\"description\":\"villa description\",\"min_guest\":1,\"bedrooms\":1,\"price\":25,\"location_id\":3,\"category_id\":2,\"baths\":1}",
+                        "description": "Update Villa Props JSON string",
+                        "name": "properties",
+                        "in": "formData"
+                    }
                 ],
-                "summary": "User's Bookings",
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
                             "allOf": [
                                 {
@@ -1896,13 +4664,25 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/repository.User"
+                                            "type": "string"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1912,21 +4692,25 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/profile": {
+        "/villas/{villaID}": {
             "get": {
-                "security": [
-                    {
-                        "JWT": []
-                    }
-                ],
-                "description": "Profile user after login",
+                "description": "Get Villa By ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Villas"
+                ],
+                "summary": "Get Villa",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Villa ID",
+                        "name": "villaID",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Profile user",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -1939,13 +4723,19 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/main.ProfileUserResponse"
+                                            "$ref": "#/definitions/repository.Villa"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1953,82 +4743,38 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/villas": {
-            "get": {
-                "description": "Get All Villas",
+            },
+            "delete": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Delete Villa By ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Villas"
                 ],
-                "summary": "Get Villa",
+                "summary": "Delete Villa",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "limit each page",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "skip rows",
-                        "name": "offset",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "sort villa latest(desc), older(asc)",
-                        "name": "sort",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "location villa",
-                        "name": "location",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "category villa",
-                        "name": "category",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "bedrooms villa",
-                        "name": "bedrooms",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "min guest villa",
-                        "name": "min_guest",
-                        "in": "query"
+                        "type": "integer",
+                        "description": "Villa ID",
+                        "name": "villaID",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/main.jsonResponse.envelope"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/repository.Villa"
-                                            }
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
                         }
                     },
                     "500": {
@@ -2038,14 +4784,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/villas/{villaID}/images": {
             "post": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Create Villa",
+                "description": "Append images to the villa, the first image stays the cover",
                 "consumes": [
                     "multipart/form-data"
                 ],
@@ -2055,26 +4803,19 @@ const docTemplate = `{
                 "tags": [
                     "Villas"
                 ],
-                "summary": "Create Villa",
+                "summary": "Add villa images",
                 "parameters": [
                     {
-                        "type": "file",
-                        "description": "Image file",
-                        "name": "thumbnail",
-                        "in": "formData",
+                        "type": "integer",
+                        "description": "Villa ID",
+                        "name": "villaID",
+                        "in": "path",
                         "required": true
                     },
                     {
                         "type": "file",
                         "description": "Image file",
-                        "name": "others",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "example": "{\"name\":\"villa name\",\"description\":\"villa description\",\"min_guest\":1,\"bedrooms\":1,\"price\":25,\"location_id\":3,\"category_id\":2,\"baths\":1,\"amenity_id\":[4]}",
-                        "description": "CreateVillaProp JSON string",
-                        "name": "properties",
+                        "name": "images",
                         "in": "formData",
                         "required": true
                     }
@@ -2091,7 +4832,10 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "string"
+                                            "type": "array",
+                                            "items": {
+                                                "type": "string"
+                                            }
                                         }
                                     }
                                 }
@@ -2104,6 +4848,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.WriteJSONError.envelope"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -2119,16 +4869,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/villas/{ID}": {
+        "/villas/{villaID}/images/order": {
             "put": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Update Villa by ID",
+                "description": "Reorder the images of the villa, the cover is moved to the first position",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
@@ -2136,38 +4886,28 @@ const docTemplate = `{
                 "tags": [
                     "Villas"
                 ],
-                "summary": "Update Villa",
+                "summary": "Order villa images",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "Villa ID",
-                        "name": "ID",
+                        "name": "villaID",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "file",
-                        "description": "Image file",
-                        "name": "thumbnail",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "file",
-                        "description": "Image file",
-                        "name": "others",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "example": "{\"name\":\"villa name\",\"description\":\"villa description\",\"min_guest\":1,\"bedrooms\":1,\"price\":25,\"location_id\":3,\"category_id\":2,\"baths\":1}",
-                        "description": "Update Villa Props JSON string",
-                        "name": "properties",
-                        "in": "formData"
+                        "description": "new order and/or cover image",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.OrderVillaImagesPayload"
+                        }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "allOf": [
                                 {
@@ -2177,13 +4917,28 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "string"
+                                            "type": "array",
+                                            "items": {
+                                                "type": "string"
+                                            }
                                         }
                                     }
                                 }
                             ]
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -2199,16 +4954,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/villas/{villaID}": {
-            "get": {
-                "description": "Get Villa By ID",
+        "/villas/{villaID}/images/{filename}": {
+            "delete": {
+                "security": [
+                    {
+                        "JWT": []
+                    }
+                ],
+                "description": "Remove one image of the villa by filename, the file is deleted as well",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Villas"
                 ],
-                "summary": "Get Villa",
+                "summary": "Remove villa image",
                 "parameters": [
                     {
                         "type": "integer",
@@ -2216,6 +4976,13 @@ const docTemplate = `{
                         "name": "villaID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Image filename",
+                        "name": "filename",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -2230,13 +4997,28 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/repository.Villa"
+                                            "type": "array",
+                                            "items": {
+                                                "type": "string"
+                                            }
                                         }
                                     }
                                 }
                             ]
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -2250,21 +5032,26 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/villas/{villaID}/status": {
+            "patch": {
                 "security": [
                     {
                         "JWT": []
                     }
                 ],
-                "description": "Delete Villa By ID",
+                "description": "Move the villa through draft, in_review, published and archived, editors submit and managers publish",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Villas"
                 ],
-                "summary": "Delete Villa",
+                "summary": "Update Villa Status",
                 "parameters": [
                     {
                         "type": "integer",
@@ -2272,14 +5059,50 @@ const docTemplate = `{
                         "name": "villaID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "payload villa status",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdateVillaStatusPayload"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/main.jsonResponse.envelope"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/main.WriteJSONError.envelope"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/main.WriteJSONError.envelope"
                         }
@@ -2295,6 +5118,46 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "auth.JWK": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string"
+                },
+                "crv": {
+                    "type": "string"
+                },
+                "e": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "kty": {
+                    "type": "string"
+                },
+                "n": {
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string"
+                },
+                "x": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.JWKSet": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/auth.JWK"
+                    }
+                }
+            }
+        },
         "main.AmenityResponse": {
             "type": "object",
             "properties": {
@@ -2329,6 +5192,22 @@ const docTemplate = `{
                 }
             }
         },
+        "main.ChangeEmailPayload": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "maxLength": 254
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
         "main.CreateAmenityPayload": {
             "type": "object",
             "properties": {
@@ -2341,6 +5220,47 @@ const docTemplate = `{
                 }
             }
         },
+        "main.CreateApiKeyPayload": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes",
+                "user_id"
+            ],
+            "properties": {
+                "expires_in_days": {
+                    "type": "integer",
+                    "maximum": 730,
+                    "minimum": 0
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 3
+                },
+                "scopes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.CreateApiKeyResponse": {
+            "type": "object",
+            "properties": {
+                "api_key": {
+                    "$ref": "#/definitions/repository.ApiKey"
+                },
+                "key": {
+                    "type": "string"
+                }
+            }
+        },
         "main.CreateBookingPayload": {
             "type": "object",
             "required": [
@@ -2418,6 +5338,43 @@ const docTemplate = `{
                 "area": {
                     "type": "string",
                     "minLength": 3
+                },
+                "latitude": {
+                    "type": "number",
+                    "maximum": 90,
+                    "minimum": -90
+                },
+                "longitude": {
+                    "type": "number",
+                    "maximum": 180,
+                    "minimum": -180
+                }
+            }
+        },
+        "main.CreateRolePayload": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "level": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "minLength": 3
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
@@ -2443,13 +5400,36 @@ const docTemplate = `{
             "properties": {
                 "email": {
                     "type": "string",
-                    "maxLength": 21
+                    "maxLength": 254
                 },
                 "password": {
                     "type": "string",
                     "maxLength": 12
                 },
                 "username": {
+                    "type": "string",
+                    "maxLength": 64
+                }
+            }
+        },
+        "main.DeleteAccountPayload": {
+            "type": "object",
+            "required": [
+                "password"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.ImpersonationResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "token": {
                     "type": "string"
                 }
             }
@@ -2465,6 +5445,12 @@ const docTemplate = `{
                 },
                 "id": {
                     "type": "integer"
+                },
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
                 }
             }
         },
@@ -2483,15 +5469,61 @@ const docTemplate = `{
                 }
             }
         },
+        "main.OIDCAuthorizeResponse": {
+            "type": "object",
+            "properties": {
+                "authorization_url": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.OIDCCallbackPayload": {
+            "type": "object",
+            "required": [
+                "code",
+                "state"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.OrderVillaImagesPayload": {
+            "type": "object",
+            "properties": {
+                "cover": {
+                    "type": "string"
+                },
+                "images": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "main.ProfileUserResponse": {
             "type": "object",
             "properties": {
                 "email": {
                     "type": "string"
                 },
+                "fullName": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
+                "phone": {
+                    "type": "string"
+                },
+                "preferredLanguage": {
+                    "type": "string"
+                },
                 "username": {
                     "type": "string"
                 }
@@ -2507,6 +5539,7 @@ const docTemplate = `{
             "properties": {
                 "email": {
                     "type": "string",
+                    "maxLength": 254,
                     "minLength": 5
                 },
                 "password": {
@@ -2514,7 +5547,22 @@ const docTemplate = `{
                     "minLength": 5
                 },
                 "username": {
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 64
+                }
+            }
+        },
+        "main.SetRolePermissionsPayload": {
+            "type": "object",
+            "required": [
+                "permissions"
+            ],
+            "properties": {
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
@@ -2567,6 +5615,25 @@ const docTemplate = `{
                 }
             }
         },
+        "main.UpdateConsentPayload": {
+            "type": "object",
+            "required": [
+                "granted",
+                "purpose"
+            ],
+            "properties": {
+                "granted": {
+                    "type": "boolean"
+                },
+                "purpose": {
+                    "type": "string",
+                    "enum": [
+                        "terms",
+                        "marketing_email"
+                    ]
+                }
+            }
+        },
         "main.UpdateLocationPayload": {
             "type": "object",
             "required": [
@@ -2576,18 +5643,133 @@ const docTemplate = `{
                 "area": {
                     "type": "string",
                     "minLength": 3
+                },
+                "latitude": {
+                    "type": "number",
+                    "maximum": 90,
+                    "minimum": -90
+                },
+                "longitude": {
+                    "type": "number",
+                    "maximum": 180,
+                    "minimum": -180
+                }
+            }
+        },
+        "main.UpdateProfilePayload": {
+            "type": "object",
+            "properties": {
+                "full_name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "preferred_language": {
+                    "type": "string",
+                    "maxLength": 8
+                },
+                "username": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "minLength": 1
+                }
+            }
+        },
+        "main.UpdateRolePayload": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "level": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "minLength": 3
+                }
+            }
+        },
+        "main.UpdateTypePayload": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "minLength": 3
+                }
+            }
+        },
+        "main.UpdateUserActivationPayload": {
+            "type": "object",
+            "required": [
+                "is_active"
+            ],
+            "properties": {
+                "is_active": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "main.UpdateUserRolePayload": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "type": "string"
                 }
             }
         },
-        "main.UpdateTypePayload": {
+        "main.UpdateVillaStatusPayload": {
             "type": "object",
             "required": [
-                "name"
+                "status"
             ],
             "properties": {
-                "name": {
+                "publish_at": {
+                    "description": "PublishAt schedules the publishing, left out the villa is published now",
+                    "type": "string"
+                },
+                "status": {
                     "type": "string",
-                    "minLength": 3
+                    "enum": [
+                        "draft",
+                        "in_review",
+                        "published",
+                        "archived"
+                    ]
+                }
+            }
+        },
+        "main.UserDataExport": {
+            "type": "object",
+            "properties": {
+                "bookings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/repository.Booking"
+                    }
+                },
+                "consents": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/repository.Consent"
+                    }
+                },
+                "exported_at": {
+                    "type": "string"
+                },
+                "profile": {
+                    "$ref": "#/definitions/main.ProfileUserResponse"
                 }
             }
         },
@@ -2602,12 +5784,41 @@ const docTemplate = `{
                 }
             }
         },
+        "main.jsonPageResponse.envelope": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "meta": {
+                    "$ref": "#/definitions/pagination.Page"
+                }
+            }
+        },
         "main.jsonResponse.envelope": {
             "type": "object",
             "properties": {
                 "data": {}
             }
         },
+        "pagination.Page": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "next": {
+                    "type": "string"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "prev": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
         "repository.Amenity": {
             "type": "object",
             "properties": {
@@ -2631,6 +5842,82 @@ const docTemplate = `{
                 }
             }
         },
+        "repository.ApiKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "repository.AuditEvent": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "actor_id": {
+                    "type": "integer"
+                },
+                "after": {
+                    "type": "object"
+                },
+                "before": {
+                    "type": "object"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "entity": {
+                    "type": "string"
+                },
+                "entity_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "impersonator_id": {
+                    "type": "integer"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                }
+            }
+        },
         "repository.Booking": {
             "type": "object",
             "properties": {
@@ -2684,6 +5971,26 @@ const docTemplate = `{
                 }
             }
         },
+        "repository.BookingDocument": {
+            "type": "object",
+            "properties": {
+                "booking_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
         "repository.Category": {
             "type": "object",
             "properties": {
@@ -2701,6 +6008,34 @@ const docTemplate = `{
                 }
             }
         },
+        "repository.Consent": {
+            "type": "object",
+            "properties": {
+                "granted": {
+                    "type": "boolean"
+                },
+                "purpose": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "repository.Facet": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
         "repository.Location": {
             "type": "object",
             "properties": {
@@ -2713,11 +6048,31 @@ const docTemplate = `{
                 "id": {
                     "type": "integer"
                 },
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                },
                 "updated_at": {
                     "type": "string"
                 }
             }
         },
+        "repository.Permission": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
         "repository.Role": {
             "type": "object",
             "properties": {
@@ -2736,6 +6091,12 @@ const docTemplate = `{
                 "name": {
                     "type": "string"
                 },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "update_at": {
                     "type": "string"
                 }
@@ -2785,6 +6146,41 @@ const docTemplate = `{
                 }
             }
         },
+        "repository.Session": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "current": {
+                    "type": "boolean"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "impersonator_id": {
+                    "type": "integer"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "last_seen_at": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
         "repository.Type": {
             "type": "object",
             "properties": {
@@ -2817,12 +6213,21 @@ const docTemplate = `{
                 "email": {
                     "type": "string"
                 },
+                "full_name": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
                 "is_active": {
                     "type": "boolean"
                 },
+                "phone": {
+                    "type": "string"
+                },
+                "preferred_language": {
+                    "type": "string"
+                },
                 "role": {
                     "$ref": "#/definitions/repository.Role"
                 },
@@ -2864,6 +6269,10 @@ const docTemplate = `{
                 "description": {
                     "type": "string"
                 },
+                "distance_km": {
+                    "description": "DistanceKm from the point of a near query",
+                    "type": "number"
+                },
                 "id": {
                     "type": "integer"
                 },
@@ -2873,12 +6282,29 @@ const docTemplate = `{
                         "type": "string"
                     }
                 },
+                "image_variants": {
+                    "description": "ImageVariants maps each image to its resized copies, e.g. {\"pool.jpg\": {\"thumb\": \"pool-thumb.jpg\"}}",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "object",
+                        "additionalProperties": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "latitude": {
+                    "description": "Latitude and Longitude of the villa, without them the villa is placed at its location",
+                    "type": "number"
+                },
                 "location": {
                     "$ref": "#/definitions/repository.SelectedLocation"
                 },
                 "location_id": {
                     "type": "integer"
                 },
+                "longitude": {
+                    "type": "number"
+                },
                 "min_guest": {
                     "type": "integer"
                 },
@@ -2888,13 +6314,85 @@ const docTemplate = `{
                 "price": {
                     "type": "number"
                 },
+                "publish_at": {
+                    "type": "string"
+                },
+                "score": {
+                    "type": "number"
+                },
+                "snippet": {
+                    "description": "Snippet and Score of a search, the snippet marks the matched terms",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status of the publishing, a published villa is listed once its publish_at has passed",
+                    "type": "string"
+                },
                 "updated_at": {
                     "type": "string"
                 }
             }
+        },
+        "repository.VillaFacets": {
+            "type": "object",
+            "properties": {
+                "amenities": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/repository.Facet"
+                    }
+                },
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/repository.Facet"
+                    }
+                },
+                "locations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/repository.Facet"
+                    }
+                },
+                "prices": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/repository.Facet"
+                    }
+                }
+            }
+        },
+        "repository.VillaPin": {
+            "type": "object",
+            "properties": {
+                "cover": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                }
+            }
         }
     },
     "securityDefinitions": {
+        "ApiKey": {
+            "description": "Partner key as \"ApiKey gb_\u003cprefix\u003e_\u003csecret\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        },
         "JWT": {
             "type": "apiKey",
             "name": "Authorization",
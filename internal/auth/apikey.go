@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// api key format: gb_<prefix>_<secret>, the prefix is stored in plain text to find the key
+// and the whole key is stored as SHA-256 hash.
+const apiKeyTag = "gb"
+
+var ErrMalformedAPIKey = errors.New("api key is malformed")
+
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	prefixBytes := make([]byte, 4)
+	if _, err := rand.Read(prefixBytes); err != nil {
+		return "", "", "", err
+	}
+
+	secretBytes := make([]byte, 24)
+	if _, err := rand.Read(secretBytes); err != nil {
+		return "", "", "", err
+	}
+
+	prefix = hex.EncodeToString(prefixBytes)
+	key = apiKeyTag + "_" + prefix + "_" + hex.EncodeToString(secretBytes)
+
+	return key, prefix, HashAPIKey(key), nil
+}
+
+func APIKeyPrefix(key string) (string, error) {
+	parts := strings.Split(key, "_")
+	if len(parts) != 3 || parts[0] != apiKeyTag || parts[1] == "" || parts[2] == "" {
+		return "", ErrMalformedAPIKey
+	}
+
+	return parts[1], nil
+}
+
+func HashAPIKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(hash[:])
+}
+
+func CompareAPIKey(key, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashAPIKey(key)), []byte(hash)) == 1
+}
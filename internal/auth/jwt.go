@@ -48,3 +48,8 @@ func (j *jwtAuth) VerifyToken(token string) (*jwt.Token, error) {
 	)
 
 }
+
+// JWKS is empty because a shared secret can not be published.
+func (j *jwtAuth) JWKS() JWKSet {
+	return JWKSet{Keys: []JWK{}}
+}
@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type keyRingAuth struct {
+	active *SigningKey
+	keys   map[string]*SigningKey
+	grace  time.Duration
+	iss    string
+	sub    string
+}
+
+// NewKeyRingAuth signs with the active key and verifies with any key that is
+// active or retired less than grace ago, keys are selected by the "kid" header.
+func NewKeyRingAuth(keys []*SigningKey, grace time.Duration, iss, sub string) (*keyRingAuth, error) {
+	kr := &keyRingAuth{
+		keys:  make(map[string]*SigningKey),
+		grace: grace,
+		iss:   iss,
+		sub:   sub,
+	}
+
+	for _, key := range keys {
+		kr.keys[key.Kid] = key
+
+		if key.RetiredAt.IsZero() {
+			if kr.active != nil {
+				return nil, fmt.Errorf("more than one active key: %s, %s", kr.active.Kid, key.Kid)
+			}
+
+			kr.active = key
+		}
+	}
+
+	if kr.active == nil {
+		return nil, ErrNoActiveKey
+	}
+
+	return kr, nil
+}
+
+func (k *keyRingAuth) GenerateToken(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.active.Method, claims)
+	token.Header["kid"] = k.active.Kid
+
+	return token.SignedString(k.active.Private)
+}
+
+func (k *keyRingAuth) VerifyToken(token string) (*jwt.Token, error) {
+	return jwt.Parse(token, func(t *jwt.Token) (interface{}, error) {
+		kid, ok := t.Header["kid"].(string)
+		if !ok {
+			return nil, ErrMissingKidInToken
+		}
+
+		key, err := k.lookup(kid)
+		if err != nil {
+			return nil, err
+		}
+
+		if t.Method.Alg() != key.Method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
+		}
+
+		return key.Public(), nil
+	},
+		jwt.WithExpirationRequired(),
+		jwt.WithSubject(k.sub),
+		jwt.WithIssuer(k.iss),
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Name, jwt.SigningMethodEdDSA.Alg()}),
+	)
+}
+
+// JWKS publishes the keys that still verify tokens.
+func (k *keyRingAuth) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+
+	kids := slices.Sorted(maps.Keys(k.keys))
+
+	for _, kid := range kids {
+		key, err := k.lookup(kid)
+		if err != nil {
+			continue
+		}
+
+		set.Keys = append(set.Keys, key.JWK())
+	}
+
+	return set
+}
+
+func (k *keyRingAuth) lookup(kid string) (*SigningKey, error) {
+	key, ok := k.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKid
+	}
+
+	if !key.RetiredAt.IsZero() && time.Since(key.RetiredAt) > k.grace {
+		return nil, ErrKeyRetired
+	}
+
+	return key, nil
+}
@@ -0,0 +1,174 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestKeyRingAuth(t *testing.T) {
+	dir := t.TempDir()
+
+	writeKey(t, dir, "active", true)
+	writeKey(t, dir, "grace", false)
+	writeKey(t, dir, "expired", false)
+	writeManifest(t, dir, map[string]time.Time{"grace": time.Now().Add(-time.Hour), "expired": time.Now().Add(-48 * time.Hour)})
+
+	keys, err := LoadKeyDir(dir, "active")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byKid := map[string]*SigningKey{}
+	for _, key := range keys {
+		byKid[key.Kid] = key
+	}
+
+	ring, err := NewKeyRingAuth(keys, 24*time.Hour, "gobali", "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := jwt.RegisteredClaims{Issuer: "gobali", Subject: "users", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))}
+
+	// sign signs the claims with the key of kid as the ring would while it was active
+	sign := func(kid string, header bool) string {
+		key := byKid[kid]
+
+		token := jwt.NewWithClaims(key.Method, claims)
+		if header {
+			token.Header["kid"] = kid
+		}
+
+		signed, err := token.SignedString(key.Private)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return signed
+	}
+
+	generated, err := ring.GenerateToken(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a token of a retired key with the kid of the active one
+	swapped := jwt.NewWithClaims(byKid["grace"].Method, claims)
+	swapped.Header["kid"] = "active"
+	forged, err := swapped.SignedString(byKid["grace"].Private)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{name: "should verify the token of the active key", token: generated},
+		{name: "should verify the token of a key retired within the grace", token: sign("grace", true)},
+		{name: "should fail for the token of a key retired before the grace", token: sign("expired", true), want: ErrKeyRetired},
+		{name: "should fail for an unknown kid", token: func() string {
+			token := jwt.NewWithClaims(byKid["active"].Method, claims)
+			token.Header["kid"] = "unknown"
+			signed, _ := token.SignedString(byKid["active"].Private)
+			return signed
+		}(), want: ErrUnknownKid},
+		{name: "should fail without kid", token: sign("active", false), want: ErrMissingKidInToken},
+		{name: "should fail for the kid of another key", token: forged, want: jwt.ErrTokenUnverifiable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ring.VerifyToken(tt.token)
+			if tt.want == nil && err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Fatalf("expected: %v but got: %v", tt.want, err)
+			}
+		})
+	}
+
+	t.Run("should sign with the kid of the active key", func(t *testing.T) {
+		token, _, err := jwt.NewParser().ParseUnverified(generated, jwt.MapClaims{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if token.Header["kid"] != "active" || token.Method.Alg() != jwt.SigningMethodRS256.Alg() {
+			t.Fatalf("expected RS256 of the active key but got: %v %v", token.Method.Alg(), token.Header["kid"])
+		}
+	})
+
+	t.Run("should publish the keys that still verify", func(t *testing.T) {
+		set := ring.JWKS()
+
+		if len(set.Keys) != 2 {
+			t.Fatalf("expected 2 keys but got: %v", len(set.Keys))
+		}
+
+		tests := []struct {
+			jwk  JWK
+			kid  string
+			kty  string
+			alg  string
+			part string
+		}{
+			{jwk: set.Keys[0], kid: "active", kty: "RSA", alg: "RS256", part: set.Keys[0].N},
+			{jwk: set.Keys[1], kid: "grace", kty: "OKP", alg: "EdDSA", part: set.Keys[1].X},
+		}
+
+		for _, tt := range tests {
+			if tt.jwk.Kid != tt.kid || tt.jwk.Kty != tt.kty || tt.jwk.Alg != tt.alg || tt.jwk.Use != "sig" || tt.part == "" {
+				t.Errorf("expected the %v %v key of %v but got: %+v", tt.kty, tt.alg, tt.kid, tt.jwk)
+			}
+		}
+	})
+}
+
+func TestNewKeyRingAuth(t *testing.T) {
+	dir := t.TempDir()
+
+	writeKey(t, dir, "a", false)
+	writeKey(t, dir, "b", false)
+	writeManifest(t, dir, map[string]time.Time{"b": time.Now()})
+
+	keys, err := LoadKeyDir(dir, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		retired func(keys []*SigningKey)
+		wantErr bool
+	}{
+		{name: "should have a single active key", retired: func(keys []*SigningKey) {}},
+		{name: "should fail without active key", retired: func(keys []*SigningKey) {
+			for _, key := range keys {
+				key.RetiredAt = time.Now()
+			}
+		}, wantErr: true},
+		{name: "should fail for more than one active key", retired: func(keys []*SigningKey) {
+			for _, key := range keys {
+				key.RetiredAt = time.Time{}
+			}
+		}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.retired(keys)
+
+			_, err := NewKeyRingAuth(keys, time.Hour, "gobali", "users")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected an error %v but got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrNoActiveKey       = errors.New("active signing key not found")
+	ErrUnsupportedKey    = errors.New("unsupported private key type")
+	ErrUnknownKid        = errors.New("unknown key id")
+	ErrKeyRetired        = errors.New("signing key is retired")
+	ErrInvalidPEM        = errors.New("invalid PEM encoded key")
+	ErrMissingKidInToken = errors.New("token has no key id")
+	ErrNoRetiredAt       = errors.New("retired key has no retirement time")
+)
+
+// SigningKey is a private key identified by kid, an active key signs new token
+// and a retired key only verify token until the grace period is over.
+type SigningKey struct {
+	Kid       string
+	Method    jwt.SigningMethod
+	Private   crypto.Signer
+	RetiredAt time.Time
+}
+
+func (k *SigningKey) Public() crypto.PublicKey {
+	return k.Private.Public()
+}
+
+// LoadSigningKey reads a PKCS#8 (RSA or Ed25519) or PKCS#1 (RSA) private key from PEM file.
+func LoadSigningKey(kid, path string) (*SigningKey, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, ErrInvalidPEM
+	}
+
+	var parsed any
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedKey, block.Type)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	key := &SigningKey{Kid: kid}
+
+	switch pk := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Method = jwt.SigningMethodRS256
+		key.Private = pk
+	case ed25519.PrivateKey:
+		key.Method = jwt.SigningMethodEdDSA
+		key.Private = pk
+	default:
+		return nil, ErrUnsupportedKey
+	}
+
+	return key, nil
+}
+
+// RetiredManifest is the file of a key dir recording when each key was retired,
+// e.g. {"2025-01": "2025-06-01T00:00:00Z"}.
+const RetiredManifest = "retired.json"
+
+// LoadKeyDir loads every "<kid>.pem" file inside dir. The key named activeKid signs
+// new tokens, the others are retired since the time recorded in the manifest.
+// A retired key left out of it fails the loading, the time of its file changes with every copy or checkout.
+func LoadKeyDir(dir, activeKid string) ([]*SigningKey, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, activeKid+".pem")); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrNoActiveKey, activeKid)
+	}
+
+	retired := map[string]time.Time{}
+
+	raw, err := os.ReadFile(filepath.Join(dir, RetiredManifest))
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(raw, &retired); err != nil {
+			return nil, fmt.Errorf("read %s: %w", RetiredManifest, err)
+		}
+	case !errors.Is(err, fs.ErrNotExist):
+		return nil, err
+	}
+
+	keys := []*SigningKey{}
+
+	for _, file := range files {
+		kid := strings.TrimSuffix(filepath.Base(file), ".pem")
+
+		key, err := LoadSigningKey(kid, file)
+		if err != nil {
+			return nil, fmt.Errorf("load key %s: %w", kid, err)
+		}
+
+		if kid != activeKid {
+			retiredAt, ok := retired[kid]
+			if !ok || retiredAt.IsZero() {
+				return nil, fmt.Errorf("%w: %s, record it in %s", ErrNoRetiredAt, kid, RetiredManifest)
+			}
+
+			key.RetiredAt = retiredAt
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// JWK is a public key in the JSON Web Key format (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+func (k *SigningKey) JWK() JWK {
+	jwk := JWK{
+		Kid: k.Kid,
+		Use: "sig",
+		Alg: k.Method.Alg(),
+	}
+
+	switch pub := k.Public().(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+	}
+
+	return jwk
+}
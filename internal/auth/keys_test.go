@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeKey stores a new PKCS#8 key as "<kid>.pem" in dir.
+func writeKey(t *testing.T, dir, kid string, rsaKey bool) {
+	t.Helper()
+
+	var private any
+
+	if rsaKey {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		private = key
+	} else {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		private = key
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, kid+".pem"), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeManifest records when each key of dir was retired.
+func writeManifest(t *testing.T, dir string, retired map[string]time.Time) {
+	t.Helper()
+
+	raw, err := json.Marshal(retired)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, RetiredManifest), raw, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadKeyDir(t *testing.T) {
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	retired := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		active   string
+		manifest string
+		want     map[string]time.Time
+		err      string
+	}{
+		{
+			name:     "should retire the other keys since the time of the manifest",
+			active:   "new",
+			manifest: `{"old": "2025-06-01T00:00:00Z", "older": "2025-01-01T00:00:00Z"}`,
+			want:     map[string]time.Time{"new": {}, "old": retired, "older": created},
+		},
+		{
+			name:     "should not retire the active key",
+			active:   "old",
+			manifest: `{"new": "2025-06-01T00:00:00Z", "older": "2025-01-01T00:00:00Z"}`,
+			want:     map[string]time.Time{"new": retired, "old": {}, "older": created},
+		},
+		{
+			name:     "should fail for a retired key missing from the manifest",
+			active:   "new",
+			manifest: `{"old": "2025-06-01T00:00:00Z"}`,
+			err:      ErrNoRetiredAt.Error() + ": older",
+		},
+		{
+			name:   "should fail for retired keys without a manifest",
+			active: "new",
+			err:    ErrNoRetiredAt.Error(),
+		},
+		{
+			name:     "should fail for an invalid manifest",
+			active:   "new",
+			manifest: `["old"]`,
+			err:      "read retired.json",
+		},
+		{
+			name:   "should fail without the active key",
+			active: "missing",
+			err:    ErrNoActiveKey.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			writeKey(t, dir, "older", true)
+			writeKey(t, dir, "old", false)
+			writeKey(t, dir, "new", false)
+
+			if tt.manifest != "" {
+				if err := os.WriteFile(filepath.Join(dir, RetiredManifest), []byte(tt.manifest), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			keys, err := LoadKeyDir(dir, tt.active)
+			if tt.err != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.err) {
+					t.Fatalf("expected: %v but got: %v", tt.err, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(keys) != len(tt.want) {
+				t.Fatalf("expected %v keys but got: %v", len(tt.want), len(keys))
+			}
+
+			for _, key := range keys {
+				if want := tt.want[key.Kid]; !key.RetiredAt.Equal(want) {
+					t.Errorf("expected %v retired at %v but got: %v", key.Kid, want, key.RetiredAt)
+				}
+			}
+		})
+	}
+}
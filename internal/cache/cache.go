@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"expvar"
+	"sync"
+	"time"
+)
+
+// stats counts hits and misses of every cache as "<name>_hits" and "<name>_misses".
+var stats = expvar.NewMap("cache")
+
+type entry[V any] struct {
+	value  V
+	expire time.Time
+}
+
+// TTL is an in-memory cache where entries expire after a fixed duration.
+type TTL[K comparable, V any] struct {
+	name    string
+	ttl     time.Duration
+	mu      sync.RWMutex
+	entries map[K]entry[V]
+}
+
+func New[K comparable, V any](name string, ttl time.Duration) *TTL[K, V] {
+	return &TTL[K, V]{
+		name:    name,
+		ttl:     ttl,
+		entries: make(map[K]entry[V]),
+	}
+}
+
+func (c *TTL[K, V]) Get(key K) (V, bool) {
+	c.mu.RLock()
+	e, ok := c.entries[key]
+	c.mu.RUnlock()
+
+	if !ok || time.Now().After(e.expire) {
+		stats.Add(c.name+"_misses", 1)
+
+		var zero V
+		return zero, false
+	}
+
+	stats.Add(c.name+"_hits", 1)
+
+	return e.value, true
+}
+
+func (c *TTL[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+
+	// drop expired entries once the cache grows, keys are never reused for most lookups
+	if len(c.entries) >= 1024 {
+		for k, e := range c.entries {
+			if now.After(e.expire) {
+				delete(c.entries, k)
+			}
+		}
+	}
+
+	c.entries[key] = entry[V]{value: value, expire: now.Add(c.ttl)}
+}
+
+func (c *TTL[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, key)
+}
+
+func (c *TTL[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clear(c.entries)
+}
@@ -0,0 +1,42 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTTL(t *testing.T) {
+	t.Run("should return the value until it expires", func(t *testing.T) {
+		c := New[int, string]("test_expire", 20*time.Millisecond)
+
+		c.Set(1, "admin")
+
+		if got, ok := c.Get(1); !ok || got != "admin" {
+			t.Errorf("expected: admin but got: %v", got)
+		}
+
+		time.Sleep(30 * time.Millisecond)
+
+		if _, ok := c.Get(1); ok {
+			t.Error("expected the entry to be expired")
+		}
+	})
+
+	t.Run("should count hits and misses", func(t *testing.T) {
+		c := New[int, string]("test_stats", time.Minute)
+
+		c.Get(1)
+		c.Set(1, "admin")
+		c.Get(1)
+		c.Delete(1)
+		c.Get(1)
+
+		if hits := stats.Get("test_stats_hits").String(); hits != "1" {
+			t.Errorf("expected: 1 hit but got: %v", hits)
+		}
+
+		if misses := stats.Get("test_stats_misses").String(); misses != "2" {
+			t.Errorf("expected: 2 misses but got: %v", misses)
+		}
+	})
+}
@@ -0,0 +1,440 @@
+// Package listquery reads the query string of a list endpoint: the page, the sort and the filters.
+// Only the sort fields and the filters a list declares are read, and their columns are the only
+// names that reach the SQL; the values always go as arguments.
+package listquery
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/faizisyellow/gobali/internal/pagination"
+)
+
+var ErrInvalidQuery = errors.New("invalid list query")
+
+const (
+	// MaxSorts a query can sort by at once
+	MaxSorts = 3
+	// MaxValues of a multi-value filter
+	MaxValues = 20
+	// maxLength of a text value
+	maxLength = 200
+)
+
+// Type a filter value is converted to.
+type Type int
+
+const (
+	String Type = iota
+	Int
+	Float
+	Bool
+	// Date is a "2006-01-02" day, kept as a string
+	Date
+)
+
+// Op compares the column of a filter with its values.
+type Op int
+
+const (
+	Eq Op = iota
+	// Like matches a part of the column
+	Like
+	// AnyLike matches a part of the column with any of the comma separated values
+	AnyLike
+	// In matches any of the comma separated values
+	In
+	Gte
+	Lte
+	// UntilDay keeps the column before the end of the day
+	UntilDay
+)
+
+type Filter struct {
+	Column string
+	Type   Type
+	Op     Op
+	// Min and Max bound the numbers of the filter when set
+	Min, Max *float64
+}
+
+// Spec declares what a list can be paged, sorted and filtered by.
+type Spec struct {
+	DefaultLimit int
+	MaxLimit     int
+	// Sorts maps the sort fields of the API to their column. An empty column is a sort
+	// the repository orders itself, like a relevance or a distance.
+	Sorts map[string]string
+	// DefaultSort of the list e.g. "created_at" or "-created_at" for descending
+	DefaultSort string
+	// TieBreaker is a unique column ending every order so pages do not overlap e.g. "id"
+	TieBreaker string
+	Filters    map[string]Filter
+}
+
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+type Cond struct {
+	Name   string
+	Filter Filter
+	Values []any
+}
+
+// Query is a list query read by a spec.
+type Query struct {
+	Limit  int
+	Offset int
+	Sorts  []Sort
+	Conds  []Cond
+	Cursor *pagination.Cursor
+	spec   *Spec
+}
+
+// Parse reads the query string. The sort is "sort=-price,name", a leading minus sorts descending;
+// "sort=asc" or "sort=desc" only sets the direction of "sort_by" or of the default sort.
+func (s *Spec) Parse(values url.Values) (Query, error) {
+	q := Query{Limit: s.DefaultLimit, spec: s}
+
+	if limit := values.Get("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil || l < 1 || l > s.MaxLimit {
+			return q, invalid("limit must be between 1 and %d", s.MaxLimit)
+		}
+
+		q.Limit = l
+	}
+
+	if offset := values.Get("offset"); offset != "" {
+		of, err := strconv.Atoi(offset)
+		if err != nil || of < 0 {
+			return q, invalid("offset must be a positive number")
+		}
+
+		q.Offset = of
+	}
+
+	if cursor := values.Get("cursor"); cursor != "" {
+		c, err := pagination.DecodeCursor(cursor)
+		if err != nil {
+			return q, fmt.Errorf("%w: %w", ErrInvalidQuery, err)
+		}
+
+		q.Cursor = c
+		q.Offset = c.Offset
+	}
+
+	sorts, err := s.parseSorts(values.Get("sort"), values.Get("sort_by"))
+	if err != nil {
+		return q, err
+	}
+
+	q.Sorts = sorts
+
+	names := make([]string, 0, len(s.Filters))
+	for name := range s.Filters {
+		names = append(names, name)
+	}
+
+	// the conditions keep one order so the same query builds the same SQL
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := values.Get(name)
+		if value == "" {
+			continue
+		}
+
+		filter := s.Filters[name]
+
+		parts := []string{value}
+		if filter.Op == In || filter.Op == AnyLike {
+			parts = splitList(value)
+			if len(parts) > MaxValues {
+				return q, invalid("%s takes at most %d values", name, MaxValues)
+			}
+		}
+
+		cond := Cond{Name: name, Filter: filter}
+		for _, part := range parts {
+			v, err := convert(name, part, filter)
+			if err != nil {
+				return q, err
+			}
+
+			cond.Values = append(cond.Values, v)
+		}
+
+		if len(cond.Values) > 0 {
+			q.Conds = append(q.Conds, cond)
+		}
+	}
+
+	return q, nil
+}
+
+func (s *Spec) parseSorts(value, sortBy string) ([]Sort, error) {
+	defaultSort := parseSort(s.DefaultSort)
+
+	switch {
+	case value == "asc" || value == "desc" || (value == "" && sortBy != ""):
+		field := defaultSort.Field
+		if sortBy != "" {
+			field = sortBy
+		}
+
+		if _, ok := s.Sorts[field]; !ok {
+			return nil, invalid("can not sort by %s", field)
+		}
+
+		desc := value == "desc" || (value == "" && defaultSort.Desc)
+
+		return []Sort{{Field: field, Desc: desc}}, nil
+	case value == "":
+		return []Sort{defaultSort}, nil
+	}
+
+	sorts := []Sort{}
+
+	for _, field := range splitList(value) {
+		sort := parseSort(field)
+
+		if _, ok := s.Sorts[sort.Field]; !ok {
+			return nil, invalid("can not sort by %s", sort.Field)
+		}
+
+		if slices.ContainsFunc(sorts, func(s Sort) bool { return s.Field == sort.Field }) {
+			return nil, invalid("%s is sorted by twice", sort.Field)
+		}
+
+		sorts = append(sorts, sort)
+	}
+
+	if len(sorts) > MaxSorts {
+		return nil, invalid("can not sort by more than %d fields", MaxSorts)
+	}
+
+	return sorts, nil
+}
+
+func parseSort(field string) Sort {
+	if name, ok := strings.CutPrefix(field, "-"); ok {
+		return Sort{Field: name, Desc: true}
+	}
+
+	return Sort{Field: field}
+}
+
+func convert(name, value string, filter Filter) (any, error) {
+	switch filter.Type {
+	case Int, Float:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil || (filter.Type == Int && n != float64(int(n))) {
+			return nil, invalid("%s must be a number", name)
+		}
+
+		if (filter.Min != nil && n < *filter.Min) || (filter.Max != nil && n > *filter.Max) {
+			return nil, invalid("%s is out of range", name)
+		}
+
+		if filter.Type == Int {
+			return int(n), nil
+		}
+
+		return n, nil
+	case Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, invalid("%s must be true or false", name)
+		}
+
+		return b, nil
+	case Date:
+		if _, err := time.Parse(time.DateOnly, value); err != nil {
+			return nil, invalid("%s must be a date like 2006-01-02", name)
+		}
+
+		return value, nil
+	}
+
+	if len(value) > maxLength {
+		return nil, invalid("%s is longer than %d characters", name, maxLength)
+	}
+
+	return value, nil
+}
+
+// Has reports whether the filter is set.
+func (q Query) Has(name string) bool {
+	return slices.ContainsFunc(q.Conds, func(c Cond) bool { return c.Name == name })
+}
+
+// Values of the filter, empty when it is not set.
+func Values[T any](q Query, name string) []T {
+	values := []T{}
+
+	for _, cond := range q.Conds {
+		if cond.Name != name {
+			continue
+		}
+
+		for _, value := range cond.Values {
+			values = append(values, value.(T))
+		}
+	}
+
+	return values
+}
+
+// Value of a single value filter.
+func Value[T any](q Query, name string) (T, bool) {
+	values := Values[T](q, name)
+	if len(values) == 0 {
+		var zero T
+		return zero, false
+	}
+
+	return values[0], true
+}
+
+// Where joins the conditions of the filters, the filters named in skip are left out.
+func (q Query) Where(skip ...string) (string, []any) {
+	conds := []string{"1 = 1"}
+	args := []any{}
+
+	for _, cond := range q.Conds {
+		if slices.Contains(skip, cond.Name) {
+			continue
+		}
+
+		column := cond.Filter.Column
+
+		switch cond.Filter.Op {
+		case Like:
+			conds = append(conds, column+` LIKE concat("%",?,"%")`)
+		case AnyLike:
+			likes := make([]string, len(cond.Values))
+			for i := range likes {
+				likes[i] = column + ` LIKE concat("%",?,"%")`
+			}
+
+			conds = append(conds, "("+strings.Join(likes, " OR ")+")")
+		case In:
+			conds = append(conds, column+" IN ("+Placeholders(len(cond.Values))+")")
+		case Gte:
+			conds = append(conds, column+" >= ?")
+		case Lte:
+			conds = append(conds, column+" <= ?")
+		case UntilDay:
+			conds = append(conds, column+" < DATE_ADD(?, INTERVAL 1 DAY)")
+		default:
+			conds = append(conds, column+" = ?")
+		}
+
+		args = append(args, cond.Values...)
+	}
+
+	return strings.Join(conds, " AND "), args
+}
+
+// SortField is the field the list is sorted by first.
+func (q Query) SortField() string {
+	if len(q.Sorts) == 0 {
+		return ""
+	}
+
+	return q.Sorts[0].Field
+}
+
+// OrderBy lists the columns of the sorts and the tie breaker, reversed every direction is turned.
+// The sorts without a column are left to the repository.
+func (q Query) OrderBy(reverse bool) string {
+	order := []string{}
+	desc := false
+
+	for _, sort := range q.Sorts {
+		column := q.spec.Sorts[sort.Field]
+		if column == "" {
+			continue
+		}
+
+		desc = sort.Desc
+		order = append(order, column+" "+direction(sort.Desc != reverse))
+	}
+
+	if q.spec.TieBreaker != "" {
+		order = append(order, q.spec.TieBreaker+" "+direction(desc != reverse))
+	}
+
+	return strings.Join(order, ", ")
+}
+
+// Keyset keeps the rows after the row of the cursor, or before it, in the order of the sort.
+// Only a keyset cursor on a list sorted by a single column pages this way.
+func (q Query) Keyset() (string, []any, bool) {
+	if !q.Cursor.Keyset() || len(q.Sorts) != 1 || q.spec.TieBreaker == "" {
+		return "", nil, false
+	}
+
+	column := q.spec.Sorts[q.Sorts[0].Field]
+	if column == "" {
+		return "", nil, false
+	}
+
+	op := ">"
+	if q.Sorts[0].Desc != q.Cursor.Before {
+		op = "<"
+	}
+
+	tie := q.spec.TieBreaker
+
+	return "(" + column + " " + op + " ? OR (" + column + " = ? AND " + tie + " " + op + " ?))",
+		[]any{q.Cursor.Value, q.Cursor.Value, q.Cursor.Id}, true
+}
+
+// Page of the list by the offset.
+func (q Query) Page(total int) pagination.Page {
+	return pagination.New(q.Limit, q.Offset, total)
+}
+
+func direction(desc bool) string {
+	if desc {
+		return "desc"
+	}
+
+	return "asc"
+}
+
+// Placeholders of n arguments e.g. "?,?,?".
+func Placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+// Ptr of a bound of a filter.
+func Ptr(n float64) *float64 {
+	return &n
+}
+
+func splitList(value string) []string {
+	values := []string{}
+
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+
+	return values
+}
+
+func invalid(format string, args ...any) error {
+	return fmt.Errorf("%w: "+format, append([]any{ErrInvalidQuery}, args...)...)
+}
@@ -0,0 +1,157 @@
+package listquery
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/faizisyellow/gobali/internal/pagination"
+)
+
+var spec = Spec{
+	DefaultLimit: 10,
+	MaxLimit:     50,
+	Sorts:        map[string]string{"created_at": "b.created_at", "price": "b.total_price", "relevance": ""},
+	DefaultSort:  "created_at",
+	TieBreaker:   "b.id",
+	Filters: map[string]Filter{
+		"status":    {Column: "b.status", Op: In},
+		"guest":     {Column: "b.guest", Type: Int, Min: Ptr(1)},
+		"price_min": {Column: "b.total_price", Type: Float, Op: Gte},
+		"area":      {Column: "l.area", Op: AnyLike},
+		"from":      {Column: "b.created_at", Type: Date, Op: Gte},
+		"to":        {Column: "b.created_at", Type: Date, Op: UntilDay},
+		"active":    {Column: "u.is_active", Type: Bool},
+	},
+}
+
+func parse(t *testing.T, query string) (Query, error) {
+	t.Helper()
+
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return spec.Parse(values)
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, query := range []string{
+		"limit=0",
+		"limit=51",
+		"limit=ten",
+		"offset=-1",
+		"cursor=nope!",
+		"sort=name",
+		"sort=created_at%3BDROP%20TABLE%20users",
+		"sort=price,-price",
+		"sort_by=name",
+		"guest=0",
+		"guest=1.5",
+		"price_min=cheap",
+		"from=yesterday",
+		"active=maybe",
+		"sort=price,created_at,relevance,-price",
+	} {
+		if _, err := parse(t, query); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("%s: got %v, want ErrInvalidQuery", query, err)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	q, err := parse(t, "limit=20&offset=40&status=paid,pending&guest=2&area=Ubud,Canggu&to=2025-01-31&unknown=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if q.Limit != 20 || q.Offset != 40 {
+		t.Errorf("limit %d offset %d, want 20 and 40", q.Limit, q.Offset)
+	}
+
+	where, args := q.Where()
+
+	wantWhere := `1 = 1 AND (l.area LIKE concat("%",?,"%") OR l.area LIKE concat("%",?,"%")) AND b.guest = ? AND b.status IN (?,?) AND b.created_at < DATE_ADD(?, INTERVAL 1 DAY)`
+	if where != wantWhere {
+		t.Errorf("where\n got %s\nwant %s", where, wantWhere)
+	}
+
+	wantArgs := []any{"Ubud", "Canggu", 2, "paid", "pending", "2025-01-31"}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args got %v, want %v", args, wantArgs)
+	}
+
+	if where, _ := q.Where("status", "area"); where != "1 = 1 AND b.guest = ? AND b.created_at < DATE_ADD(?, INTERVAL 1 DAY)" {
+		t.Errorf("skipped filters are in %s", where)
+	}
+
+	if guest, ok := Value[int](q, "guest"); !ok || guest != 2 {
+		t.Errorf("guest = %d, %v", guest, ok)
+	}
+
+	if status := Values[string](q, "status"); !reflect.DeepEqual(status, []string{"paid", "pending"}) {
+		t.Errorf("status = %v", status)
+	}
+}
+
+func TestOrderBy(t *testing.T) {
+	tests := []struct {
+		query   string
+		reverse bool
+		want    string
+	}{
+		{"", false, "b.created_at asc, b.id asc"},
+		{"sort=desc", false, "b.created_at desc, b.id desc"},
+		{"sort=desc&sort_by=price", false, "b.total_price desc, b.id desc"},
+		{"sort=-price,created_at", false, "b.total_price desc, b.created_at asc, b.id asc"},
+		{"sort=-price", true, "b.total_price asc, b.id asc"},
+		{"sort=relevance,-price", false, "b.total_price desc, b.id desc"},
+	}
+
+	for _, test := range tests {
+		q, err := parse(t, test.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := q.OrderBy(test.reverse); got != test.want {
+			t.Errorf("%q: got %s, want %s", test.query, got, test.want)
+		}
+	}
+}
+
+func TestKeyset(t *testing.T) {
+	next := pagination.Cursor{Value: "120", Id: 7}.Encode()
+	prev := pagination.Cursor{Value: "120", Id: 7, Before: true}.Encode()
+
+	tests := []struct {
+		query string
+		want  string
+		ok    bool
+	}{
+		{"sort=price&cursor=" + next, "(b.total_price > ? OR (b.total_price = ? AND b.id > ?))", true},
+		{"sort=-price&cursor=" + next, "(b.total_price < ? OR (b.total_price = ? AND b.id < ?))", true},
+		{"sort=-price&cursor=" + prev, "(b.total_price > ? OR (b.total_price = ? AND b.id > ?))", true},
+		{"sort=price,created_at&cursor=" + next, "", false},
+		{"sort=relevance&cursor=" + next, "", false},
+		{"sort=price&cursor=" + pagination.Cursor{Offset: 10}.Encode(), "", false},
+	}
+
+	for _, test := range tests {
+		q, err := parse(t, test.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, args, ok := q.Keyset()
+		if got != test.want || ok != test.ok {
+			t.Errorf("%q: got %s %v, want %s %v", test.query, got, ok, test.want, test.ok)
+		}
+
+		if ok && !reflect.DeepEqual(args, []any{"120", "120", 7}) {
+			t.Errorf("%q: args %v", test.query, args)
+		}
+	}
+}
@@ -6,6 +6,7 @@ const (
 	FromName            = "Welcome to Gobali Where You Can Rent A Good Villa !"
 	maxRetries          = 3
 	UserWelcomeTemplate = "user_invitation.tmpl"
+	EmailChangeTemplate = "email_change.tmpl"
 )
 
 //go:embed "templates"
@@ -0,0 +1,327 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrUnknownProvider   = errors.New("unknown identity provider")
+	ErrInvalidState      = errors.New("login state is invalid or expired")
+	ErrNonceMismatch     = errors.New("id token nonce does not match")
+	ErrNoIDToken         = errors.New("token response has no id_token")
+	ErrUnknownSigningKey = errors.New("id token signed by unknown key")
+)
+
+// Config of a relying party registered at an OpenID provider.
+type Config struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// Identity is the subset of the id token claims used to link an account.
+type Identity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+type Provider struct {
+	config Config
+	client *http.Client
+
+	mu        sync.Mutex
+	discovery *discovery
+	keys      map[string]crypto.PublicKey
+}
+
+func NewProvider(config Config, client *http.Client) *Provider {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid", "email", "profile"}
+	}
+
+	return &Provider{config: config, client: client}
+}
+
+func (p *Provider) Name() string {
+	return p.config.Name
+}
+
+// AuthCodeURL builds the authorization code request with a S256 PKCE challenge.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, challenge string) (string, error) {
+	d, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	q := url.Values{}
+	q.Set("response_type", "code")
+	q.Set("client_id", p.config.ClientID)
+	q.Set("redirect_uri", p.config.RedirectURL)
+	q.Set("scope", strings.Join(p.config.Scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", challenge)
+	q.Set("code_challenge_method", "S256")
+
+	return d.AuthorizationEndpoint + "?" + q.Encode(), nil
+}
+
+// Exchange trades the code for tokens and returns the verified identity of the id token.
+func (p *Provider) Exchange(ctx context.Context, code, verifier, nonce string) (*Identity, error) {
+	d, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.config.RedirectURL)
+	form.Set("client_id", p.config.ClientID)
+	form.Set("client_secret", p.config.ClientSecret)
+	form.Set("code_verifier", verifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token endpoint responded %d", res.StatusCode)
+	}
+
+	var tokens struct {
+		IDToken string `json:"id_token"`
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(&tokens); err != nil {
+		return nil, err
+	}
+
+	if tokens.IDToken == "" {
+		return nil, ErrNoIDToken
+	}
+
+	return p.verifyIDToken(ctx, tokens.IDToken, nonce)
+}
+
+func (p *Provider) verifyIDToken(ctx context.Context, idToken, nonce string) (*Identity, error) {
+	d, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := jwt.MapClaims{}
+
+	_, err = jwt.ParseWithClaims(idToken, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+
+		return p.publicKey(ctx, kid)
+	},
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuer(d.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithValidMethods([]string{"RS256", "EdDSA"}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if claimNonce, _ := claims["nonce"].(string); claimNonce != nonce {
+		return nil, ErrNonceMismatch
+	}
+
+	identity := &Identity{Provider: p.config.Name}
+	identity.Subject, _ = claims["sub"].(string)
+	identity.Email, _ = claims["email"].(string)
+	identity.Name, _ = claims["name"].(string)
+
+	// some providers send email_verified as string
+	switch verified := claims["email_verified"].(type) {
+	case bool:
+		identity.EmailVerified = verified
+	case string:
+		identity.EmailVerified = verified == "true"
+	}
+
+	if identity.Subject == "" {
+		return nil, errors.New("id token has no subject")
+	}
+
+	return identity, nil
+}
+
+func (p *Provider) discover(ctx context.Context) (*discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	d := &discovery{}
+	if err := p.getJSON(ctx, strings.TrimSuffix(p.config.Issuer, "/")+"/.well-known/openid-configuration", d); err != nil {
+		return nil, err
+	}
+
+	if d.Issuer != p.config.Issuer {
+		return nil, fmt.Errorf("issuer mismatch: expected %s got %s", p.config.Issuer, d.Issuer)
+	}
+
+	p.discovery = d
+
+	return d, nil
+}
+
+// publicKey refetches the key set once when the kid is unknown, the provider may have rotated keys.
+func (p *Provider) publicKey(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	key, ok := p.keys[kid]
+	p.mu.Unlock()
+
+	if ok {
+		return key, nil
+	}
+
+	d, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+		} `json:"keys"`
+	}
+
+	if err := p.getJSON(ctx, d.JwksURI, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]crypto.PublicKey)
+
+	for _, jwk := range set.Keys {
+		switch {
+		case jwk.Kty == "RSA":
+			n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+			if err != nil {
+				return nil, err
+			}
+
+			e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+			if err != nil {
+				return nil, err
+			}
+
+			keys[jwk.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		case jwk.Kty == "OKP" && jwk.Crv == "Ed25519":
+			x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+			if err != nil {
+				return nil, err
+			}
+
+			keys[jwk.Kid] = ed25519.PublicKey(x)
+		}
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.mu.Unlock()
+
+	key, ok = keys[kid]
+	if !ok {
+		return nil, ErrUnknownSigningKey
+	}
+
+	return key, nil
+}
+
+func (p *Provider) getJSON(ctx context.Context, url string, data any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s responded %d", url, res.StatusCode)
+	}
+
+	return json.NewDecoder(res.Body).Decode(data)
+}
+
+// NewPKCE returns a code verifier and its S256 challenge (RFC 7636).
+func NewPKCE() (verifier, challenge string, err error) {
+	verifier, err = RandomString(32)
+	if err != nil {
+		return "", "", err
+	}
+
+	hash := sha256.Sum256([]byte(verifier))
+
+	return verifier, base64.RawURLEncoding.EncodeToString(hash[:]), nil
+}
+
+func RandomString(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
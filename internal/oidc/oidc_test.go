@@ -0,0 +1,181 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// fakeProvider is an in-process OpenID provider issuing one authorization code.
+type fakeProvider struct {
+	server    *httptest.Server
+	key       *rsa.PrivateKey
+	code      string
+	challenge string
+	nonce     string
+	email     string
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fp := &fakeProvider{key: key, code: "auth-code", email: "guest@example.com"}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 fp.server.URL,
+			"authorization_endpoint": fp.server.URL + "/authorize",
+			"token_endpoint":         fp.server.URL + "/token",
+			"jwks_uri":               fp.server.URL + "/jwks",
+		})
+	})
+
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "fake",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+
+		hash := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+		if r.Form.Get("code") != fp.code || base64.RawURLEncoding.EncodeToString(hash[:]) != fp.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"iss":            fp.server.URL,
+			"aud":            r.Form.Get("client_id"),
+			"sub":            "google-123",
+			"email":          fp.email,
+			"email_verified": true,
+			"nonce":          fp.nonce,
+			"exp":            time.Now().Add(time.Minute).Unix(),
+		})
+		token.Header["kid"] = "fake"
+
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Error(err)
+		}
+
+		json.NewEncoder(w).Encode(map[string]string{"id_token": signed, "access_token": "x"})
+	})
+
+	fp.server = httptest.NewServer(mux)
+	t.Cleanup(fp.server.Close)
+
+	return fp
+}
+
+// authorize plays the browser and the consent screen, the provider remembers the challenge and nonce.
+func (fp *fakeProvider) authorize(t *testing.T, authURL string) {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fp.challenge = u.Query().Get("code_challenge")
+	fp.nonce = u.Query().Get("nonce")
+
+	if u.Query().Get("code_challenge_method") != "S256" {
+		t.Errorf("expected S256 challenge method but got: %v", u.Query().Get("code_challenge_method"))
+	}
+}
+
+func TestProviderExchange(t *testing.T) {
+	fp := newFakeProvider(t)
+
+	provider := NewProvider(Config{
+		Name:        "google",
+		Issuer:      fp.server.URL,
+		ClientID:    "client-id",
+		RedirectURL: "http://localhost:5173/oauth/callback",
+	}, fp.server.Client())
+
+	ctx := context.Background()
+
+	t.Run("should return the identity of the id token", func(t *testing.T) {
+		verifier, challenge, err := NewPKCE()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		authURL, err := provider.AuthCodeURL(ctx, "state", "nonce-1", challenge)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fp.authorize(t, authURL)
+
+		identity, err := provider.Exchange(ctx, fp.code, verifier, "nonce-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if identity.Email != fp.email || !identity.EmailVerified || identity.Subject != "google-123" {
+			t.Errorf("unexpected identity: %+v", identity)
+		}
+	})
+
+	t.Run("should fail if the code verifier does not match", func(t *testing.T) {
+		_, challenge, _ := NewPKCE()
+
+		authURL, _ := provider.AuthCodeURL(ctx, "state", "nonce-2", challenge)
+		fp.authorize(t, authURL)
+
+		otherVerifier, _, _ := NewPKCE()
+
+		if _, err := provider.Exchange(ctx, fp.code, otherVerifier, "nonce-2"); err == nil {
+			t.Error("expected error but got nil")
+		}
+	})
+
+	t.Run("should fail if the nonce does not match", func(t *testing.T) {
+		verifier, challenge, _ := NewPKCE()
+
+		authURL, _ := provider.AuthCodeURL(ctx, "state", "nonce-3", challenge)
+		fp.authorize(t, authURL)
+
+		if _, err := provider.Exchange(ctx, fp.code, verifier, "replayed-nonce"); err != ErrNonceMismatch {
+			t.Errorf("expected: %v but got: %v", ErrNonceMismatch, err)
+		}
+	})
+}
+
+func TestStateStore(t *testing.T) {
+	store := NewStateStore(time.Minute)
+
+	store.Save("state", Pending{Provider: "google", Verifier: "v", Nonce: "n"})
+
+	if _, err := store.Take("state"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Take("state"); err != ErrInvalidState {
+		t.Errorf("expected: %v but got: %v", ErrInvalidState, err)
+	}
+}
@@ -0,0 +1,63 @@
+package oidc
+
+import (
+	"sync"
+	"time"
+)
+
+// Pending is a login started by AuthCodeURL and waiting for the callback.
+type Pending struct {
+	Provider string
+	Verifier string
+	Nonce    string
+	Expire   time.Time
+}
+
+// StateStore keeps pending logins in memory, a state can only be used once.
+type StateStore struct {
+	mu      sync.Mutex
+	pending map[string]Pending
+	ttl     time.Duration
+}
+
+func NewStateStore(ttl time.Duration) *StateStore {
+	return &StateStore{
+		pending: make(map[string]Pending),
+		ttl:     ttl,
+	}
+}
+
+func (s *StateStore) Save(state string, pending Pending) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	// drop abandoned logins
+	for key, p := range s.pending {
+		if now.After(p.Expire) {
+			delete(s.pending, key)
+		}
+	}
+
+	pending.Expire = now.Add(s.ttl)
+	s.pending[state] = pending
+}
+
+func (s *StateStore) Take(state string) (Pending, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending, ok := s.pending[state]
+	if !ok {
+		return Pending{}, ErrInvalidState
+	}
+
+	delete(s.pending, state)
+
+	if time.Now().After(pending.Expire) {
+		return Pending{}, ErrInvalidState
+	}
+
+	return pending, nil
+}
@@ -0,0 +1,128 @@
+// Package pagination describes a page of a list to the client, as meta data of the
+// response and as Link headers, and reads the cursors the client sends back.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidCursor = errors.New("the cursor is invalid")
+
+// Cursor points to the page next to the row of a list. A list sorted by a column
+// pages after the sort value and the id of the row, other lists page by the offset.
+type Cursor struct {
+	Offset int    `json:"o,omitempty"`
+	Value  string `json:"v,omitempty"`
+	Id     int    `json:"i,omitempty"`
+	// Before pages backward from the row
+	Before bool `json:"b,omitempty"`
+}
+
+// Keyset reports whether the cursor points to a row instead of an offset.
+func (c *Cursor) Keyset() bool {
+	return c != nil && c.Id != 0
+}
+
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(value string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	cursor := &Cursor{}
+	if err := json.Unmarshal(data, cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	if cursor.Offset < 0 || cursor.Id < 0 {
+		return nil, ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
+
+// Page of a list, Total counts every row matching the filters.
+type Page struct {
+	Total  int    `json:"total"`
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+	Next   string `json:"next,omitempty"`
+	Prev   string `json:"prev,omitempty"`
+}
+
+// New pages the list by the offset.
+func New(limit, offset, total int) Page {
+	page := Page{Total: total, Limit: limit, Offset: offset}
+
+	if offset+limit < total {
+		page.Next = Cursor{Offset: offset + limit}.Encode()
+	}
+
+	if offset > 0 {
+		page.Prev = Cursor{Offset: max(offset-limit, 0)}.Encode()
+	}
+
+	return page
+}
+
+// Links of the page for the Link header, the URL of the request keeps its filters.
+func (p Page) Links(u *url.URL) string {
+	link := func(rel string, set func(url.Values)) string {
+		query := u.Query()
+		query.Del("cursor")
+		query.Del("offset")
+
+		set(query)
+
+		next := *u
+		next.RawQuery = query.Encode()
+
+		return fmt.Sprintf(`<%s>; rel="%s"`, next.String(), rel)
+	}
+
+	links := []string{}
+
+	if p.Next != "" {
+		links = append(links, link("next", func(q url.Values) { q.Set("cursor", p.Next) }))
+	}
+
+	if p.Prev != "" {
+		links = append(links, link("prev", func(q url.Values) { q.Set("cursor", p.Prev) }))
+	}
+
+	links = append(links, link("first", func(url.Values) {}))
+
+	if p.Limit > 0 && p.Total > 0 {
+		last := (p.Total - 1) / p.Limit * p.Limit
+		links = append(links, link("last", func(q url.Values) { q.Set("offset", strconv.Itoa(last)) }))
+	}
+
+	return strings.Join(links, ", ")
+}
+
+// SetHeaders adds the Link and X-Total-Count headers of the page to the response.
+func SetHeaders(w http.ResponseWriter, r *http.Request, p Page) {
+	u := *r.URL
+	if u.Host == "" {
+		u.Host = r.Host
+		u.Scheme = "http"
+		if r.TLS != nil {
+			u.Scheme = "https"
+		}
+	}
+
+	w.Header().Set("Link", p.Links(&u))
+	w.Header().Set("X-Total-Count", strconv.Itoa(p.Total))
+}
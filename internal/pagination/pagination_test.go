@@ -0,0 +1,92 @@
+package pagination
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestCursor(t *testing.T) {
+	cursor := Cursor{Value: "2025-01-02 10:00:00", Id: 42, Before: true}
+
+	decoded, err := DecodeCursor(cursor.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *decoded != cursor {
+		t.Errorf("got %+v, want %+v", *decoded, cursor)
+	}
+
+	if !decoded.Keyset() {
+		t.Error("a cursor with an id is a keyset cursor")
+	}
+
+	for _, value := range []string{"not base64!", "bm90IGpzb24", Cursor{Offset: -5}.Encode()} {
+		if _, err := DecodeCursor(value); err != ErrInvalidCursor {
+			t.Errorf("DecodeCursor(%q) = %v, want ErrInvalidCursor", value, err)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name                   string
+		limit, offset, total   int
+		nextOffset, prevOffset int
+	}{
+		{"first page", 10, 0, 25, 10, -1},
+		{"middle page", 10, 10, 25, 20, 0},
+		{"last page", 10, 20, 25, -1, 10},
+		{"short offset", 10, 5, 25, 15, 0},
+		{"empty", 10, 0, 0, -1, -1},
+	}
+
+	offset := func(value string) int {
+		if value == "" {
+			return -1
+		}
+
+		cursor, err := DecodeCursor(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return cursor.Offset
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			page := New(test.limit, test.offset, test.total)
+
+			if got := offset(page.Next); got != test.nextOffset {
+				t.Errorf("next offset = %d, want %d", got, test.nextOffset)
+			}
+
+			if got := offset(page.Prev); got != test.prevOffset {
+				t.Errorf("prev offset = %d, want %d", got, test.prevOffset)
+			}
+		})
+	}
+}
+
+func TestLinks(t *testing.T) {
+	u, _ := url.Parse("https://api.gobali.com/v1/villas?category=beach&offset=10&limit=10")
+
+	links := New(10, 10, 25).Links(u)
+
+	for _, want := range []string{
+		`rel="next"`,
+		`rel="prev"`,
+		`<https://api.gobali.com/v1/villas?category=beach&limit=10>; rel="first"`,
+		`<https://api.gobali.com/v1/villas?category=beach&limit=10&offset=20>; rel="last"`,
+	} {
+		if !strings.Contains(links, want) {
+			t.Errorf("links %s do not contain %s", links, want)
+		}
+	}
+
+	if strings.Contains(links, "offset=10") {
+		t.Errorf("the offset of the request is replaced by the cursor: %s", links)
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"strings"
+
+	"github.com/faizisyellow/gobali/internal/listquery"
+	"github.com/faizisyellow/gobali/internal/pagination"
 )
 
 type AmenitiesRepository struct {
@@ -25,27 +28,32 @@ type SelectedAmenity struct {
 	Type string `json:"type_name"`
 }
 
-func (a *AmenitiesRepository) Create(ctx context.Context, name string, typeId int) error {
+func (a *AmenitiesRepository) Create(ctx context.Context, name string, typeId int) (int, error) {
 	query := `INSERT INTO amenities(name,type_id) VALUE(?, ?)`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	_, err := a.db.ExecContext(ctx, query, &name, &typeId)
+	res, err := a.db.ExecContext(ctx, query, &name, &typeId)
 	if err != nil {
 		duplicateKey := "Error 1062"
 		emptyKey := "Error 1452"
 		switch {
 		case strings.Contains(err.Error(), duplicateKey):
-			return ErrDuplicateAmenities
+			return 0, ErrDuplicateAmenities
 		case strings.Contains(err.Error(), emptyKey):
-			return ErrTypeNotExist
+			return 0, ErrTypeNotExist
 		default:
-			return err
+			return 0, err
 		}
 	}
 
-	return nil
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
 }
 
 func (a *AmenitiesRepository) GetByID(ctx context.Context, id int) (*Amenity, error) {
@@ -70,17 +78,25 @@ func (a *AmenitiesRepository) GetByID(ctx context.Context, id int) (*Amenity, er
 	return am, nil
 }
 
-func (a *AmenitiesRepository) GetAmenities(ctx context.Context, qp PaginatedAmenitiesQuery) ([]*Amenity, error) {
+func (a *AmenitiesRepository) GetAmenities(ctx context.Context, qp listquery.Query) ([]*Amenity, pagination.Page, error) {
+	where, args := qp.Where()
+
 	query := `SELECT a.id, a.name, a.type_id, t.name, a.created_at FROM amenities a LEFT JOIN types t ON a.type_id = t.id
-	ORDER BY a.created_at ` + qp.Sort + ` LIMIT ? OFFSET ? 
+	WHERE ` + where + `
+	ORDER BY ` + qp.OrderBy(false) + ` LIMIT ? OFFSET ? 
 	`
 
+	total, err := countRows(ctx, a.db, `SELECT COUNT(*) FROM amenities a WHERE `+where, args...)
+	if err != nil {
+		return nil, pagination.Page{}, err
+	}
+
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	rows, err := a.db.QueryContext(ctx, query, qp.Limit, qp.Offset)
+	rows, err := a.db.QueryContext(ctx, query, append(args, qp.Limit, qp.Offset)...)
 	if err != nil {
-		return nil, err
+		return nil, pagination.Page{}, err
 	}
 
 	defer rows.Close()
@@ -91,17 +107,17 @@ func (a *AmenitiesRepository) GetAmenities(ctx context.Context, qp PaginatedAmen
 		am := &Amenity{}
 		err := rows.Scan(&am.Id, &am.Name, &am.TypeId, &am.Type.Name, &am.CreatedAt)
 		if err != nil {
-			return nil, err
+			return nil, pagination.Page{}, err
 		}
 
 		amenities = append(amenities, am)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return nil, pagination.Page{}, err
 	}
 
-	return amenities, nil
+	return amenities, qp.Page(total), nil
 }
 
 func (a *AmenitiesRepository) Update(ctx context.Context, amentity *Amenity) error {
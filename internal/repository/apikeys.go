@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+type ApiKeysRepository struct {
+	db *sql.DB
+}
+
+type ApiKey struct {
+	Id         int      `json:"id"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"`
+	Hash       string   `json:"-"`
+	UserId     int      `json:"user_id"`
+	Scopes     []string `json:"scopes"`
+	CreatedBy  *int     `json:"created_by"`
+	LastUsedAt *string  `json:"last_used_at"`
+	ExpiresAt  *string  `json:"expires_at"`
+	RevokedAt  *string  `json:"revoked_at"`
+	CreatedAt  string   `json:"created_at"`
+
+	lastUsed time.Time
+}
+
+func (a *ApiKeysRepository) Create(ctx context.Context, key *ApiKey, expiresAt *time.Time) error {
+	query := `INSERT INTO api_keys(name, prefix, key_hash, user_id, scopes, created_by, expires_at) VALUES(?,?,?,?,?,?,?)`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	scopes, err := json.Marshal(key.Scopes)
+	if err != nil {
+		return err
+	}
+
+	res, err := a.db.ExecContext(ctx, query, key.Name, key.Prefix, key.Hash, key.UserId, scopes, key.CreatedBy, expiresAt)
+	if err != nil {
+		notExist := "Error 1452"
+		switch {
+		case strings.Contains(err.Error(), notExist):
+			return ErrNoRows
+		default:
+			return err
+		}
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	key.Id = int(id)
+
+	return nil
+}
+
+// GetActiveByPrefix returns the key if it is neither revoked nor expired.
+func (a *ApiKeysRepository) GetActiveByPrefix(ctx context.Context, prefix string) (*ApiKey, error) {
+	query := `
+	SELECT id, name, prefix, key_hash, user_id, scopes, created_by, last_used_at, expires_at, revoked_at, created_at
+	FROM api_keys WHERE prefix = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	key, err := scanApiKey(a.db.QueryRowContext(ctx, query, prefix, time.Now()))
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return nil, ErrNoRows
+		default:
+			return nil, err
+		}
+	}
+
+	return key, nil
+}
+
+func (a *ApiKeysRepository) GetByID(ctx context.Context, id int) (*ApiKey, error) {
+	query := `
+	SELECT id, name, prefix, key_hash, user_id, scopes, created_by, last_used_at, expires_at, revoked_at, created_at
+	FROM api_keys WHERE id = ?
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	key, err := scanApiKey(a.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return nil, ErrNoRows
+		default:
+			return nil, err
+		}
+	}
+
+	return key, nil
+}
+
+func (a *ApiKeysRepository) GetApiKeys(ctx context.Context) ([]*ApiKey, error) {
+	query := `
+	SELECT id, name, prefix, key_hash, user_id, scopes, created_by, last_used_at, expires_at, revoked_at, created_at
+	FROM api_keys ORDER BY created_at DESC
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := a.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	keys := []*ApiKey{}
+
+	for rows.Next() {
+		key, err := scanApiKey(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (a *ApiKeysRepository) Revoke(ctx context.Context, id int) error {
+	query := `UPDATE api_keys SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := a.db.ExecContext(ctx, query, time.Now(), id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// TouchDue tells whether the last used time is old enough to be written again.
+func (k *ApiKey) TouchDue() bool {
+	return time.Since(k.lastUsed) >= apiKeyTouchInterval
+}
+
+func (a *ApiKeysRepository) Touch(ctx context.Context, id int) error {
+	query := `UPDATE api_keys SET last_used_at = ? WHERE id = ? AND (last_used_at IS NULL OR last_used_at < ?)`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	now := time.Now()
+
+	_, err := a.db.ExecContext(ctx, query, now, id, now.Add(-apiKeyTouchInterval))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanApiKey(row rowScanner) (*ApiKey, error) {
+	key := &ApiKey{}
+
+	var scopes []byte
+	var lastUsed dbTime
+
+	err := row.Scan(
+		&key.Id,
+		&key.Name,
+		&key.Prefix,
+		&key.Hash,
+		&key.UserId,
+		&scopes,
+		&key.CreatedBy,
+		&lastUsed,
+		&key.ExpiresAt,
+		&key.RevokedAt,
+		&key.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	key.lastUsed = lastUsed.Time
+	key.LastUsedAt = lastUsed.text()
+
+	if err := json.Unmarshal(scopes, &key.Scopes); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/faizisyellow/gobali/internal/listquery"
+	"github.com/faizisyellow/gobali/internal/pagination"
+)
+
+type AuditRepository struct {
+	db *sql.DB
+}
+
+type AuditEvent struct {
+	Id             int             `json:"id"`
+	ActorId        *int            `json:"actor_id"`
+	ImpersonatorId *int            `json:"impersonator_id"`
+	Action         string          `json:"action"`
+	Entity         string          `json:"entity"`
+	EntityId       int             `json:"entity_id"`
+	Before         json.RawMessage `json:"before" swaggertype:"object"`
+	After          json.RawMessage `json:"after" swaggertype:"object"`
+	RequestId      string          `json:"request_id"`
+	IP             string          `json:"ip"`
+	CreatedAt      string          `json:"created_at"`
+}
+
+func (a *AuditRepository) Create(ctx context.Context, event *AuditEvent) error {
+	return a.create(ctx, a.db, event)
+}
+
+// CreateWithTx records the event with the change itself, e.g. before a row the event refers to is deleted.
+func (a *AuditRepository) CreateWithTx(ctx context.Context, tx *sql.Tx, event *AuditEvent) error {
+	return a.create(ctx, tx, event)
+}
+
+func (a *AuditRepository) create(ctx context.Context, db interface {
+	ExecContext(context.Context, string, ...any) (sql.Result, error)
+}, event *AuditEvent) error {
+	query := `INSERT INTO audit_events(actor_id, action, entity, entity_id, before_data, after_data, request_id, ip, impersonator_id)
+	VALUES(?,?,?,?,?,?,?,?,?)`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := db.ExecContext(ctx, query,
+		event.ActorId,
+		event.Action,
+		event.Entity,
+		event.EntityId,
+		nullableJSON(event.Before),
+		nullableJSON(event.After),
+		event.RequestId,
+		event.IP,
+		event.ImpersonatorId,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	event.Id = int(id)
+
+	return nil
+}
+
+// personalFields are the keys of the snapshots holding personal data, the users and bookings
+// and the profile response, which is encoded with its field names.
+var personalFields = []string{"username", "email", "full_name", "phone", "first_name", "last_name", "bookings", "Username", "Email", "FullName", "Phone"}
+
+// redactWithTx erases the personal data of the user from the snapshots of its account and bookings,
+// and the address it made its changes from. The events stay, with the ids and amounts they recorded.
+func (a *AuditRepository) redactWithTx(ctx context.Context, tx *sql.Tx, userId int) error {
+	paths := make([]string, len(personalFields))
+	for i, field := range personalFields {
+		paths[i] = "'$." + field + "'"
+	}
+
+	remove := strings.Join(paths, ", ")
+
+	// the bookings deleted before are only found by the owner in their snapshots
+	query := fmt.Sprintf(`UPDATE audit_events SET before_data = JSON_REMOVE(before_data, %[1]s), after_data = JSON_REMOVE(after_data, %[1]s)
+	WHERE (entity = 'user' AND entity_id = ?)
+	OR (entity = 'booking' AND (
+		entity_id IN (SELECT id FROM bookings WHERE user_id = ?)
+		OR JSON_EXTRACT(before_data, '$.user_id') = ?
+		OR JSON_EXTRACT(after_data, '$.user_id') = ?
+	))`, remove)
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, query, userId, userId, userId, userId)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE audit_events SET ip = '' WHERE actor_id = ?`, userId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// nullableJSON stores an empty snapshot as NULL rather than invalid JSON.
+func nullableJSON(data json.RawMessage) any {
+	if len(data) == 0 {
+		return nil
+	}
+
+	return []byte(data)
+}
+
+// GetEvents filters on every field that is set, "to" includes the whole day.
+func (a *AuditRepository) GetEvents(ctx context.Context, pq listquery.Query) ([]*AuditEvent, pagination.Page, error) {
+	where, args := pq.Where()
+
+	query := `
+	SELECT id, actor_id, impersonator_id, action, entity, entity_id, before_data, after_data, request_id, ip, created_at
+	FROM audit_events
+	WHERE ` + where + `
+	ORDER BY ` + pq.OrderBy(false) + ` LIMIT ? OFFSET ?
+	`
+
+	total, err := countRows(ctx, a.db, `SELECT COUNT(*) FROM audit_events WHERE `+where, args...)
+	if err != nil {
+		return nil, pagination.Page{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := a.db.QueryContext(ctx, query, append(args, pq.Limit, pq.Offset)...)
+	if err != nil {
+		return nil, pagination.Page{}, err
+	}
+
+	defer rows.Close()
+
+	events := []*AuditEvent{}
+
+	for rows.Next() {
+		event := &AuditEvent{}
+
+		var before, after []byte
+
+		err := rows.Scan(
+			&event.Id,
+			&event.ActorId,
+			&event.ImpersonatorId,
+			&event.Action,
+			&event.Entity,
+			&event.EntityId,
+			&before,
+			&after,
+			&event.RequestId,
+			&event.IP,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, pagination.Page{}, err
+		}
+
+		event.Before = before
+		event.After = after
+
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, pagination.Page{}, err
+	}
+
+	return events, pq.Page(total), nil
+}
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/charmbracelet/log"
+)
+
+// BlobsRepository counts the villa images referring to each stored file,
+// identical uploads share one file named by its content hash.
+type BlobsRepository struct {
+	db *sql.DB
+}
+
+var ErrBlobGone = errors.New("image is no longer stored, upload it again")
+
+// BlobStore is the storage of the counted files. A file is checked and removed while its count is locked,
+// so a villa sharing a file can not lose it to another villa releasing it at the same time.
+type BlobStore interface {
+	Exists(ctx context.Context, name string) (bool, error)
+	Remove(ctx context.Context, names []string) error
+}
+
+// retainWithTx counts one more reference for every name, a name given twice is counted twice.
+// The upload of a file shared with another villa stored nothing, so the file must still be there.
+func (b *BlobsRepository) retainWithTx(ctx context.Context, tx *sql.Tx, store BlobStore, names []string) error {
+	query := `INSERT INTO image_blobs(name, ref_count) VALUES(?, 1) ON DUPLICATE KEY UPDATE ref_count = ref_count + 1`
+
+	dbCtx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	for _, name := range names {
+		if _, err := tx.ExecContext(dbCtx, query, name); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range names {
+		exists, err := store.Exists(ctx, name)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			return fmt.Errorf("%w: %s", ErrBlobGone, name)
+		}
+	}
+
+	return nil
+}
+
+// releaseWithTx drops one reference for every name and removes the files nothing refers to anymore.
+// Files stored before the counting have no count, their only reference is the released one.
+func (b *BlobsRepository) releaseWithTx(ctx context.Context, tx *sql.Tx, store BlobStore, names []string) error {
+	dbCtx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	released := []string{}
+
+	for _, name := range names {
+		count, err := lockCount(dbCtx, tx, name)
+		if err != nil {
+			return err
+		}
+
+		if count > 1 {
+			if _, err := tx.ExecContext(dbCtx, `UPDATE image_blobs SET ref_count = ref_count - 1 WHERE name = ?`, name); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if _, err := tx.ExecContext(dbCtx, `DELETE FROM image_blobs WHERE name = ?`, name); err != nil {
+			return err
+		}
+
+		released = append(released, name)
+	}
+
+	removeBlobs(ctx, store, released)
+
+	return nil
+}
+
+// Discard removes the uploads of a villa that failed to save, unless another villa refers to them.
+func (b *BlobsRepository) Discard(ctx context.Context, store BlobStore, names []string) error {
+	return withTx(b.db, ctx, func(tx *sql.Tx) error {
+		dbCtx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		unreferenced := []string{}
+
+		for _, name := range names {
+			count, err := lockCount(dbCtx, tx, name)
+			if err != nil {
+				return err
+			}
+
+			if count < 1 {
+				unreferenced = append(unreferenced, name)
+			}
+		}
+
+		removeBlobs(ctx, store, unreferenced)
+
+		return nil
+	})
+}
+
+// RemoveUnreferenced removes an orphan image for the garbage collector, unless a villa retained it since the orphans were listed.
+// The count stays locked meanwhile, a villa retaining the image waits and then finds it gone.
+func (b *BlobsRepository) RemoveUnreferenced(ctx context.Context, store BlobStore, name string) (bool, error) {
+	removed := false
+
+	err := withTx(b.db, ctx, func(tx *sql.Tx) error {
+		dbCtx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		count, err := lockCount(dbCtx, tx, name)
+		if err != nil {
+			return err
+		}
+
+		if count > 0 {
+			return nil
+		}
+
+		if err := store.Remove(ctx, []string{name}); err != nil {
+			return err
+		}
+
+		removed = true
+
+		return nil
+	})
+
+	return removed, err
+}
+
+// lockCount reads the count of the name and locks it, a name without a count is locked as well
+// so no other villa retains it until the transaction ends.
+func lockCount(ctx context.Context, tx *sql.Tx, name string) (int, error) {
+	var count int
+
+	err := tx.QueryRowContext(ctx, `SELECT ref_count FROM image_blobs WHERE name = ? FOR UPDATE`, name).Scan(&count)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// removeBlobs removes the files before the counts are unlocked,
+// a failure leaves an orphan file behind for the garbage collector and is only logged.
+func removeBlobs(ctx context.Context, store BlobStore, names []string) {
+	if len(names) == 0 {
+		return
+	}
+
+	if err := store.Remove(ctx, names); err != nil {
+		log.Error("remove images", "error", err)
+	}
+}
+
+// GetReferenced returns the names still counted as referenced, the garbage collector keeps them.
+func (b *BlobsRepository) GetReferenced(ctx context.Context) ([]string, error) {
+	query := `SELECT name FROM image_blobs WHERE ref_count > 0`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := b.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	names := []string{}
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+
+		names = append(names, name)
+	}
+
+	return names, rows.Err()
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+)
+
+// BookingDocument is a guest document of the check-in registration, e.g. a passport,
+// the file is private and only reachable through a signed URL.
+type BookingDocument struct {
+	Id        int    `json:"id"`
+	BookingId int    `json:"booking_id"`
+	Name      string `json:"name"`
+	URL       string `json:"url"`
+	CreatedAt string `json:"created_at"`
+}
+
+func (b *BookingsRepository) AddDocuments(ctx context.Context, bookingId int, names []string) error {
+	query := `INSERT INTO booking_documents(booking_id, name) VALUES(?,?)`
+
+	return withTx(b.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		for _, name := range names {
+			if _, err := tx.ExecContext(ctx, query, bookingId, name); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (b *BookingsRepository) GetDocuments(ctx context.Context, bookingId int) ([]*BookingDocument, error) {
+	query := `SELECT id, booking_id, name, created_at FROM booking_documents WHERE booking_id = ? ORDER BY id`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := b.db.QueryContext(ctx, query, bookingId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	documents := []*BookingDocument{}
+
+	for rows.Next() {
+		document := &BookingDocument{}
+		if err := rows.Scan(&document.Id, &document.BookingId, &document.Name, &document.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		documents = append(documents, document)
+	}
+
+	return documents, rows.Err()
+}
+
+// GetDocumentNames returns the files of every document, the garbage collector keeps them.
+func (b *BookingsRepository) GetDocumentNames(ctx context.Context) ([]string, error) {
+	query := `SELECT DISTINCT name FROM booking_documents`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := b.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	names := []string{}
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+
+		names = append(names, name)
+	}
+
+	return names, rows.Err()
+}
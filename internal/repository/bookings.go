@@ -3,6 +3,9 @@ package repository
 import (
 	"context"
 	"database/sql"
+
+	"github.com/faizisyellow/gobali/internal/listquery"
+	"github.com/faizisyellow/gobali/internal/pagination"
 )
 
 type BookingsRepository struct {
@@ -47,7 +50,7 @@ func (b *BookingsRepository) Create(ctx context.Context, newBooking *Booking) er
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	_, err := b.db.ExecContext(ctx, query,
+	res, err := b.db.ExecContext(ctx, query,
 		newBooking.UserId,
 		newBooking.VillaId,
 		newBooking.VillaName,
@@ -66,12 +69,19 @@ func (b *BookingsRepository) Create(ctx context.Context, newBooking *Booking) er
 		return err
 	}
 
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	newBooking.Id = int(id)
+
 	return nil
 }
 
 func (b *BookingsRepository) GetById(ctx context.Context, id int) (*Booking, error) {
 	query := `SELECT id,first_name,last_name,status,villa_id,villa_name,villa_price,villa_location,total_price,
-	start_at,end_at,created_at,updated_at,user_id,email,guest FROM bookings WHERE id = ?
+	start_at,end_at,created_at,updated_at,COALESCE(user_id, 0),email,guest FROM bookings WHERE id = ?
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
@@ -109,18 +119,26 @@ func (b *BookingsRepository) GetById(ctx context.Context, id int) (*Booking, err
 	return &booking, nil
 }
 
-func (b *BookingsRepository) GetBookings(ctx context.Context, pq PaginatedBookingsQuery) ([]*Booking, error) {
+func (b *BookingsRepository) GetBookings(ctx context.Context, pq listquery.Query) ([]*Booking, pagination.Page, error) {
+	where, args := pq.Where()
+
 	query := `SELECT id,first_name,last_name,status,villa_name,villa_price,villa_location,total_price,
-	start_at,end_at,email,guest,villa_id,user_id,created_at,updated_at FROM bookings
-	ORDER BY created_at ` + pq.Sort + ` LIMIT ? OFFSET ?
+	start_at,end_at,email,guest,villa_id,COALESCE(user_id, 0),created_at,updated_at FROM bookings
+	WHERE ` + where + `
+	ORDER BY ` + pq.OrderBy(false) + ` LIMIT ? OFFSET ?
 	`
 
+	total, err := countRows(ctx, b.db, `SELECT COUNT(*) FROM bookings WHERE `+where, args...)
+	if err != nil {
+		return nil, pagination.Page{}, err
+	}
+
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	rows, err := b.db.QueryContext(ctx, query, pq.Limit, pq.Offset)
+	rows, err := b.db.QueryContext(ctx, query, append(args, pq.Limit, pq.Offset)...)
 	if err != nil {
-		return nil, err
+		return nil, pagination.Page{}, err
 	}
 
 	defer rows.Close()
@@ -150,17 +168,17 @@ func (b *BookingsRepository) GetBookings(ctx context.Context, pq PaginatedBookin
 		)
 
 		if err != nil {
-			return nil, err
+			return nil, pagination.Page{}, err
 		}
 
 		bookings = append(bookings, booking)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, err
+		return nil, pagination.Page{}, err
 	}
 
-	return bookings, nil
+	return bookings, pq.Page(total), nil
 }
 
 func (b *BookingsRepository) GetBookingVillaByDate(ctx context.Context, startAt, endAt string, villaId int) (*Booking, error) {
@@ -223,3 +241,79 @@ func (b *BookingsRepository) Delete(ctx context.Context, id int) error {
 
 	return nil
 }
+
+// GetByUserID returns every booking of the user, used for the personal data export.
+func (b *BookingsRepository) GetByUserID(ctx context.Context, userId int) ([]*Booking, error) {
+	query := `SELECT id,first_name,last_name,status,villa_name,villa_price,villa_location,total_price,
+	start_at,end_at,email,guest,villa_id,user_id,created_at,updated_at FROM bookings
+	WHERE user_id = ? ORDER BY created_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := b.db.QueryContext(ctx, query, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	bookings := []*Booking{}
+
+	for rows.Next() {
+		booking := &Booking{}
+
+		err := rows.Scan(
+			&booking.Id,
+			&booking.FirstName,
+			&booking.LastName,
+			&booking.Status,
+			&booking.VillaName,
+			&booking.VillaPrice,
+			&booking.VillaLocation,
+			&booking.TotalPrice,
+			&booking.StartAt,
+			&booking.EndAt,
+			&booking.Email,
+			&booking.Guest,
+			&booking.VillaId,
+			&booking.UserId,
+			&booking.CreatedAt,
+			&booking.UpdatedAt,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		bookings = append(bookings, booking)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return bookings, nil
+}
+
+// anonymizeWithTx detaches the bookings from the user and erases the guest's personal details,
+// the booking itself stays for the revenue history. The documents go too, the garbage collector removes their files.
+func (b *BookingsRepository) anonymizeWithTx(ctx context.Context, tx *sql.Tx, userId int) error {
+	query := `UPDATE bookings SET user_id = NULL, first_name = 'deleted', last_name = '', email = '' WHERE user_id = ?`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, `DELETE FROM booking_documents WHERE booking_id IN (SELECT id FROM bookings WHERE user_id = ?)`, userId)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, query, userId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+
+	"github.com/faizisyellow/gobali/internal/cache"
+)
+
+// WithCache caches the user and role lookups done by every authenticated request.
+// Changes going through the repository invalidate the entries, other instances see them after the ttl.
+func WithCache(repo Repository, db *sql.DB, ttl time.Duration) Repository {
+	users := cache.New[int, *User]("users", ttl)
+
+	repo.Users = &cachedUsers{
+		UserRepository: &UserRepository{db},
+		users:          users,
+	}
+
+	repo.Roles = &cachedRoles{
+		RolesRepository: &RolesRepository{db},
+		byId:            cache.New[int, *Role]("roles_by_id", ttl),
+		byName:          cache.New[string, *Role]("roles_by_name", ttl),
+		users:           users,
+	}
+
+	return repo
+}
+
+type cachedUsers struct {
+	*UserRepository
+	users *cache.TTL[int, *User]
+}
+
+// GetByID returns a copy, callers may change the user.
+func (c *cachedUsers) GetByID(ctx context.Context, userId int) (*User, error) {
+	if user, ok := c.users.Get(userId); ok {
+		return cloneUser(user), nil
+	}
+
+	user, err := c.UserRepository.GetByID(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	c.users.Set(userId, cloneUser(user))
+
+	return user, nil
+}
+
+func (c *cachedUsers) Delete(ctx context.Context, userId int, event *AuditEvent) error {
+	defer c.users.Delete(userId)
+
+	return c.UserRepository.Delete(ctx, userId, event)
+}
+
+func (c *cachedUsers) UpdateWithTx(ctx context.Context, tx *sql.Tx, user *User) error {
+	defer c.users.Delete(user.Id)
+
+	return c.UserRepository.UpdateWithTx(ctx, tx, user)
+}
+
+func (c *cachedUsers) UpdateRole(ctx context.Context, userId, roleId int) error {
+	defer c.users.Delete(userId)
+
+	return c.UserRepository.UpdateRole(ctx, userId, roleId)
+}
+
+func (c *cachedUsers) SetActive(ctx context.Context, userId int, active bool) error {
+	defer c.users.Delete(userId)
+
+	return c.UserRepository.SetActive(ctx, userId, active)
+}
+
+func (c *cachedUsers) UpdateProfile(ctx context.Context, user *User) error {
+	defer c.users.Delete(user.Id)
+
+	return c.UserRepository.UpdateProfile(ctx, user)
+}
+
+// ConfirmEmailChange only knows the token, so every user is dropped.
+func (c *cachedUsers) ConfirmEmailChange(ctx context.Context, token string) error {
+	defer c.users.Clear()
+
+	return c.UserRepository.ConfirmEmailChange(ctx, token)
+}
+
+type cachedRoles struct {
+	*RolesRepository
+	byId   *cache.TTL[int, *Role]
+	byName *cache.TTL[string, *Role]
+	users  *cache.TTL[int, *User]
+}
+
+func (c *cachedRoles) GetByID(ctx context.Context, id int) (*Role, error) {
+	if role, ok := c.byId.Get(id); ok {
+		return cloneRole(role), nil
+	}
+
+	role, err := c.RolesRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	c.byId.Set(id, cloneRole(role))
+
+	return role, nil
+}
+
+func (c *cachedRoles) GetByName(ctx context.Context, name string) (*Role, error) {
+	if role, ok := c.byName.Get(name); ok {
+		return cloneRole(role), nil
+	}
+
+	role, err := c.RolesRepository.GetByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	c.byName.Set(name, cloneRole(role))
+
+	return role, nil
+}
+
+func (c *cachedRoles) Update(ctx context.Context, role *Role) error {
+	defer c.invalidate()
+
+	return c.RolesRepository.Update(ctx, role)
+}
+
+func (c *cachedRoles) Delete(ctx context.Context, id int) error {
+	defer c.invalidate()
+
+	return c.RolesRepository.Delete(ctx, id)
+}
+
+func (c *cachedRoles) SetPermissions(ctx context.Context, roleId int, permissions []string) error {
+	defer c.invalidate()
+
+	return c.RolesRepository.SetPermissions(ctx, roleId, permissions)
+}
+
+// invalidate drops the users too, a user carries the name and permissions of its role.
+func (c *cachedRoles) invalidate() {
+	c.byId.Clear()
+	c.byName.Clear()
+	c.users.Clear()
+}
+
+func cloneUser(user *User) *User {
+	clone := *user
+	clone.Password.Hash = slices.Clone(user.Password.Hash)
+	clone.Role = *cloneRole(&user.Role)
+	clone.Bookings = slices.Clone(user.Bookings)
+
+	if user.FullName != nil {
+		fullName := *user.FullName
+		clone.FullName = &fullName
+	}
+
+	if user.Phone != nil {
+		phone := *user.Phone
+		clone.Phone = &phone
+	}
+
+	return &clone
+}
+
+func cloneRole(role *Role) *Role {
+	clone := *role
+	clone.Permissions = slices.Clone(role.Permissions)
+
+	return &clone
+}
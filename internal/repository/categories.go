@@ -4,6 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"strings"
+
+	"github.com/faizisyellow/gobali/internal/listquery"
+	"github.com/faizisyellow/gobali/internal/pagination"
 )
 
 type CategoriesRepository struct {
@@ -22,24 +25,29 @@ type SelectedCategory struct {
 	Name string `json:"name"`
 }
 
-func (c *CategoriesRepository) Create(ctx context.Context, name string) error {
+func (c *CategoriesRepository) Create(ctx context.Context, name string) (int, error) {
 	query := `INSERT INTO categories(name) VALUE(?)`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	_, err := c.db.ExecContext(ctx, query, &name)
+	res, err := c.db.ExecContext(ctx, query, &name)
 	if err != nil {
 		duplicateKey := "Error 1062"
 		switch {
 		case strings.Contains(err.Error(), duplicateKey):
-			return ErrDuplicateCategory
+			return 0, ErrDuplicateCategory
 		default:
-			return err
+			return 0, err
 		}
 	}
 
-	return nil
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
 }
 
 func (c *CategoriesRepository) GetByID(ctx context.Context, id int) (*Category, error) {
@@ -63,15 +71,22 @@ func (c *CategoriesRepository) GetByID(ctx context.Context, id int) (*Category,
 	return cat, nil
 }
 
-func (c *CategoriesRepository) GetCategories(ctx context.Context, qp PaginatedCategoriesQuery) ([]*Category, error) {
-	query := `SELECT id, name, created_at FROM categories ORDER BY created_at ` + qp.Sort + ` LIMIT ? OFFSET ?`
+func (c *CategoriesRepository) GetCategories(ctx context.Context, qp listquery.Query) ([]*Category, pagination.Page, error) {
+	where, args := qp.Where()
+
+	query := `SELECT id, name, created_at FROM categories WHERE ` + where + ` ORDER BY ` + qp.OrderBy(false) + ` LIMIT ? OFFSET ?`
+
+	total, err := countRows(ctx, c.db, `SELECT COUNT(*) FROM categories WHERE `+where, args...)
+	if err != nil {
+		return nil, pagination.Page{}, err
+	}
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	rows, err := c.db.QueryContext(ctx, query, qp.Limit, qp.Offset)
+	rows, err := c.db.QueryContext(ctx, query, append(args, qp.Limit, qp.Offset)...)
 	if err != nil {
-		return nil, err
+		return nil, pagination.Page{}, err
 	}
 
 	defer rows.Close()
@@ -82,17 +97,17 @@ func (c *CategoriesRepository) GetCategories(ctx context.Context, qp PaginatedCa
 		category := &Category{}
 		err := rows.Scan(&category.Id, &category.Name, &category.CreatedAt)
 		if err != nil {
-			return nil, err
+			return nil, pagination.Page{}, err
 		}
 
 		categories = append(categories, category)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return nil, pagination.Page{}, err
 	}
 
-	return categories, nil
+	return categories, qp.Page(total), nil
 }
 
 func (c *CategoriesRepository) Update(ctx context.Context, category *Category) error {
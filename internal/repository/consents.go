@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+)
+
+type ConsentsRepository struct {
+	db *sql.DB
+}
+
+type Consent struct {
+	Purpose   string `json:"purpose"`
+	Granted   bool   `json:"granted"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+func (c *ConsentsRepository) GetByUserID(ctx context.Context, userId int) ([]*Consent, error) {
+	query := `SELECT purpose, granted, updated_at FROM user_consents WHERE user_id = ? ORDER BY purpose`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := c.db.QueryContext(ctx, query, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	consents := []*Consent{}
+
+	for rows.Next() {
+		consent := &Consent{}
+		if err := rows.Scan(&consent.Purpose, &consent.Granted, &consent.UpdatedAt); err != nil {
+			return nil, err
+		}
+
+		consents = append(consents, consent)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return consents, nil
+}
+
+func (c *ConsentsRepository) Set(ctx context.Context, userId int, purpose string, granted bool) error {
+	query := `INSERT INTO user_consents(user_id, purpose, granted) VALUES(?,?,?) ON DUPLICATE KEY UPDATE granted = VALUES(granted)`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := c.db.ExecContext(ctx, query, userId, purpose, granted)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
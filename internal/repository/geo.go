@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultRadiusKm of a near query without a radius
+	DefaultRadiusKm = 5
+	// MaxPins a map view gets, the map has to zoom in for the rest
+	MaxPins = 500
+)
+
+type GeoPoint struct {
+	Lat float64 `json:"lat" validate:"gte=-90,lte=90"`
+	Lng float64 `json:"lng" validate:"gte=-180,lte=180"`
+}
+
+// GeoBounds is the box of a map view, a box crossing the antimeridian has MinLng greater than MaxLng.
+type GeoBounds struct {
+	MinLat float64 `json:"min_lat" validate:"gte=-90,lte=90"`
+	MinLng float64 `json:"min_lng" validate:"gte=-180,lte=180"`
+	MaxLat float64 `json:"max_lat" validate:"gte=-90,lte=90,gtefield=MinLat"`
+	MaxLng float64 `json:"max_lng" validate:"gte=-180,lte=180"`
+}
+
+// VillaPin is what a map shows of a villa.
+type VillaPin struct {
+	Id        int     `json:"id"`
+	Name      string  `json:"name"`
+	Price     float64 `json:"price"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Cover     string  `json:"cover"`
+}
+
+// ParseGeoPoint reads "lat,lng".
+func ParseGeoPoint(value string) (*GeoPoint, error) {
+	coords, err := parseCoordinates(value, 2)
+	if err != nil {
+		return nil, fmt.Errorf("near must be lat,lng: %w", err)
+	}
+
+	return &GeoPoint{Lat: coords[0], Lng: coords[1]}, nil
+}
+
+// ParseGeoBounds reads "min_lat,min_lng,max_lat,max_lng", the south-west and the north-east corner.
+func ParseGeoBounds(value string) (*GeoBounds, error) {
+	coords, err := parseCoordinates(value, 4)
+	if err != nil {
+		return nil, fmt.Errorf("bbox must be min_lat,min_lng,max_lat,max_lng: %w", err)
+	}
+
+	return &GeoBounds{MinLat: coords[0], MinLng: coords[1], MaxLat: coords[2], MaxLng: coords[3]}, nil
+}
+
+func parseCoordinates(value string, n int) ([]float64, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != n {
+		return nil, fmt.Errorf("got %d values", len(parts))
+	}
+
+	coords := make([]float64, 0, n)
+	for _, part := range parts {
+		coord, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, err
+		}
+
+		coords = append(coords, coord)
+	}
+
+	return coords, nil
+}
+
+// villaCoordinates of villas v joined with locations l, a villa without its own
+// coordinates is placed at its location.
+const (
+	villaLat = "COALESCE(v.latitude, l.latitude)"
+	villaLng = "COALESCE(v.longitude, l.longitude)"
+)
+
+// villaDistance in meters from the point, on a sphere of the earth's radius.
+func villaDistance(point GeoPoint) (string, []any) {
+	return "ST_Distance_Sphere(POINT(" + villaLng + ", " + villaLat + "), POINT(?, ?))", []any{point.Lng, point.Lat}
+}
+
+func geoFilters(vq PaginatedVillaQuery) ([]string, []any) {
+	conds := []string{}
+	args := []any{}
+
+	if vq.Near != nil {
+		radius := vq.RadiusKm
+		if radius == 0 {
+			radius = DefaultRadiusKm
+		}
+
+		distance, distanceArgs := villaDistance(*vq.Near)
+
+		conds = append(conds, distance+" <= ?")
+		args = append(args, distanceArgs...)
+		args = append(args, radius*1000)
+	}
+
+	if vq.Bounds != nil {
+		b := vq.Bounds
+
+		conds = append(conds, villaLat+" BETWEEN ? AND ?")
+		args = append(args, b.MinLat, b.MaxLat)
+
+		if b.MinLng <= b.MaxLng {
+			conds = append(conds, villaLng+" BETWEEN ? AND ?")
+		} else {
+			conds = append(conds, "("+villaLng+" >= ? OR "+villaLng+" <= ?)")
+		}
+
+		args = append(args, b.MinLng, b.MaxLng)
+	}
+
+	return conds, args
+}
+
+// GetPins lists the villas of the filters with their coordinates, at most MaxPins of them.
+func (v *VillasRepository) GetPins(ctx context.Context, vq PaginatedVillaQuery) ([]*VillaPin, error) {
+	pins := []*VillaPin{}
+
+	if vq.Ids != nil && len(vq.Ids) == 0 {
+		return pins, nil
+	}
+
+	where, args := villaFilters(vq)
+
+	query := `
+	SELECT v.id, v.name, v.price, ` + villaLat + `, ` + villaLng + `, v.image_urls
+	FROM villas v LEFT JOIN categories c ON c.id = v.category_id LEFT JOIN locations l ON l.id = v.location_id
+	WHERE ` + where + ` AND ` + villaLat + ` IS NOT NULL AND ` + villaLng + ` IS NOT NULL
+	ORDER BY v.id
+	LIMIT ?`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := v.db.QueryContext(ctx, query, append(args, MaxPins)...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		pin := &VillaPin{}
+		rowUrls := []uint8{}
+
+		if err := rows.Scan(&pin.Id, &pin.Name, &pin.Price, &pin.Latitude, &pin.Longitude, &rowUrls); err != nil {
+			return nil, err
+		}
+
+		images := []string{}
+		if err := json.Unmarshal(rowUrls, &images); err != nil {
+			return nil, err
+		}
+
+		if len(images) > 0 {
+			pin.Cover = images[0]
+		}
+
+		pins = append(pins, pin)
+	}
+
+	return pins, rows.Err()
+}
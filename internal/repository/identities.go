@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+)
+
+type IdentitiesRepository struct {
+	db *sql.DB
+}
+
+// Identity links an account at an external OpenID provider to a user.
+type Identity struct {
+	Provider  string `json:"provider"`
+	Subject   string `json:"subject"`
+	UserId    int    `json:"user_id"`
+	Email     string `json:"email"`
+	CreatedAt string `json:"created_at"`
+}
+
+func (i *IdentitiesRepository) GetUserID(ctx context.Context, provider, subject string) (int, error) {
+	query := `SELECT user_id FROM user_identities WHERE provider = ? AND subject = ?`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var userId int
+	err := i.db.QueryRowContext(ctx, query, provider, subject).Scan(&userId)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return 0, ErrNoRows
+		default:
+			return 0, err
+		}
+	}
+
+	return userId, nil
+}
+
+func (i *IdentitiesRepository) Link(ctx context.Context, identity *Identity) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return i.linkWithTx(ctx, i.db, identity)
+}
+
+// CreateUserWithIdentity registers an active user, the provider already verified the email.
+func (i *IdentitiesRepository) CreateUserWithIdentity(ctx context.Context, user *User, identity *Identity) error {
+	return withTx(i.db, ctx, func(tx *sql.Tx) error {
+		users := &UserRepository{i.db}
+
+		if err := users.CreateWithTx(ctx, tx, user); err != nil {
+			return err
+		}
+
+		user.IsActive = true
+		if err := users.UpdateWithTx(ctx, tx, user); err != nil {
+			return err
+		}
+
+		identity.UserId = user.Id
+
+		return i.linkWithTx(ctx, tx, identity)
+	})
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func (i *IdentitiesRepository) linkWithTx(ctx context.Context, db execer, identity *Identity) error {
+	query := `INSERT INTO user_identities(provider, subject, user_id, email) VALUES(?,?,?,?)`
+
+	_, err := db.ExecContext(ctx, query, identity.Provider, identity.Subject, identity.UserId, identity.Email)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"strings"
+
+	"github.com/faizisyellow/gobali/internal/listquery"
+	"github.com/faizisyellow/gobali/internal/pagination"
 )
 
 type LocationsRepository struct {
@@ -11,10 +14,12 @@ type LocationsRepository struct {
 }
 
 type Location struct {
-	Id        int    `json:"id"`
-	Area      string `json:"area"`
-	CreatedAt string `json:"created_at"`
-	UpdateAt  string `json:"updated_at"`
+	Id        int      `json:"id"`
+	Area      string   `json:"area"`
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+	CreatedAt string   `json:"created_at"`
+	UpdateAt  string   `json:"updated_at"`
 }
 
 type SelectedLocation struct {
@@ -22,34 +27,39 @@ type SelectedLocation struct {
 	Area string `json:"area"`
 }
 
-func (l *LocationsRepository) Create(ctx context.Context, area string) error {
-	query := `INSERT INTO locations(area) VALUE(?)`
+func (l *LocationsRepository) Create(ctx context.Context, location *Location) (int, error) {
+	query := `INSERT INTO locations(area, latitude, longitude) VALUE(?, ?, ?)`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	_, err := l.db.ExecContext(ctx, query, area)
+	res, err := l.db.ExecContext(ctx, query, location.Area, location.Latitude, location.Longitude)
 	if err != nil {
 		duplicateKey := "Error 1062"
 		switch {
 		case strings.Contains(err.Error(), duplicateKey):
-			return ErrDuplicateLocation
+			return 0, ErrDuplicateLocation
 		default:
-			return err
+			return 0, err
 		}
 	}
 
-	return nil
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
 }
 
 func (l *LocationsRepository) GetByID(ctx context.Context, id int) (*Location, error) {
-	query := `SELECT id, area, created_at, updated_at FROM locations WHERE id = ?`
+	query := `SELECT id, area, latitude, longitude, created_at, updated_at FROM locations WHERE id = ?`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
 	location := &Location{}
-	err := l.db.QueryRowContext(ctx, query, id).Scan(&location.Id, &location.Area, &location.CreatedAt, &location.UpdateAt)
+	err := l.db.QueryRowContext(ctx, query, id).Scan(&location.Id, &location.Area, &location.Latitude, &location.Longitude, &location.CreatedAt, &location.UpdateAt)
 	if err != nil {
 		switch err {
 		case sql.ErrNoRows:
@@ -62,41 +72,54 @@ func (l *LocationsRepository) GetByID(ctx context.Context, id int) (*Location, e
 	return location, nil
 }
 
-func (l *LocationsRepository) GetLocations(ctx context.Context, qp PaginatedLocationQuery) ([]*Location, error) {
-	query := `SELECT id, area, created_at, updated_at FROM locations ORDER BY created_at ` + qp.Sort + ` LIMIT ? OFFSET ?`
+func (l *LocationsRepository) GetLocations(ctx context.Context, qp listquery.Query) ([]*Location, pagination.Page, error) {
+	where, args := qp.Where()
+
+	query := `SELECT id, area, latitude, longitude, created_at, updated_at FROM locations WHERE ` + where + ` ORDER BY ` + qp.OrderBy(false) + ` LIMIT ? OFFSET ?`
+
+	total, err := countRows(ctx, l.db, `SELECT COUNT(*) FROM locations WHERE `+where, args...)
+	if err != nil {
+		return nil, pagination.Page{}, err
+	}
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	rows, err := l.db.QueryContext(ctx, query, qp.Limit, qp.Offset)
+	rows, err := l.db.QueryContext(ctx, query, append(args, qp.Limit, qp.Offset)...)
 	if err != nil {
-		return nil, err
+		return nil, pagination.Page{}, err
 	}
 
+	defer rows.Close()
+
 	locations := []*Location{}
 
 	for rows.Next() {
 		location := &Location{}
 
-		err := rows.Scan(&location.Id, &location.Area, &location.CreatedAt, &location.UpdateAt)
+		err := rows.Scan(&location.Id, &location.Area, &location.Latitude, &location.Longitude, &location.CreatedAt, &location.UpdateAt)
 		if err != nil {
-			return nil, err
+			return nil, pagination.Page{}, err
 		}
 
 		locations = append(locations, location)
 	}
 
-	return locations, nil
+	if err := rows.Err(); err != nil {
+		return nil, pagination.Page{}, err
+	}
+
+	return locations, qp.Page(total), nil
 
 }
 
 func (l *LocationsRepository) Update(ctx context.Context, location *Location) error {
-	query := `UPDATE locations SET area = ? WHERE id = ?`
+	query := `UPDATE locations SET area = ?, latitude = ?, longitude = ? WHERE id = ?`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	_, err := l.db.ExecContext(ctx, query, &location.Area, &location.Id)
+	_, err := l.db.ExecContext(ctx, query, &location.Area, location.Latitude, location.Longitude, &location.Id)
 	if err != nil {
 		duplicateKey := "Error 1062"
 		switch {
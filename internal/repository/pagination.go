@@ -1,256 +1,197 @@
 package repository
 
 import (
-	"net/http"
+	"fmt"
+	"net/url"
 	"strconv"
-)
-
-type PaginatedVillaQuery struct {
-	Limit    int    `json:"limit" validate:"gte=1,lte=10"`
-	Offset   int    `json:"offset" validate:"gte=0"`
-	Sort     string `json:"sort" validate:"oneof=asc desc"`
-	Location string `json:"location"`
-	Category string `json:"category"`
-	MinGuest string `json:"min_guest"`
-	Bedrooms string `json:"bedrooms"`
-}
-
-func (pv PaginatedVillaQuery) Parse(r *http.Request) (PaginatedVillaQuery, error) {
-	qs := r.URL.Query()
-
-	limit := qs.Get("limit")
-	if limit != "" {
-		l, err := strconv.Atoi(limit)
-		if err != nil {
-			return pv, err
-		}
-
-		pv.Limit = l
-	}
-
-	offset := qs.Get("offset")
-	if offset != "" {
-		of, err := strconv.Atoi(offset)
-		if err != nil {
-			return pv, err
-		}
-
-		pv.Offset = of
-	}
-
-	sort := qs.Get("sort")
-	if sort != "" {
-		pv.Sort = sort
-	}
-
-	location := qs.Get("location")
-	if location != "" {
-		pv.Location = location
-	}
-
-	category := qs.Get("category")
-	if category != "" {
-		pv.Category = category
-	}
-
-	bedrooms := qs.Get("bedrooms")
-	if bedrooms != "" {
-		pv.Bedrooms = bedrooms
-	}
-
-	minGuest := qs.Get("min_guest")
-	if minGuest != "" {
-		pv.MinGuest = minGuest
-	}
-
-	return pv, nil
-}
-
-type PaginatedLocationQuery struct {
-	Limit  int    `json:"limit" validate:"gte=1,lte=10"`
-	Offset int    `json:"offset" validate:"gte=0"`
-	Sort   string `json:"sort" validate:"oneof=asc desc"`
-}
-
-func (pl PaginatedLocationQuery) Parse(r *http.Request) (PaginatedLocationQuery, error) {
-	qs := r.URL.Query()
-
-	limit := qs.Get("limit")
-	if limit != "" {
-		l, err := strconv.Atoi(limit)
-		if err != nil {
-			return pl, err
-		}
 
-		pl.Limit = l
-	}
-
-	offset := qs.Get("offset")
-	if offset != "" {
-		of, err := strconv.Atoi(offset)
-		if err != nil {
-			return pl, err
-		}
-
-		pl.Offset = of
-	}
-
-	sort := qs.Get("sort")
-	if sort != "" {
-		pl.Sort = sort
-	}
-
-	return pl, nil
-}
-
-type PaginatedCategoriesQuery struct {
-	Limit  int    `json:"limit" validate:"gte=1,lte=10"`
-	Offset int    `json:"offset" validate:"gte=0"`
-	Sort   string `json:"sort" validate:"oneof=asc desc"`
-}
-
-func (pc PaginatedCategoriesQuery) Parse(r *http.Request) (PaginatedCategoriesQuery, error) {
-
-	qs := r.URL.Query()
-
-	limit := qs.Get("limit")
-	if limit != "" {
-		l, err := strconv.Atoi(limit)
-		if err != nil {
-			return pc, err
-		}
-
-		pc.Limit = l
-	}
-
-	offset := qs.Get("offset")
-	if offset != "" {
-		of, err := strconv.Atoi(offset)
-		if err != nil {
-			return pc, err
-		}
-
-		pc.Offset = of
-	}
+	"github.com/faizisyellow/gobali/internal/listquery"
+)
 
-	sort := qs.Get("sort")
-	if sort != "" {
-		pc.Sort = sort
+// The specs of the lists, the sort fields and the filters of a list are the only names
+// of the query string that reach its SQL.
+var (
+	VillasList = listquery.Spec{
+		DefaultLimit: 5,
+		MaxLimit:     10,
+		Sorts: map[string]string{
+			"newest":   "v.created_at",
+			"price":    "v.price",
+			"bedrooms": "v.bedrooms",
+			// ordered by the repository
+			"relevance": "",
+			"distance":  "",
+		},
+		DefaultSort: "newest",
+		TieBreaker:  "v.id",
+		Filters: map[string]listquery.Filter{
+			"location":  {Column: "l.area", Op: listquery.AnyLike},
+			"category":  {Column: "c.name", Op: listquery.AnyLike},
+			"bedrooms":  {Column: "v.bedrooms", Type: listquery.Int, Min: listquery.Ptr(1)},
+			"min_guest": {Column: "v.min_guest", Type: listquery.Int, Min: listquery.Ptr(1)},
+			"price_min": {Column: "v.price", Type: listquery.Float, Op: listquery.Gte, Min: listquery.Ptr(0)},
+			"price_max": {Column: "v.price", Type: listquery.Float, Op: listquery.Lte, Min: listquery.Ptr(0)},
+			// the villa must have all of them, see villaFilters
+			"amenities": {Type: listquery.Int, Op: listquery.In},
+			// the public sees the published villas alone, see villaFilters
+			"status": {Column: "v.status", Op: listquery.In},
+		},
+	}
+
+	LocationsList = listquery.Spec{
+		DefaultLimit: 10,
+		MaxLimit:     10,
+		Sorts:        map[string]string{"created_at": "created_at", "area": "area"},
+		DefaultSort:  "created_at",
+		TieBreaker:   "id",
+		Filters: map[string]listquery.Filter{
+			"area": {Column: "area", Op: listquery.Like},
+		},
+	}
+
+	CategoriesList = listquery.Spec{
+		DefaultLimit: 10,
+		MaxLimit:     10,
+		Sorts:        map[string]string{"created_at": "created_at", "name": "name"},
+		DefaultSort:  "created_at",
+		TieBreaker:   "id",
+		Filters: map[string]listquery.Filter{
+			"name": {Column: "name", Op: listquery.Like},
+		},
+	}
+
+	AmenitiesList = listquery.Spec{
+		DefaultLimit: 10,
+		MaxLimit:     10,
+		Sorts:        map[string]string{"created_at": "a.created_at", "name": "a.name"},
+		DefaultSort:  "created_at",
+		TieBreaker:   "a.id",
+		Filters: map[string]listquery.Filter{
+			"name":    {Column: "a.name", Op: listquery.Like},
+			"type_id": {Column: "a.type_id", Type: listquery.Int},
+		},
+	}
+
+	BookingsList = listquery.Spec{
+		DefaultLimit: 5,
+		MaxLimit:     10,
+		Sorts:        map[string]string{"created_at": "created_at", "start_at": "start_at", "total_price": "total_price"},
+		DefaultSort:  "created_at",
+		TieBreaker:   "id",
+		Filters: map[string]listquery.Filter{
+			"status":   {Column: "status", Op: listquery.In},
+			"villa_id": {Column: "villa_id", Type: listquery.Int},
+			"from":     {Column: "start_at", Type: listquery.Date, Op: listquery.Gte},
+			"to":       {Column: "start_at", Type: listquery.Date, Op: listquery.UntilDay},
+		},
+	}
+
+	UserBookingsList = listquery.Spec{
+		DefaultLimit: 6,
+		MaxLimit:     10,
+		Sorts:        map[string]string{"created_at": "b.created_at", "start_at": "b.start_at"},
+		DefaultSort:  "created_at",
+		TieBreaker:   "b.id",
+		Filters: map[string]listquery.Filter{
+			"status": {Column: "b.status", Op: listquery.In},
+		},
+	}
+
+	UsersList = listquery.Spec{
+		DefaultLimit: 20,
+		MaxLimit:     50,
+		Sorts:        map[string]string{"created_at": "u.created_at", "email": "u.email"},
+		DefaultSort:  "-created_at",
+		TieBreaker:   "u.id",
+		Filters: map[string]listquery.Filter{
+			"role":   {Column: "r.name"},
+			"active": {Column: "u.is_active", Type: listquery.Bool},
+			"search": {Column: "u.email", Op: listquery.Like},
+		},
+	}
+
+	AuditList = listquery.Spec{
+		DefaultLimit: 20,
+		MaxLimit:     100,
+		Sorts:        map[string]string{"created_at": "created_at"},
+		DefaultSort:  "-created_at",
+		TieBreaker:   "id",
+		Filters: map[string]listquery.Filter{
+			"actor_id":  {Column: "actor_id", Type: listquery.Int},
+			"action":    {Column: "action"},
+			"entity":    {Column: "entity"},
+			"entity_id": {Column: "entity_id", Type: listquery.Int},
+			"from":      {Column: "created_at", Type: listquery.Date, Op: listquery.Gte},
+			"to":        {Column: "created_at", Type: listquery.Date, Op: listquery.UntilDay},
+		},
 	}
+)
 
-	return pc, nil
-}
-
-type PaginatedAmenitiesQuery struct {
-	Limit  int    `json:"limit" validate:"gte=1,lte=10"`
-	Offset int    `json:"offset" validate:"gte=0"`
-	Sort   string `json:"sort" validate:"oneof=asc desc"`
+// PaginatedVillaQuery is the list query of the villas with the filters SQL conditions alone can not express.
+type PaginatedVillaQuery struct {
+	listquery.Query
+	Search string `json:"q" validate:"max=200"`
+	// Near and RadiusKm keep the villas within the radius of the point
+	Near     *GeoPoint  `json:"near"`
+	RadiusKm float64    `json:"radius_km" validate:"gte=0,lte=50"`
+	Bounds   *GeoBounds `json:"bbox"`
+	// Ids restricts the villas to the search hits, in the order of relevance
+	Ids []int `json:"-"`
+	// All lists the villas of every status, not only the ones the public sees
+	All bool `json:"-"`
 }
 
-func (pa PaginatedAmenitiesQuery) Parse(r *http.Request) (PaginatedAmenitiesQuery, error) {
-
-	qs := r.URL.Query()
-
-	limit := qs.Get("limit")
-	if limit != "" {
-		l, err := strconv.Atoi(limit)
-		if err != nil {
-			return pa, err
-		}
-
-		pa.Limit = l
+func ParseVillaQuery(values url.Values) (PaginatedVillaQuery, error) {
+	query, err := VillasList.Parse(values)
+	if err != nil {
+		return PaginatedVillaQuery{}, err
 	}
 
-	offset := qs.Get("offset")
-	if offset != "" {
-		of, err := strconv.Atoi(offset)
-		if err != nil {
-			return pa, err
-		}
+	pv := PaginatedVillaQuery{Query: query, Search: values.Get("q")}
 
-		pa.Offset = of
-	}
-
-	sort := qs.Get("sort")
-	if sort != "" {
-		pa.Sort = sort
-	}
-
-	return pa, nil
-}
-
-type PaginatedBookingsQuery struct {
-	Limit  int    `json:"limit" validate:"gte=1,lte=10"`
-	Offset int    `json:"offset" validate:"gte=0"`
-	Sort   string `json:"sort" validate:"oneof=asc desc"`
-}
-
-func (pb PaginatedBookingsQuery) Parse(r *http.Request) (PaginatedBookingsQuery, error) {
-
-	qs := r.URL.Query()
-
-	limit := qs.Get("limit")
-	if limit != "" {
-		l, err := strconv.Atoi(limit)
+	if near := values.Get("near"); near != "" {
+		point, err := ParseGeoPoint(near)
 		if err != nil {
-			return pb, err
+			return pv, err
 		}
 
-		pb.Limit = l
+		pv.Near = point
 	}
 
-	offset := qs.Get("offset")
-	if offset != "" {
-		of, err := strconv.Atoi(offset)
+	if radius := values.Get("radius_km"); radius != "" {
+		r, err := strconv.ParseFloat(radius, 64)
 		if err != nil {
-			return pb, err
+			return pv, fmt.Errorf("%w: radius_km must be a number", listquery.ErrInvalidQuery)
 		}
 
-		pb.Offset = of
+		pv.RadiusKm = r
 	}
 
-	sort := qs.Get("sort")
-	if sort != "" {
-		pb.Sort = sort
-	}
-
-	return pb, nil
-}
-
-type PaginatedUserBookingsQuery struct {
-	Limit  int    `json:"limit" validate:"gte=1,lte=10"`
-	Offset int    `json:"offset" validate:"gte=0"`
-	Sort   string `json:"sort" validate:"oneof=asc desc"`
-}
-
-func (pb PaginatedUserBookingsQuery) Parse(r *http.Request) (PaginatedUserBookingsQuery, error) {
-
-	qs := r.URL.Query()
-
-	limit := qs.Get("limit")
-	if limit != "" {
-		l, err := strconv.Atoi(limit)
+	if bbox := values.Get("bbox"); bbox != "" {
+		bounds, err := ParseGeoBounds(bbox)
 		if err != nil {
-			return pb, err
+			return pv, err
 		}
 
-		pb.Limit = l
+		pv.Bounds = bounds
 	}
 
-	offset := qs.Get("offset")
-	if offset != "" {
-		of, err := strconv.Atoi(offset)
-		if err != nil {
-			return pb, err
+	// the best match comes first unless another sort field is asked for,
+	// without a search the nearest villa comes first
+	if sort := values.Get("sort"); values.Get("sort_by") == "" && (sort == "" || sort == "asc" || sort == "desc") {
+		switch {
+		case pv.Search != "":
+			pv.Sorts = []listquery.Sort{{Field: "relevance"}}
+		case pv.Near != nil:
+			pv.Sorts = []listquery.Sort{{Field: "distance"}}
 		}
-
-		pb.Offset = of
 	}
 
-	sort := qs.Get("sort")
-	if sort != "" {
-		pb.Sort = sort
+	priceMin, _ := listquery.Value[float64](pv.Query, "price_min")
+	priceMax, ok := listquery.Value[float64](pv.Query, "price_max")
+	if ok && priceMin > priceMax {
+		return pv, fmt.Errorf("%w: price_min is greater than price_max", listquery.ErrInvalidQuery)
 	}
 
-	return pb, nil
+	return pv, nil
 }
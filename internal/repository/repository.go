@@ -5,6 +5,9 @@ import (
 	"database/sql"
 	"errors"
 	"time"
+
+	"github.com/faizisyellow/gobali/internal/listquery"
+	"github.com/faizisyellow/gobali/internal/pagination"
 )
 
 var (
@@ -19,6 +22,10 @@ var (
 	ErrCatOrLocNotExist      = errors.New("category or location not exist")
 	ErrNoRows                = errors.New("records not found")
 	ErrDuplicateVilla        = errors.New("villa already exist")
+	ErrDuplicateRole         = errors.New("role already exist")
+	ErrRoleInUse             = errors.New("role is still assigned to users")
+	ErrPermissionNotExist    = errors.New("permission not exist")
+	ErrLastRolesManager      = errors.New("some role must keep the roles:manage permission")
 	QueryTimeoutDuration     = 5 * time.Second
 )
 
@@ -27,60 +34,116 @@ type Repository struct {
 		Create(context.Context, *User) error
 		CreateWithTx(context.Context, *sql.Tx, *User) error
 		CreateAndInvite(ctx context.Context, user *User, token string, invitationExp time.Duration) error
-		Delete(context.Context, int) error
+		Delete(ctx context.Context, userId int, event *AuditEvent) error
 		Activate(context.Context, string) error
 		GetUserInvitation(ctx context.Context, tx *sql.Tx, token string) (*User, error)
 		UpdateWithTx(ctx context.Context, tx *sql.Tx, user *User) error
 		GetUserByEmail(ctx context.Context, email string) (user *User, err error)
 		GetByID(ctx context.Context, userId int) (*User, error)
-		GetUserBookings(ctx context.Context, userId int, pq PaginatedUserBookingsQuery) (*User, error)
+		GetUserBookings(ctx context.Context, userId int, pq listquery.Query) (*User, pagination.Page, error)
+		GetAccountByID(ctx context.Context, userId int) (*User, error)
+		GetUsers(ctx context.Context, pq listquery.Query) ([]*User, pagination.Page, error)
+		UpdateRole(ctx context.Context, userId, roleId int) error
+		SetActive(ctx context.Context, userId int, active bool) error
+		UpdateProfile(context.Context, *User) error
+		CreateEmailChange(ctx context.Context, userId int, newEmail, token string, exp time.Duration) error
+		ConfirmEmailChange(ctx context.Context, token string) error
 	}
 	Roles interface {
 		Create(context.Context, *Role) error
 		GetByName(context.Context, string) (*Role, error)
+		GetByID(context.Context, int) (*Role, error)
+		GetRoles(context.Context) ([]*Role, error)
+		Update(context.Context, *Role) error
+		Delete(context.Context, int) error
+		SetPermissions(ctx context.Context, roleId int, permissions []string) error
+		GetPermissions(context.Context) ([]*Permission, error)
 	}
 	Categories interface {
-		Create(ctx context.Context, name string) error
+		Create(ctx context.Context, name string) (int, error)
 		GetByID(ctx context.Context, id int) (*Category, error)
-		GetCategories(ctx context.Context, query PaginatedCategoriesQuery) ([]*Category, error)
+		GetCategories(ctx context.Context, query listquery.Query) ([]*Category, pagination.Page, error)
 		Update(ctx context.Context, category *Category) error
 		Delete(ctx context.Context, id int) error
 	}
 	Location interface {
-		Create(ctx context.Context, area string) error
+		Create(ctx context.Context, location *Location) (int, error)
 		GetByID(ctx context.Context, id int) (*Location, error)
-		GetLocations(ctx context.Context, query PaginatedLocationQuery) ([]*Location, error)
+		GetLocations(ctx context.Context, query listquery.Query) ([]*Location, pagination.Page, error)
 		Update(ctx context.Context, location *Location) error
 		Delete(ctx context.Context, id int) error
 	}
 	Types interface {
-		Create(ctx context.Context, name string) error
+		Create(ctx context.Context, name string) (int, error)
 		GetByID(ctx context.Context, id int) (*Type, error)
 		GetTypes(ctx context.Context) ([]*Type, error)
 		Update(ctx context.Context, Type *Type) error
 		Delete(ctx context.Context, id int) error
 	}
 	Amenities interface {
-		Create(ctx context.Context, name string, typeID int) error
+		Create(ctx context.Context, name string, typeID int) (int, error)
 		GetByID(ctx context.Context, id int) (*Amenity, error)
-		GetAmenities(ctx context.Context, query PaginatedAmenitiesQuery) ([]*Amenity, error)
+		GetAmenities(ctx context.Context, query listquery.Query) ([]*Amenity, pagination.Page, error)
 		Update(ctx context.Context, Amenity *Amenity) error
 		Delete(ctx context.Context, id int) error
 	}
 	Villas interface {
-		CreateVillaWithAmenity(ctx context.Context, payload *Villa) error
+		CreateVillaWithAmenity(ctx context.Context, payload *Villa, store BlobStore) error
 		GetById(ctx context.Context, id int) (*Villa, error)
-		GetVillas(ctx context.Context, pq PaginatedVillaQuery) ([]*Villa, error)
-		Delete(ctx context.Context, id int) error
-		Update(ctx context.Context, villa *Villa) error
+		GetVillas(ctx context.Context, pq PaginatedVillaQuery) ([]*Villa, pagination.Page, error)
+		GetPins(ctx context.Context, pq PaginatedVillaQuery) ([]*VillaPin, error)
+		GetFacets(ctx context.Context, pq PaginatedVillaQuery) (*VillaFacets, error)
+		Delete(ctx context.Context, id int, store BlobStore) error
+		Update(ctx context.Context, villa *Villa, store BlobStore) error
+		UpdateStatus(ctx context.Context, id int, from, to string, publishAt *string) error
+		GetImageNames(ctx context.Context) ([]string, error)
+		UpdateImages(ctx context.Context, villaId int, variants map[string]map[string]string, store BlobStore, update func(images []string) ([]string, error)) ([]string, error)
 	}
 	Bookings interface {
 		UpdateBookingStatus(ctx context.Context, bookId int, status string) error
 		Create(context.Context, *Booking) error
 		GetById(context.Context, int) (*Booking, error)
-		GetBookings(context.Context, PaginatedBookingsQuery) ([]*Booking, error)
+		GetBookings(context.Context, listquery.Query) ([]*Booking, pagination.Page, error)
 		Delete(context.Context, int) error
 		GetBookingVillaByDate(ctx context.Context, startAt, endAt string, villaId int) (*Booking, error)
+		GetByUserID(ctx context.Context, userId int) ([]*Booking, error)
+		AddDocuments(ctx context.Context, bookingId int, names []string) error
+		GetDocuments(ctx context.Context, bookingId int) ([]*BookingDocument, error)
+		GetDocumentNames(ctx context.Context) ([]string, error)
+	}
+	ApiKeys interface {
+		Create(ctx context.Context, key *ApiKey, expiresAt *time.Time) error
+		GetActiveByPrefix(ctx context.Context, prefix string) (*ApiKey, error)
+		GetByID(ctx context.Context, id int) (*ApiKey, error)
+		GetApiKeys(ctx context.Context) ([]*ApiKey, error)
+		Revoke(ctx context.Context, id int) error
+		Touch(ctx context.Context, id int) error
+	}
+	Identities interface {
+		GetUserID(ctx context.Context, provider, subject string) (int, error)
+		Link(context.Context, *Identity) error
+		CreateUserWithIdentity(context.Context, *User, *Identity) error
+	}
+	Sessions interface {
+		Create(ctx context.Context, session *Session, expiresAt time.Time) error
+		GetActive(ctx context.Context, id string) (*Session, error)
+		GetByUserID(ctx context.Context, userId int) ([]*Session, error)
+		Revoke(ctx context.Context, userId int, id string) error
+		RevokeAll(ctx context.Context, userId int) error
+		Touch(ctx context.Context, id string) error
+	}
+	Consents interface {
+		GetByUserID(ctx context.Context, userId int) ([]*Consent, error)
+		Set(ctx context.Context, userId int, purpose string, granted bool) error
+	}
+	Blobs interface {
+		Discard(ctx context.Context, store BlobStore, names []string) error
+		RemoveUnreferenced(ctx context.Context, store BlobStore, name string) (bool, error)
+		GetReferenced(ctx context.Context) ([]string, error)
+	}
+	Audit interface {
+		Create(context.Context, *AuditEvent) error
+		GetEvents(context.Context, listquery.Query) ([]*AuditEvent, pagination.Page, error)
 	}
 }
 
@@ -94,6 +157,12 @@ func NewRepository(db *sql.DB) Repository {
 		Amenities:  &AmenitiesRepository{db},
 		Villas:     &VillasRepository{db},
 		Bookings:   &BookingsRepository{db},
+		Audit:      &AuditRepository{db},
+		Consents:   &ConsentsRepository{db},
+		ApiKeys:    &ApiKeysRepository{db},
+		Identities: &IdentitiesRepository{db},
+		Sessions:   &SessionsRepository{db},
+		Blobs:      &BlobsRepository{db},
 	}
 }
 
@@ -111,3 +180,16 @@ func withTx(db *sql.DB, ctx context.Context, fnc func(*sql.Tx) error) error {
 
 	return tx.Commit()
 }
+
+// countRows runs a COUNT query of the rows matching the filters of a list.
+func countRows(ctx context.Context, db *sql.DB, query string, args ...any) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var total int
+	if err := db.QueryRowContext(ctx, query, args...).Scan(&total); err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
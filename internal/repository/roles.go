@@ -3,15 +3,33 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"slices"
+	"strings"
 )
 
+// RolesManagePermission grants the management of the roles, some role must always hold it
+// or nobody could grant any permission anymore.
+const RolesManagePermission = "roles:manage"
+
 type Role struct {
+	Id          int      `json:"id"`
+	Name        string   `json:"name"`
+	Level       int      `json:"level"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+	CreatedAt   string   `json:"created_at"`
+	UpdateAt    string   `json:"update_at"`
+}
+
+type Permission struct {
 	Id          int    `json:"id"`
 	Name        string `json:"name"`
-	Level       int    `json:"level"`
 	Description string `json:"description"`
-	CreatedAt   string `json:"created_at"`
-	UpdateAt    string `json:"update_at"`
+}
+
+// HasPermission reports whether the role is granted the permission, e.g. "bookings:delete".
+func (r *Role) HasPermission(permission string) bool {
+	return slices.Contains(r.Permissions, permission)
 }
 
 type RolesRepository struct {
@@ -26,6 +44,41 @@ func (r *RolesRepository) GetByName(ctx context.Context, name string) (*Role, er
 
 	var role Role
 	err := r.db.QueryRowContext(ctx, query, name).Scan(&role.Id, &role.Name, &role.Level, &role.Description)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return nil, ErrNoRows
+		default:
+			return nil, err
+		}
+	}
+
+	role.Permissions, err = getRolePermissions(ctx, r.db, role.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &role, nil
+}
+
+func (r *RolesRepository) GetByID(ctx context.Context, id int) (*Role, error) {
+	query := `SELECT id, name, level, description FROM roles WHERE id = ?`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var role Role
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&role.Id, &role.Name, &role.Level, &role.Description)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return nil, ErrNoRows
+		default:
+			return nil, err
+		}
+	}
+
+	role.Permissions, err = getRolePermissions(ctx, r.db, role.Id)
 	if err != nil {
 		return nil, err
 	}
@@ -33,16 +86,240 @@ func (r *RolesRepository) GetByName(ctx context.Context, name string) (*Role, er
 	return &role, nil
 }
 
+func (r *RolesRepository) GetRoles(ctx context.Context) ([]*Role, error) {
+	query := `
+	SELECT r.id, r.name, r.level, r.description, COALESCE(GROUP_CONCAT(p.name ORDER BY p.name), '')
+	FROM roles r LEFT JOIN role_permissions rp ON rp.role_id = r.id LEFT JOIN permissions p ON p.id = rp.permission_id
+	GROUP BY r.id, r.name, r.level, r.description ORDER BY r.level, r.name
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	roles := []*Role{}
+
+	for rows.Next() {
+		role := &Role{}
+
+		var permissions string
+		err := rows.Scan(&role.Id, &role.Name, &role.Level, &role.Description, &permissions)
+		if err != nil {
+			return nil, err
+		}
+
+		role.Permissions = []string{}
+		if permissions != "" {
+			role.Permissions = strings.Split(permissions, ",")
+		}
+
+		roles = append(roles, role)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
 func (r *RolesRepository) Create(ctx context.Context, payload *Role) error {
-	query := `INSERT INTO roles(name, level, description) VALUES(?,?,?)`
+	return withTx(r.db, ctx, func(tx *sql.Tx) error {
+		query := `INSERT INTO roles(name, level, description) VALUES(?,?,?)`
+
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		res, err := tx.ExecContext(ctx, query, payload.Name, payload.Level, payload.Description)
+		if err != nil {
+			duplicateKey := "Error 1062"
+			switch {
+			case strings.Contains(err.Error(), duplicateKey):
+				return ErrDuplicateRole
+			default:
+				return err
+			}
+		}
+
+		id, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		payload.Id = int(id)
+
+		return r.setPermissionsWithTx(ctx, tx, payload.Id, payload.Permissions)
+	})
+}
+
+func (r *RolesRepository) Update(ctx context.Context, role *Role) error {
+	query := `UPDATE roles SET name = ?, level = ?, description = ? WHERE id = ?`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	_, err := r.db.ExecContext(ctx, query, payload.Name, payload.Level, payload.Description)
+	_, err := r.db.ExecContext(ctx, query, role.Name, role.Level, role.Description, role.Id)
+	if err != nil {
+		duplicateKey := "Error 1062"
+		switch {
+		case strings.Contains(err.Error(), duplicateKey):
+			return ErrDuplicateRole
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Delete removes the role with its permissions, ErrLastRolesManager when no role would hold RolesManagePermission.
+func (r *RolesRepository) Delete(ctx context.Context, id int) error {
+	return withTx(r.db, ctx, func(tx *sql.Tx) error {
+		query := `DELETE FROM roles WHERE id = ?`
+
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		_, err := tx.ExecContext(ctx, query, id)
+		if err != nil {
+			// users still reference the role
+			referenced := "Error 1451"
+			switch {
+			case strings.Contains(err.Error(), referenced):
+				return ErrRoleInUse
+			default:
+				return err
+			}
+		}
+
+		return checkRolesManagerWithTx(ctx, tx)
+	})
+}
+
+// SetPermissions replaces every permission of the role, ErrLastRolesManager when no role would hold RolesManagePermission.
+func (r *RolesRepository) SetPermissions(ctx context.Context, roleId int, permissions []string) error {
+	return withTx(r.db, ctx, func(tx *sql.Tx) error {
+		query := `DELETE FROM role_permissions WHERE role_id = ?`
+
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		if _, err := tx.ExecContext(ctx, query, roleId); err != nil {
+			return err
+		}
+
+		if err := r.setPermissionsWithTx(ctx, tx, roleId, permissions); err != nil {
+			return err
+		}
+
+		return checkRolesManagerWithTx(ctx, tx)
+	})
+}
+
+// checkRolesManagerWithTx fails with ErrLastRolesManager once no role holds RolesManagePermission.
+// The holders are locked, two roles giving it up at once can not both pass.
+func checkRolesManagerWithTx(ctx context.Context, tx *sql.Tx) error {
+	var holders int
+
+	err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM role_permissions rp JOIN permissions p ON p.id = rp.permission_id
+	WHERE p.name = ? FOR UPDATE`, RolesManagePermission).Scan(&holders)
 	if err != nil {
 		return err
 	}
 
+	if holders == 0 {
+		return ErrLastRolesManager
+	}
+
 	return nil
 }
+
+func (r *RolesRepository) setPermissionsWithTx(ctx context.Context, tx *sql.Tx, roleId int, permissions []string) error {
+	query := `INSERT INTO role_permissions(role_id, permission_id) SELECT ?, id FROM permissions WHERE name = ?`
+
+	unique := slices.Compact(slices.Sorted(slices.Values(permissions)))
+
+	for _, permission := range unique {
+		res, err := tx.ExecContext(ctx, query, roleId, permission)
+		if err != nil {
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		// nothing selected means the permission name is unknown
+		if affected == 0 {
+			return ErrPermissionNotExist
+		}
+	}
+
+	return nil
+}
+
+func (r *RolesRepository) GetPermissions(ctx context.Context) ([]*Permission, error) {
+	query := `SELECT id, name, description FROM permissions ORDER BY name`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	permissions := []*Permission{}
+
+	for rows.Next() {
+		permission := &Permission{}
+		if err := rows.Scan(&permission.Id, &permission.Name, &permission.Description); err != nil {
+			return nil, err
+		}
+
+		permissions = append(permissions, permission)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return permissions, nil
+}
+
+func getRolePermissions(ctx context.Context, db *sql.DB, roleId int) ([]string, error) {
+	query := `SELECT p.name FROM permissions p JOIN role_permissions rp ON rp.permission_id = p.id WHERE rp.role_id = ? ORDER BY p.name`
+
+	rows, err := db.QueryContext(ctx, query, roleId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	permissions := []string{}
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+
+		permissions = append(permissions, name)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return permissions, nil
+}
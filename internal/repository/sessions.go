@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// sessions and api keys are only touched once a minute, every authenticated request would write otherwise.
+const (
+	sessionTouchInterval = time.Minute
+	apiKeyTouchInterval  = time.Minute
+)
+
+type SessionsRepository struct {
+	db *sql.DB
+}
+
+// Session is a login of a user, the id is carried in the token as "sid".
+type Session struct {
+	Id             string  `json:"id"`
+	UserId         int     `json:"user_id"`
+	IP             string  `json:"ip"`
+	UserAgent      string  `json:"user_agent"`
+	CreatedAt      string  `json:"created_at"`
+	LastSeenAt     string  `json:"last_seen_at"`
+	ExpiresAt      string  `json:"expires_at"`
+	RevokedAt      *string `json:"revoked_at,omitempty"`
+	ImpersonatorId *int    `json:"impersonator_id,omitempty"`
+	Current        bool    `json:"current"`
+
+	lastSeen time.Time
+}
+
+func (s *SessionsRepository) Create(ctx context.Context, session *Session, expiresAt time.Time) error {
+	query := `INSERT INTO sessions(id, user_id, ip, user_agent, last_seen_at, expires_at, impersonator_id) VALUES(?,?,?,?,?,?,?)`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, query, session.Id, session.UserId, session.IP, session.UserAgent, time.Now(), expiresAt, session.ImpersonatorId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetActive returns the session if it is neither revoked nor expired.
+func (s *SessionsRepository) GetActive(ctx context.Context, id string) (*Session, error) {
+	query := `
+	SELECT id, user_id, ip, user_agent, created_at, last_seen_at, expires_at, revoked_at, impersonator_id
+	FROM sessions WHERE id = ? AND revoked_at IS NULL AND expires_at > ?
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	session, err := scanSession(s.db.QueryRowContext(ctx, query, id, time.Now()))
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return nil, ErrNoRows
+		default:
+			return nil, err
+		}
+	}
+
+	return session, nil
+}
+
+// GetByUserID returns the active sessions of the user, last seen first.
+func (s *SessionsRepository) GetByUserID(ctx context.Context, userId int) ([]*Session, error) {
+	query := `
+	SELECT id, user_id, ip, user_agent, created_at, last_seen_at, expires_at, revoked_at, impersonator_id
+	FROM sessions WHERE user_id = ? AND revoked_at IS NULL AND expires_at > ?
+	ORDER BY last_seen_at DESC
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, userId, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	sessions := []*Session{}
+
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// Revoke signs out one session of the user, ErrNoRows if the user has no such active session.
+func (s *SessionsRepository) Revoke(ctx context.Context, userId int, id string) error {
+	query := `UPDATE sessions SET revoked_at = ? WHERE id = ? AND user_id = ? AND revoked_at IS NULL`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, time.Now(), id, userId)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrNoRows
+	}
+
+	return nil
+}
+
+// RevokeAll signs out every session of the user.
+func (s *SessionsRepository) RevokeAll(ctx context.Context, userId int) error {
+	query := `UPDATE sessions SET revoked_at = ? WHERE user_id = ? AND revoked_at IS NULL`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, query, time.Now(), userId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *SessionsRepository) Touch(ctx context.Context, id string) error {
+	query := `UPDATE sessions SET last_seen_at = ? WHERE id = ? AND last_seen_at < ?`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	now := time.Now()
+
+	_, err := s.db.ExecContext(ctx, query, now, id, now.Add(-sessionTouchInterval))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// TouchDue tells whether the last seen time is old enough to be written again.
+func (s *Session) TouchDue() bool {
+	return time.Since(s.lastSeen) >= sessionTouchInterval
+}
+
+// dbTime reads a DATETIME as the time it is, the driver returns its text unless the DSN sets parseTime.
+// Touch writes it in UTC.
+type dbTime struct {
+	sql.NullTime
+}
+
+func (t *dbTime) Scan(value any) error {
+	switch v := value.(type) {
+	case []byte:
+		return t.parse(string(v))
+	case string:
+		return t.parse(v)
+	default:
+		return t.NullTime.Scan(value)
+	}
+}
+
+func (t *dbTime) parse(value string) error {
+	at, err := time.ParseInLocation(time.DateTime, value, time.UTC)
+	if err != nil {
+		return err
+	}
+
+	t.Time, t.Valid = at, true
+
+	return nil
+}
+
+// text formats the time like the DATETIME columns read as strings, nil when it is NULL.
+func (t dbTime) text() *string {
+	if !t.Valid {
+		return nil
+	}
+
+	text := t.Time.UTC().Format(time.DateTime)
+
+	return &text
+}
+
+func scanSession(row rowScanner) (*Session, error) {
+	session := &Session{}
+
+	var lastSeen dbTime
+
+	err := row.Scan(
+		&session.Id,
+		&session.UserId,
+		&session.IP,
+		&session.UserAgent,
+		&session.CreatedAt,
+		&lastSeen,
+		&session.ExpiresAt,
+		&session.RevokedAt,
+		&session.ImpersonatorId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	session.lastSeen = lastSeen.Time
+
+	if text := lastSeen.text(); text != nil {
+		session.LastSeenAt = *text
+	}
+
+	return session, nil
+}
@@ -21,24 +21,29 @@ type SelectedType struct {
 	Name string `json:"name"`
 }
 
-func (t *TypesRepository) Create(ctx context.Context, name string) error {
+func (t *TypesRepository) Create(ctx context.Context, name string) (int, error) {
 	query := `INSERT INTO types(name) VALUE(?)`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	_, err := t.db.ExecContext(ctx, query, &name)
+	res, err := t.db.ExecContext(ctx, query, &name)
 	if err != nil {
 		duplicateKey := "Error 1062"
 		switch {
 		case strings.Contains(err.Error(), duplicateKey):
-			return ErrDuplicateTypes
+			return 0, ErrDuplicateTypes
 		default:
-			return err
+			return 0, err
 		}
 	}
 
-	return nil
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
 }
 
 func (t *TypesRepository) GetByID(ctx context.Context, id int) (*Type, error) {
@@ -8,6 +8,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/faizisyellow/gobali/internal/listquery"
+	"github.com/faizisyellow/gobali/internal/pagination"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -16,16 +18,19 @@ type UserRepository struct {
 }
 
 type User struct {
-	Id        int          `json:"id"`
-	Username  string       `json:"username"`
-	Email     string       `json:"email"`
-	Password  HashPassword `json:"-"`
-	IsActive  bool         `json:"is_active"`
-	RoleId    int          `json:"role_id"`
-	Role      Role         `json:"role"`
-	CreatedAt string       `json:"created_at"`
-	UpdateAt  string       `json:"update_at"`
-	Bookings  []Booking    `json:"bookings"`
+	Id                int          `json:"id"`
+	Username          string       `json:"username"`
+	Email             string       `json:"email"`
+	Password          HashPassword `json:"-"`
+	FullName          *string      `json:"full_name"`
+	Phone             *string      `json:"phone"`
+	PreferredLanguage string       `json:"preferred_language"`
+	IsActive          bool         `json:"is_active"`
+	RoleId            int          `json:"role_id"`
+	Role              Role         `json:"role"`
+	CreatedAt         string       `json:"created_at"`
+	UpdateAt          string       `json:"update_at"`
+	Bookings          []Booking    `json:"bookings"`
 }
 
 type HashPassword struct {
@@ -65,7 +70,7 @@ func (u *UserRepository) Create(ctx context.Context, payload *User) error {
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	_, err := u.db.ExecContext(ctx, query, payload.Username, payload.Email, payload.Password.Hash, payload.Role.Name)
+	res, err := u.db.ExecContext(ctx, query, payload.Username, payload.Email, payload.Password.Hash, payload.Role.Name)
 	if err != nil {
 		switch err {
 		case sql.ErrNoRows:
@@ -75,6 +80,13 @@ func (u *UserRepository) Create(ctx context.Context, payload *User) error {
 		}
 	}
 
+	userId, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	payload.Id = int(userId)
+
 	return nil
 }
 
@@ -134,7 +146,7 @@ func (u *UserRepository) GetUserByEmail(ctx context.Context, email string) (*Use
 
 func (u *UserRepository) GetByID(ctx context.Context, userId int) (*User, error) {
 	query := `
-	SELECT users.id, username, email, password, role_id, roles.id, roles.name, roles.level, roles.description
+	SELECT users.id, username, email, password, full_name, phone, preferred_language, role_id, roles.id, roles.name, roles.level, roles.description
 	FROM users JOIN roles ON users.role_id = roles.id WHERE users.id = ? AND is_active = 1;
 	`
 
@@ -147,6 +159,9 @@ func (u *UserRepository) GetByID(ctx context.Context, userId int) (*User, error)
 	err := res.Scan(
 		&user.Id, &user.Username, &user.Email,
 		&user.Password.Hash,
+		&user.FullName,
+		&user.Phone,
+		&user.PreferredLanguage,
 		&user.RoleId,
 		&user.Role.Id,
 		&user.Role.Name,
@@ -162,6 +177,11 @@ func (u *UserRepository) GetByID(ctx context.Context, userId int) (*User, error)
 		}
 	}
 
+	user.Role.Permissions, err = getRolePermissions(ctx, u.db, user.Role.Id)
+	if err != nil {
+		return nil, err
+	}
+
 	return &user, nil
 }
 
@@ -265,9 +285,32 @@ func (u *UserRepository) DeleteUser(ctx context.Context, tx *sql.Tx, userId int)
 	return nil
 }
 
-func (u *UserRepository) Delete(ctx context.Context, userId int) error {
+// Delete removes the user and its personal data, the bookings are anonymized rather than removed.
+// The event is recorded before the user is gone, deleting the user clears it as the actor.
+// The personal data is erased from the audit log too, before the bookings are detached from the user.
+func (u *UserRepository) Delete(ctx context.Context, userId int, event *AuditEvent) error {
 	return withTx(u.db, ctx, func(tx *sql.Tx) error {
-		err := u.DeleteUser(ctx, tx, userId)
+		audit := &AuditRepository{u.db}
+
+		if event != nil {
+			if err := audit.CreateWithTx(ctx, tx, event); err != nil {
+				return err
+			}
+		}
+
+		err := audit.redactWithTx(ctx, tx, userId)
+		if err != nil {
+			return err
+		}
+
+		bookings := &BookingsRepository{u.db}
+
+		err = bookings.anonymizeWithTx(ctx, tx, userId)
+		if err != nil {
+			return err
+		}
+
+		err = u.DeleteUser(ctx, tx, userId)
 		if err != nil {
 			return err
 		}
@@ -303,18 +346,28 @@ func (u *UserRepository) Activate(ctx context.Context, token string) error {
 	})
 }
 
-func (u *UserRepository) GetUserBookings(ctx context.Context, userId int, pq PaginatedUserBookingsQuery) (*User, error) {
+func (u *UserRepository) GetUserBookings(ctx context.Context, userId int, pq listquery.Query) (*User, pagination.Page, error) {
+	// the filters are on the join so a user without matching bookings is still found
+	where, filterArgs := pq.Where()
+
 	query := `
-	SELECT u.id,u.email,b.villa_name,b.status, b.total_price, b.created_at,b.start_at,b.end_at FROM users u LEFT JOIN bookings b ON b.user_id = u.id
-	WHERE u.id = ?	ORDER BY b.created_at ` + pq.Sort + ` LIMIT ? OFFSET ?
+	SELECT u.id,u.email,b.villa_name,b.status, b.total_price, b.created_at,b.start_at,b.end_at FROM users u LEFT JOIN bookings b ON b.user_id = u.id AND ` + where + `
+	WHERE u.id = ?	ORDER BY ` + pq.OrderBy(false) + ` LIMIT ? OFFSET ?
 	`
 
+	args := append(filterArgs, userId)
+
+	total, err := countRows(ctx, u.db, `SELECT COUNT(*) FROM bookings b WHERE `+where+` AND b.user_id = ?`, args...)
+	if err != nil {
+		return nil, pagination.Page{}, err
+	}
+
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	rows, err := u.db.QueryContext(ctx, query, userId, pq.Limit, pq.Offset)
+	rows, err := u.db.QueryContext(ctx, query, append(args, pq.Limit, pq.Offset)...)
 	if err != nil {
-		return nil, err
+		return nil, pagination.Page{}, err
 	}
 	defer rows.Close()
 
@@ -334,7 +387,7 @@ func (u *UserRepository) GetUserBookings(ctx context.Context, userId int, pq Pag
 			&booking.EndAt,
 		)
 		if err != nil {
-			return nil, err
+			return nil, pagination.Page{}, err
 		}
 
 		if user.Id != 0 {
@@ -342,6 +395,216 @@ func (u *UserRepository) GetUserBookings(ctx context.Context, userId int, pq Pag
 		}
 	}
 
+	return &user, pq.Page(total), nil
+
+}
+
+// GetAccountByID returns the user whether it is active or not.
+func (u *UserRepository) GetAccountByID(ctx context.Context, userId int) (*User, error) {
+	query := `
+	SELECT users.id, username, email, full_name, phone, preferred_language, is_active, role_id, roles.id, roles.name, roles.level, roles.description,
+	users.created_at, users.update_at
+	FROM users JOIN roles ON users.role_id = roles.id WHERE users.id = ?
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	user := User{}
+	err := u.db.QueryRowContext(ctx, query, userId).Scan(
+		&user.Id,
+		&user.Username,
+		&user.Email,
+		&user.FullName,
+		&user.Phone,
+		&user.PreferredLanguage,
+		&user.IsActive,
+		&user.RoleId,
+		&user.Role.Id,
+		&user.Role.Name,
+		&user.Role.Level,
+		&user.Role.Description,
+		&user.CreatedAt,
+		&user.UpdateAt,
+	)
+	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return nil, ErrNoRows
+		default:
+			return nil, err
+		}
+	}
+
 	return &user, nil
+}
+
+func (u *UserRepository) GetUsers(ctx context.Context, pq listquery.Query) ([]*User, pagination.Page, error) {
+	where, args := pq.Where()
+
+	from := `
+	FROM users u JOIN roles r ON r.id = u.role_id
+	WHERE ` + where
+
+	query := `
+	SELECT u.id, u.username, u.email, u.full_name, u.phone, u.preferred_language, u.is_active, u.role_id, r.id, r.name, r.level, r.description,
+	u.created_at, u.update_at` + from + `
+	ORDER BY ` + pq.OrderBy(false) + ` LIMIT ? OFFSET ?
+	`
+
+	total, err := countRows(ctx, u.db, `SELECT COUNT(*)`+from, args...)
+	if err != nil {
+		return nil, pagination.Page{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := u.db.QueryContext(ctx, query, append(args, pq.Limit, pq.Offset)...)
+	if err != nil {
+		return nil, pagination.Page{}, err
+	}
+
+	defer rows.Close()
+
+	users := []*User{}
+
+	for rows.Next() {
+		user := &User{}
+
+		err := rows.Scan(
+			&user.Id,
+			&user.Username,
+			&user.Email,
+			&user.FullName,
+			&user.Phone,
+			&user.PreferredLanguage,
+			&user.IsActive,
+			&user.RoleId,
+			&user.Role.Id,
+			&user.Role.Name,
+			&user.Role.Level,
+			&user.Role.Description,
+			&user.CreatedAt,
+			&user.UpdateAt,
+		)
+		if err != nil {
+			return nil, pagination.Page{}, err
+		}
+
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, pagination.Page{}, err
+	}
+
+	return users, pq.Page(total), nil
+}
+
+func (u *UserRepository) UpdateRole(ctx context.Context, userId, roleId int) error {
+	query := `UPDATE users SET role_id = ? WHERE id = ?`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := u.db.ExecContext(ctx, query, roleId, userId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u *UserRepository) SetActive(ctx context.Context, userId int, active bool) error {
+	query := `UPDATE users SET is_active = ? WHERE id = ?`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := u.db.ExecContext(ctx, query, active, userId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u *UserRepository) UpdateProfile(ctx context.Context, user *User) error {
+	query := `UPDATE users SET username = ?, full_name = ?, phone = ?, preferred_language = ? WHERE id = ?`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := u.db.ExecContext(ctx, query, user.Username, user.FullName, user.Phone, user.PreferredLanguage, user.Id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CreateEmailChange replaces any pending change of the user, the email is swapped once the token is confirmed.
+func (u *UserRepository) CreateEmailChange(ctx context.Context, userId int, newEmail, token string, exp time.Duration) error {
+	return withTx(u.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		_, err := tx.ExecContext(ctx, `DELETE FROM email_changes WHERE user_id = ?`, userId)
+		if err != nil {
+			return err
+		}
+
+		query := `INSERT INTO email_changes(token, user_id, new_email, expire) VALUES(?,?,?,?)`
+
+		_, err = tx.ExecContext(ctx, query, token, userId, newEmail, time.Now().Add(exp))
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (u *UserRepository) ConfirmEmailChange(ctx context.Context, token string) error {
+	return withTx(u.db, ctx, func(tx *sql.Tx) error {
+		query := `SELECT user_id, new_email FROM email_changes WHERE token = ? AND expire > ?`
+
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		hash := sha256.Sum256([]byte(token))
+		hashToken := hex.EncodeToString(hash[:])
+
+		var userId int
+		var newEmail string
+
+		err := tx.QueryRowContext(ctx, query, hashToken, time.Now()).Scan(&userId, &newEmail)
+		if err != nil {
+			switch err {
+			case sql.ErrNoRows:
+				return ErrNoRows
+			default:
+				return err
+			}
+		}
 
+		_, err = tx.ExecContext(ctx, `UPDATE users SET email = ? WHERE id = ?`, newEmail, userId)
+		if err != nil {
+			duplicateKey := "Error 1062"
+			switch {
+			case strings.Contains(err.Error(), duplicateKey):
+				return ErrDuplicateEmail
+			default:
+				return err
+			}
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM email_changes WHERE user_id = ?`, userId)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
 }
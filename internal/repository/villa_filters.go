@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/faizisyellow/gobali/internal/listquery"
+	"github.com/faizisyellow/gobali/internal/pagination"
+)
+
+// priceBuckets of the price facet, the last one has no upper bound.
+var priceBuckets = []struct {
+	Label    string
+	Min, Max float64
+}{
+	{"0-100", 0, 100},
+	{"100-250", 100, 250},
+	{"250-500", 250, 500},
+	{"500-1000", 500, 1000},
+	{"1000+", 1000, 0},
+}
+
+type Facet struct {
+	Id    int    `json:"id,omitempty"`
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// VillaFacets count the villas matching the filters by each value a filter can take.
+// A facet ignores its own filter, so the other values of a multi-value filter keep their counts.
+type VillaFacets struct {
+	Categories []Facet `json:"categories"`
+	Locations  []Facet `json:"locations"`
+	Amenities  []Facet `json:"amenities"`
+	Prices     []Facet `json:"prices"`
+}
+
+// villaFilters builds the conditions on villas v joined with categories c and locations l,
+// the filters named by skip are left out for their facet.
+func villaFilters(vq PaginatedVillaQuery, skip ...string) (string, []any) {
+	where, args := vq.Where(append(skip, "amenities")...)
+	conds := []string{where}
+
+	// the villa must have every amenity
+	if amenities := listquery.Values[int](vq.Query, "amenities"); len(amenities) > 0 {
+		conds = append(conds, `v.id IN (
+			SELECT villa_id FROM villas_amenities WHERE amenity_id IN (`+listquery.Placeholders(len(amenities))+`)
+			GROUP BY villa_id HAVING COUNT(DISTINCT amenity_id) = ?)`)
+
+		for _, id := range amenities {
+			args = append(args, id)
+		}
+
+		args = append(args, len(amenities))
+	}
+
+	if !vq.All {
+		conds = append(conds, villaVisible)
+	}
+
+	geoConds, geoArgs := geoFilters(vq)
+	conds = append(conds, geoConds...)
+	args = append(args, geoArgs...)
+
+	if vq.Ids != nil {
+		conds = append(conds, "v.id IN ("+listquery.Placeholders(len(vq.Ids))+")")
+
+		for _, id := range vq.Ids {
+			args = append(args, id)
+		}
+	}
+
+	return strings.Join(conds, " AND "), args
+}
+
+// villaOrder sorts the villas of v joined with locations l, reversed the villas before a cursor come first.
+func villaOrder(vq PaginatedVillaQuery, reverse bool) (string, []any) {
+	order := vq.OrderBy(reverse)
+
+	switch vq.SortField() {
+	case "distance":
+		if vq.Near != nil {
+			dir := "asc"
+			if vq.Sorts[0].Desc != reverse {
+				dir = "desc"
+			}
+
+			distance, args := villaDistance(*vq.Near)
+			return distance + " " + dir + ", " + order, args
+		}
+	case "relevance":
+		if len(vq.Ids) > 0 {
+			args := []any{}
+			for _, id := range vq.Ids {
+				args = append(args, id)
+			}
+
+			return "FIELD(v.id, " + listquery.Placeholders(len(vq.Ids)) + "), " + order, args
+		}
+	}
+
+	return order, nil
+}
+
+// villaCursor points to the villa in the sort of the query.
+func villaCursor(vq PaginatedVillaQuery, villa *Villa, before bool) string {
+	value := villa.CreatedAt
+
+	switch vq.SortField() {
+	case "price":
+		value = strconv.FormatFloat(villa.Price, 'f', -1, 64)
+	case "bedrooms":
+		value = strconv.Itoa(villa.Bedrooms)
+	}
+
+	return pagination.Cursor{Value: value, Id: villa.Id, Before: before}.Encode()
+}
+
+func (v *VillasRepository) GetFacets(ctx context.Context, vq PaginatedVillaQuery) (*VillaFacets, error) {
+	facets := &VillaFacets{}
+
+	if vq.Ids != nil && len(vq.Ids) == 0 {
+		return facets, nil
+	}
+
+	from := `FROM villas v LEFT JOIN categories c ON c.id = v.category_id LEFT JOIN locations l ON l.id = v.location_id`
+
+	var err error
+
+	where, args := villaFilters(vq, "category")
+	facets.Categories, err = v.countFacet(ctx, `SELECT c.id, c.name, COUNT(*) `+from+` WHERE `+where+` AND c.id IS NOT NULL GROUP BY c.id, c.name ORDER BY COUNT(*) DESC, c.name`, args)
+	if err != nil {
+		return nil, err
+	}
+
+	where, args = villaFilters(vq, "location")
+	facets.Locations, err = v.countFacet(ctx, `SELECT l.id, l.area, COUNT(*) `+from+` WHERE `+where+` AND l.id IS NOT NULL GROUP BY l.id, l.area ORDER BY COUNT(*) DESC, l.area`, args)
+	if err != nil {
+		return nil, err
+	}
+
+	where, args = villaFilters(vq)
+	facets.Amenities, err = v.countFacet(ctx, `SELECT a.id, a.name, COUNT(DISTINCT v.id) `+from+`
+		JOIN villas_amenities va ON va.villa_id = v.id JOIN amenities a ON a.id = va.amenity_id
+		WHERE `+where+` GROUP BY a.id, a.name ORDER BY COUNT(DISTINCT v.id) DESC, a.name`, args)
+	if err != nil {
+		return nil, err
+	}
+
+	cases := []string{}
+	bucketArgs := []any{}
+	for i, bucket := range priceBuckets {
+		if bucket.Max == 0 {
+			cases = append(cases, "WHEN v.price >= ? THEN ?")
+			bucketArgs = append(bucketArgs, bucket.Min, i)
+			continue
+		}
+
+		cases = append(cases, "WHEN v.price >= ? AND v.price < ? THEN ?")
+		bucketArgs = append(bucketArgs, bucket.Min, bucket.Max, i)
+	}
+
+	where, args = villaFilters(vq, "price_min", "price_max")
+	prices, err := v.countFacet(ctx, `SELECT CASE `+strings.Join(cases, " ")+` END AS bucket, '', COUNT(*) `+from+` WHERE `+where+` GROUP BY bucket`, append(bucketArgs, args...))
+	if err != nil {
+		return nil, err
+	}
+
+	// every bucket is listed, the empty ones too
+	counts := map[int]int{}
+	for _, price := range prices {
+		counts[price.Id] = price.Count
+	}
+
+	for i, bucket := range priceBuckets {
+		facets.Prices = append(facets.Prices, Facet{Value: bucket.Label, Count: counts[i]})
+	}
+
+	return facets, nil
+}
+
+func (v *VillasRepository) countFacet(ctx context.Context, query string, args []any) ([]Facet, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := v.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	facets := []Facet{}
+
+	for rows.Next() {
+		facet := Facet{}
+		if err := rows.Scan(&facet.Id, &facet.Value, &facet.Count); err != nil {
+			return nil, err
+		}
+
+		facets = append(facets, facet)
+	}
+
+	return facets, rows.Err()
+}
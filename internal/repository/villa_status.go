@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"errors"
+)
+
+// The statuses of the publishing of a villa, a new villa starts as a draft.
+const (
+	VillaDraft     = "draft"
+	VillaInReview  = "in_review"
+	VillaPublished = "published"
+	VillaArchived  = "archived"
+)
+
+// villaVisible is the condition of the villas v the public sees.
+const villaVisible = "(v.status = 'published' AND (v.publish_at IS NULL OR v.publish_at <= UTC_TIMESTAMP()))"
+
+var ErrVillaStatusChanged = errors.New("villa status has changed")
+
+// UpdateStatus moves the villa from one status to another, a villa no longer in from is left as it is.
+// publishAt schedules a published villa in UTC, nil publishes it now.
+func (v *VillasRepository) UpdateStatus(ctx context.Context, id int, from, to string, publishAt *string) error {
+	query := `UPDATE villas SET status = ?, publish_at = ? WHERE id = ? AND status = ?`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := v.db.ExecContext(ctx, query, to, publishAt, id, from)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrVillaStatusChanged
+	}
+
+	return nil
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/log"
+	"github.com/faizisyellow/gobali/internal/pagination"
 )
 
 type VillasRepository struct {
@@ -27,13 +29,28 @@ type Villa struct {
 	Price       float64           `json:"price"`
 	Baths       int               `json:"baths"`
 	ImageUrls   []string          `json:"image_urls"`
-	CreatedAt   string            `json:"created_at"`
-	UpdateAt    string            `json:"updated_at"`
+	// Latitude and Longitude of the villa, without them the villa is placed at its location
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+	// ImageVariants maps each image to its resized copies, e.g. {"pool.jpg": {"thumb": "pool-thumb.jpg"}}
+	ImageVariants map[string]map[string]string `json:"image_variants"`
+	CreatedAt     string                       `json:"created_at"`
+	UpdateAt      string                       `json:"updated_at"`
+	// Snippet and Score of a search, the snippet marks the matched terms
+	Snippet string  `json:"snippet,omitempty"`
+	Score   float64 `json:"score,omitempty"`
+	// DistanceKm from the point of a near query
+	DistanceKm *float64 `json:"distance_km,omitempty"`
+	// Status of the publishing, a published villa is listed once its publish_at has passed
+	Status    string  `json:"status"`
+	PublishAt *string `json:"publish_at"`
+	// Visible tells whether the public sees the villa
+	Visible bool `json:"-"`
 }
 
 func (v *VillasRepository) Create(ctx context.Context, tx *sql.Tx, villa *Villa) (int64, error) {
-	query := `INSERT INTO villas(image_urls,name,description,category_id,location_id,min_guest,bedrooms,price,baths)
-	VALUES(?,?,?,?,?,?,?,?,?)`
+	query := `INSERT INTO villas(image_urls,image_variants,name,description,category_id,location_id,min_guest,bedrooms,price,baths,latitude,longitude)
+	VALUES(?,?,?,?,?,?,?,?,?,?,?,?)`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
@@ -43,8 +60,14 @@ func (v *VillasRepository) Create(ctx context.Context, tx *sql.Tx, villa *Villa)
 		return 0, err
 	}
 
+	variants, err := json.Marshal(villa.ImageVariants)
+	if err != nil {
+		return 0, err
+	}
+
 	res, err := tx.ExecContext(ctx, query,
 		images,
+		variants,
 		villa.Name,
 		villa.Description,
 		villa.CategoryId,
@@ -53,6 +76,8 @@ func (v *VillasRepository) Create(ctx context.Context, tx *sql.Tx, villa *Villa)
 		villa.Bedrooms,
 		villa.Price,
 		villa.Baths,
+		villa.Latitude,
+		villa.Longitude,
 	)
 
 	if err != nil {
@@ -99,7 +124,8 @@ func (v *VillasRepository) CreateVillasAmenities(ctx context.Context, tx *sql.Tx
 	return nil
 }
 
-func (v *VillasRepository) CreateVillaWithAmenity(ctx context.Context, payload *Villa) error {
+// CreateVillaWithAmenity counts the references of the images with the villa, before any of them can be released.
+func (v *VillasRepository) CreateVillaWithAmenity(ctx context.Context, payload *Villa, store BlobStore) error {
 	return withTx(v.db, ctx, func(tx *sql.Tx) error {
 
 		villaId, err := v.Create(ctx, tx, payload)
@@ -107,13 +133,17 @@ func (v *VillasRepository) CreateVillaWithAmenity(ctx context.Context, payload *
 			return err
 		}
 
+		payload.Id = int(villaId)
+
 		for _, amenity := range payload.Amenity {
 			if err := v.CreateVillasAmenities(ctx, tx, int(villaId), amenity.Id); err != nil {
 				return err
 			}
 		}
 
-		return nil
+		blobs := &BlobsRepository{v.db}
+
+		return blobs.retainWithTx(ctx, tx, store, payload.ImageUrls)
 	})
 }
 
@@ -130,6 +160,12 @@ func (v *VillasRepository) GetById(ctx context.Context, id int) (*Villa, error)
 		v.baths,
 		v.price,
 		v.image_urls,
+		v.image_variants,
+		v.latitude,
+		v.longitude,
+		v.status,
+		v.publish_at,
+		` + villaVisible + `,
 		c.id,
 		c.name,
 		l.id,
@@ -151,6 +187,7 @@ func (v *VillasRepository) GetById(ctx context.Context, id int) (*Villa, error)
 	villa := &Villa{}
 
 	rowUrls := []uint8{}
+	rowVariants := []uint8{}
 
 	rows, err := v.db.QueryContext(ctx, query, id)
 	if err != nil {
@@ -178,6 +215,12 @@ func (v *VillasRepository) GetById(ctx context.Context, id int) (*Villa, error)
 			&villa.Baths,
 			&villa.Price,
 			&rowUrls,
+			&rowVariants,
+			&villa.Latitude,
+			&villa.Longitude,
+			&villa.Status,
+			&villa.PublishAt,
+			&villa.Visible,
 			&villa.Category.Id,
 			&villa.Category.Name,
 			&villa.Location.Id,
@@ -204,10 +247,55 @@ func (v *VillasRepository) GetById(ctx context.Context, id int) (*Villa, error)
 		return nil, err
 	}
 
+	if err := unmarshalVariants(rowVariants, villa); err != nil {
+		return nil, err
+	}
+
 	return villa, nil
 }
 
-func (v *VillasRepository) GetVillas(ctx context.Context, vq PaginatedVillaQuery) ([]*Villa, error) {
+func (v *VillasRepository) GetVillas(ctx context.Context, vq PaginatedVillaQuery) ([]*Villa, pagination.Page, error) {
+	// the search found no villa
+	if vq.Ids != nil && len(vq.Ids) == 0 {
+		return []*Villa{}, pagination.New(vq.Limit, vq.Offset, 0), nil
+	}
+
+	where, whereArgs := villaFilters(vq)
+
+	total, err := countRows(ctx, v.db, `SELECT COUNT(*) FROM villas v
+		LEFT JOIN categories c ON c.id = v.category_id LEFT JOIN locations l ON l.id = v.location_id
+		WHERE `+where, whereArgs...)
+	if err != nil {
+		return nil, pagination.Page{}, err
+	}
+
+	// a keyset cursor pages from its villa, the sorts without a single column page by the offset
+	sortable := len(vq.Sorts) == 1 && VillasList.Sorts[vq.SortField()] != ""
+	cond, keysetArgs, keyset := vq.Keyset()
+	reverse := keyset && vq.Cursor.Before
+
+	offset := vq.Offset
+	if keyset {
+		where += " AND " + cond
+		whereArgs = append(whereArgs, keysetArgs...)
+		offset = 0
+	}
+
+	distance, args := "NULL", []any{}
+	if vq.Near != nil {
+		distance, args = villaDistance(*vq.Near)
+		distance += " / 1000"
+	}
+
+	innerOrder, innerArgs := villaOrder(vq, reverse)
+	order, orderArgs := villaOrder(vq, false)
+
+	// one more villa than the page tells whether another page follows
+	args = append(args, whereArgs...)
+	args = append(args, innerArgs...)
+	args = append(args, vq.Limit+1, offset)
+	args = append(args, orderArgs...)
+
 	query := `
 	SELECT 
 		v.id,
@@ -218,10 +306,16 @@ func (v *VillasRepository) GetVillas(ctx context.Context, vq PaginatedVillaQuery
 		v.baths,
 		v.price,
 		v.image_urls,
-		cat.id,
-		cat.name,
-		loc.id,
-		loc.area,
+		v.image_variants,
+		v.latitude,
+		v.longitude,
+		v.status,
+		v.publish_at,
+		` + distance + `,
+		c.id,
+		c.name,
+		l.id,
+		l.area,
 		am.id,
 		am.name,
 		tp.name,
@@ -229,37 +323,37 @@ func (v *VillasRepository) GetVillas(ctx context.Context, vq PaginatedVillaQuery
 		v.updated_at
 	FROM
 		(SELECT 
-			id,
-			created_at
+			v.id
 		FROM
-			villas
-		ORDER BY created_at ` + vq.Sort + `
+			villas v
+				LEFT JOIN
+			categories c ON c.id = v.category_id
+				LEFT JOIN
+			locations l ON l.id = v.location_id
+		WHERE ` + where + `
+		ORDER BY ` + innerOrder + `
 		LIMIT ? OFFSET ?) AS pg
 			JOIN
 		villas v ON pg.id = v.id
 			LEFT JOIN
-		categories cat ON cat.id = v.category_id
+		categories c ON c.id = v.category_id
 			LEFT JOIN
-		locations loc ON v.location_id = loc.id
+		locations l ON v.location_id = l.id
 			LEFT JOIN
 		villas_amenities ON v.id = villas_amenities.villa_id
 			LEFT JOIN
 		amenities am ON am.id = villas_amenities.amenity_id
 			LEFT JOIN
 		types tp ON tp.id = am.type_id
-	WHERE
-    loc.area LIKE concat("%",?,"%")
-        AND cat.name LIKE concat("%",?,"%") 
-        AND v.bedrooms LIKE concat("%",?,"%")
-        AND v.min_guest LIKE concat("%",?,"%");
+	ORDER BY ` + order + `;
 		`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	rows, err := v.db.QueryContext(ctx, query, vq.Limit, vq.Offset, vq.Location, vq.Category, vq.Bedrooms, vq.MinGuest)
+	rows, err := v.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, pagination.Page{}, err
 	}
 
 	defer rows.Close()
@@ -274,6 +368,7 @@ func (v *VillasRepository) GetVillas(ctx context.Context, vq PaginatedVillaQuery
 		amenity := &SelectedAmenity{}
 
 		rowUrls := []uint8{}
+		rowVariants := []uint8{}
 		err := rows.Scan(
 			&villa.Id,
 			&villa.Name,
@@ -283,6 +378,12 @@ func (v *VillasRepository) GetVillas(ctx context.Context, vq PaginatedVillaQuery
 			&villa.Baths,
 			&villa.Price,
 			&rowUrls,
+			&rowVariants,
+			&villa.Latitude,
+			&villa.Longitude,
+			&villa.Status,
+			&villa.PublishAt,
+			&villa.DistanceKm,
 			&villa.Category.Id,
 			&villa.Category.Name,
 			&villa.Location.Id,
@@ -295,76 +396,286 @@ func (v *VillasRepository) GetVillas(ctx context.Context, vq PaginatedVillaQuery
 		)
 
 		if err != nil {
-			return nil, err
+			return nil, pagination.Page{}, err
 		}
 
 		err = json.Unmarshal(rowUrls, &villa.ImageUrls)
 		if err != nil {
-			return nil, err
+			return nil, pagination.Page{}, err
+		}
+
+		if err := unmarshalVariants(rowVariants, villa); err != nil {
+			return nil, pagination.Page{}, err
 		}
 
 		// if the row not exist add not the map
 		if _, ok := villaMap[villa.Id]; !ok {
 			villaMap[villa.Id] = villa
+			villas = append(villas, villa)
 		}
 
 		// update the amenity with the result of the amenity row
 		villaMap[villa.Id].Amenity = append(villaMap[villa.Id].Amenity, *amenity)
 	}
 
-	// convert the map to slice
-	for _, val := range villaMap {
-		villas = append(villas, val)
+	if err := rows.Err(); err != nil {
+		return nil, pagination.Page{}, err
+	}
+
+	more := len(villas) > vq.Limit
+	if more && reverse {
+		villas = villas[1:]
+	} else if more {
+		villas = villas[:vq.Limit]
+	}
+
+	if !sortable {
+		return villas, pagination.New(vq.Limit, vq.Offset, total), nil
 	}
 
-	return villas, nil
+	page := pagination.Page{Total: total, Limit: vq.Limit, Offset: vq.Offset}
+
+	if len(villas) > 0 {
+		if more || reverse {
+			page.Next = villaCursor(vq, villas[len(villas)-1], false)
+		}
+
+		if (reverse && more) || (!reverse && (keyset || vq.Offset > 0)) {
+			page.Prev = villaCursor(vq, villas[0], true)
+		}
+	}
+
+	return villas, page, nil
 }
 
-func (v *VillasRepository) Update(ctx context.Context, villa *Villa) error {
+// Update saves the villa, the references of the images it no longer has are released with it.
+func (v *VillasRepository) Update(ctx context.Context, villa *Villa, store BlobStore) error {
 
-	query := `UPDATE villas SET image_urls=?, name=?, description=?, min_guest=?, bedrooms=?, price=?, baths=?,location_id=?,category_id=?
+	query := `UPDATE villas SET image_urls=?, image_variants=?, name=?, description=?, min_guest=?, bedrooms=?, price=?, baths=?,location_id=?,category_id=?,latitude=?,longitude=?
 	WHERE id = ?
 	`
 
-	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
-	defer cancel()
-
 	images, err := json.Marshal(villa.ImageUrls)
 	if err != nil {
 		return err
 	}
 
-	_, err = v.db.ExecContext(ctx, query,
-		images,
-		&villa.Name,
-		&villa.Description,
-		&villa.MinGuest,
-		&villa.Bedrooms,
-		&villa.Price,
-		&villa.Baths,
-		&villa.LocationId,
-		&villa.CategoryId,
-		&villa.Id,
-	)
-
+	variants, err := json.Marshal(villa.ImageVariants)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return withTx(v.db, ctx, func(tx *sql.Tx) error {
+		before, err := lockImages(ctx, tx, villa.Id)
+		if err != nil {
+			return err
+		}
 
+		dbCtx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		_, err = tx.ExecContext(dbCtx, query,
+			images,
+			variants,
+			&villa.Name,
+			&villa.Description,
+			&villa.MinGuest,
+			&villa.Bedrooms,
+			&villa.Price,
+			&villa.Baths,
+			&villa.LocationId,
+			&villa.CategoryId,
+			villa.Latitude,
+			villa.Longitude,
+			&villa.Id,
+		)
+		if err != nil {
+			return err
+		}
+
+		return updateBlobs(ctx, tx, v.db, store, before, villa.ImageUrls)
+	})
 }
 
-func (v *VillasRepository) Delete(ctx context.Context, id int) error {
+// Delete removes the villa, the files of its images go once no other villa shares them.
+func (v *VillasRepository) Delete(ctx context.Context, id int, store BlobStore) error {
 	query := `DELETE FROM villas WHERE id = ?`
 
+	return withTx(v.db, ctx, func(tx *sql.Tx) error {
+		before, err := lockImages(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		dbCtx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		if _, err := tx.ExecContext(dbCtx, query, id); err != nil {
+			return err
+		}
+
+		return updateBlobs(ctx, tx, v.db, store, before, nil)
+	})
+}
+
+// lockImages reads the images of the villa and locks its row until the transaction ends.
+func lockImages(ctx context.Context, tx *sql.Tx, villaId int) ([]string, error) {
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	_, err := v.db.ExecContext(ctx, query, id)
+	var rowUrls []byte
+
+	err := tx.QueryRowContext(ctx, `SELECT image_urls FROM villas WHERE id = ? FOR UPDATE`, villaId).Scan(&rowUrls)
 	if err != nil {
+		switch err {
+		case sql.ErrNoRows:
+			return nil, ErrNoRows
+		default:
+			return nil, err
+		}
+	}
+
+	images := []string{}
+	if err := json.Unmarshal(rowUrls, &images); err != nil {
+		return nil, err
+	}
+
+	return images, nil
+}
+
+// updateBlobs retains the images the villa has from now on and releases the ones it no longer has.
+func updateBlobs(ctx context.Context, tx *sql.Tx, db *sql.DB, store BlobStore, before, after []string) error {
+	blobs := &BlobsRepository{db}
+
+	added := slices.DeleteFunc(slices.Clone(after), func(image string) bool { return slices.Contains(before, image) })
+	removed := slices.DeleteFunc(slices.Clone(before), func(image string) bool { return slices.Contains(after, image) })
+
+	if err := blobs.retainWithTx(ctx, tx, store, added); err != nil {
 		return err
 	}
 
-	return nil
+	return blobs.releaseWithTx(ctx, tx, store, removed)
+}
+
+// UpdateImages locks the villa row so concurrent image changes do not overwrite each other.
+// The update gets the current images and returns the new ones, an error rolls back.
+// The variants of added images are stored, the variants of removed images are dropped.
+// The references of the images are counted with the change, the files nothing refers to anymore are removed.
+func (v *VillasRepository) UpdateImages(ctx context.Context, villaId int, variants map[string]map[string]string, store BlobStore, update func(images []string) ([]string, error)) ([]string, error) {
+	var images []string
+
+	err := withTx(v.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		var rowUrls, rowVariants []byte
+
+		err := tx.QueryRowContext(ctx, `SELECT image_urls, image_variants FROM villas WHERE id = ? FOR UPDATE`, villaId).Scan(&rowUrls, &rowVariants)
+		if err != nil {
+			switch err {
+			case sql.ErrNoRows:
+				return ErrNoRows
+			default:
+				return err
+			}
+		}
+
+		villa := &Villa{}
+		if err := json.Unmarshal(rowUrls, &villa.ImageUrls); err != nil {
+			return err
+		}
+
+		if err := unmarshalVariants(rowVariants, villa); err != nil {
+			return err
+		}
+
+		images, err = update(villa.ImageUrls)
+		if err != nil {
+			return err
+		}
+
+		kept := make(map[string]map[string]string, len(images))
+		for _, image := range images {
+			if variant, ok := variants[image]; ok {
+				kept[image] = variant
+			} else if variant, ok := villa.ImageVariants[image]; ok {
+				kept[image] = variant
+			}
+		}
+
+		rowUrls, err = json.Marshal(images)
+		if err != nil {
+			return err
+		}
+
+		rowVariants, err = json.Marshal(kept)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE villas SET image_urls = ?, image_variants = ? WHERE id = ?`, rowUrls, rowVariants, villaId)
+		if err != nil {
+			return err
+		}
+
+		return updateBlobs(ctx, tx, v.db, store, villa.ImageUrls, images)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return images, nil
+}
+
+// GetImageNames returns every file a villa refers to, the variants included.
+func (v *VillasRepository) GetImageNames(ctx context.Context) ([]string, error) {
+	query := `SELECT image_urls, image_variants FROM villas`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := v.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	names := []string{}
+
+	for rows.Next() {
+		var rowUrls, rowVariants []byte
+
+		if err := rows.Scan(&rowUrls, &rowVariants); err != nil {
+			return nil, err
+		}
+
+		villa := &Villa{}
+		if err := json.Unmarshal(rowUrls, &villa.ImageUrls); err != nil {
+			return nil, err
+		}
+
+		if err := unmarshalVariants(rowVariants, villa); err != nil {
+			return nil, err
+		}
+
+		names = append(names, villa.ImageUrls...)
+
+		for _, variants := range villa.ImageVariants {
+			for _, variant := range variants {
+				names = append(names, variant)
+			}
+		}
+	}
+
+	return names, rows.Err()
+}
+
+// unmarshalVariants leaves the variants empty for villas created before images were processed.
+func unmarshalVariants(data []byte, villa *Villa) error {
+	if len(data) == 0 || string(data) == "null" {
+		return nil
+	}
+
+	return json.Unmarshal(data, &villa.ImageVariants)
 }
@@ -0,0 +1,76 @@
+package search
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+const (
+	queryTimeout = 5 * time.Second
+	snippetSize  = 160
+)
+
+// MySQL ranks the villas with the FULLTEXT indexes over the villa name and description,
+// the location area and the amenity names. A match on the villa itself weighs the most.
+type MySQL struct {
+	db *sql.DB
+}
+
+func NewMySQL(db *sql.DB) *MySQL {
+	return &MySQL{db}
+}
+
+func (m *MySQL) Search(ctx context.Context, text string) ([]Hit, error) {
+	terms := Terms(text)
+	if len(terms) == 0 {
+		return nil, ErrEmptyQuery
+	}
+
+	query := `
+	SELECT
+		v.id,
+		v.description,
+		MATCH(v.name, v.description) AGAINST (? IN NATURAL LANGUAGE MODE) * 2
+		+ COALESCE(MATCH(l.area) AGAINST (? IN NATURAL LANGUAGE MODE), 0) * 1.5
+		+ COALESCE((
+			SELECT SUM(MATCH(a.name) AGAINST (? IN NATURAL LANGUAGE MODE))
+			FROM villas_amenities va JOIN amenities a ON a.id = va.amenity_id
+			WHERE va.villa_id = v.id
+		), 0) AS score
+	FROM
+		villas v LEFT JOIN locations l ON l.id = v.location_id
+	HAVING score > 0
+	ORDER BY score DESC, v.id
+	LIMIT ?`
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	q := strings.Join(terms, " ")
+
+	rows, err := m.db.QueryContext(ctx, query, q, q, q, MaxHits)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	hits := []Hit{}
+
+	for rows.Next() {
+		var hit Hit
+		var description string
+
+		if err := rows.Scan(&hit.VillaId, &description, &hit.Score); err != nil {
+			return nil, err
+		}
+
+		hit.Snippet = Snippet(description, terms, snippetSize)
+
+		hits = append(hits, hit)
+	}
+
+	return hits, rows.Err()
+}
@@ -0,0 +1,107 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"html"
+	"strings"
+	"unicode"
+)
+
+var ErrEmptyQuery = errors.New("search query is empty")
+
+// MaxHits bounds the villas a search returns, the filters and the pagination apply to them.
+const MaxHits = 1000
+
+// Hit is a villa matching the search, the hits are ordered by descending score.
+type Hit struct {
+	VillaId int
+	Score   float64
+	// Snippet of the description with the matched terms in <mark>, the text is HTML escaped
+	Snippet string
+}
+
+// Searcher finds the villas matching the text of a guest, e.g. "private pool ubud rice field".
+// MySQL implements it, a dedicated engine can replace it without changing the handlers.
+type Searcher interface {
+	Search(ctx context.Context, text string) ([]Hit, error)
+}
+
+// Terms splits the query into lower case words, the punctuation is dropped.
+func Terms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// Snippet cuts the text around the first matched term to about size runes and marks every matched term.
+func Snippet(text string, terms []string, size int) string {
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+
+	start := -1
+	for _, term := range terms {
+		if i := index(lower, []rune(term)); i >= 0 && (start < 0 || i < start) {
+			start = i
+		}
+	}
+
+	from, to := 0, min(len(runes), size)
+
+	if start >= 0 && len(runes) > size {
+		from = max(0, start-size/4)
+		to = min(len(runes), from+size)
+		from = max(0, to-size)
+	}
+
+	var b strings.Builder
+
+	if from > 0 {
+		b.WriteString("…")
+	}
+
+	for i := from; i < to; {
+		if length := matchAt(lower, i, to, terms); length > 0 {
+			b.WriteString("<mark>" + html.EscapeString(string(runes[i:i+length])) + "</mark>")
+			i += length
+			continue
+		}
+
+		b.WriteString(html.EscapeString(string(runes[i])))
+		i++
+	}
+
+	if to < len(runes) {
+		b.WriteString("…")
+	}
+
+	return b.String()
+}
+
+// matchAt returns the length of the longest term starting a word at i, 0 when none does.
+func matchAt(lower []rune, i, to int, terms []string) int {
+	if i > 0 && (unicode.IsLetter(lower[i-1]) || unicode.IsNumber(lower[i-1])) {
+		return 0
+	}
+
+	length := 0
+
+	for _, term := range terms {
+		t := []rune(term)
+		if len(t) > length && i+len(t) <= to && string(lower[i:i+len(t)]) == term {
+			length = len(t)
+		}
+	}
+
+	return length
+}
+
+func index(s, sub []rune) int {
+	for i := 0; i+len(sub) <= len(s); i++ {
+		if string(s[i:i+len(sub)]) == string(sub) {
+			return i
+		}
+	}
+
+	return -1
+}
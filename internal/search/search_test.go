@@ -0,0 +1,50 @@
+package search
+
+import "testing"
+
+func TestSnippet(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		query string
+		size  int
+		want  string
+	}{
+		{
+			name:  "should mark every matched term",
+			text:  "Private pool facing the rice field",
+			query: "pool rice",
+			size:  100,
+			want:  "Private <mark>pool</mark> facing the <mark>rice</mark> field",
+		},
+		{
+			name:  "should not mark a term inside a word",
+			text:  "Whirlpool and pool",
+			query: "pool",
+			size:  100,
+			want:  "Whirlpool and <mark>pool</mark>",
+		},
+		{
+			name:  "should escape the text",
+			text:  "<b>Pool</b> & garden",
+			query: "pool",
+			size:  100,
+			want:  "&lt;b&gt;<mark>Pool</mark>&lt;/b&gt; &amp; garden",
+		},
+		{
+			name:  "should cut the text around the first match",
+			text:  "A quiet villa far from the crowds with a private pool",
+			query: "pool",
+			size:  20,
+			want:  "… with a private <mark>pool</mark>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Snippet(tt.text, Terms(tt.query), tt.size); got != tt.want {
+				t.Errorf("expected: %v but got: %v", tt.want, got)
+			}
+		})
+	}
+}
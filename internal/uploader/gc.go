@@ -0,0 +1,148 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// GCReport lists what a garbage collection found, a dry run deletes nothing.
+type GCReport struct {
+	Group   string     `json:"group"`
+	DryRun  bool       `json:"dry_run"`
+	Scanned int        `json:"scanned"`
+	Kept    int        `json:"kept"`
+	Orphans []FileInfo `json:"orphans"`
+	Deleted int        `json:"deleted"`
+	Bytes   int64      `json:"bytes"`
+}
+
+// Remover removes an orphan image with its variants, false when the image is referenced again
+// since the references were read, e.g. an upload of the same content saved meanwhile.
+type Remover func(ctx context.Context, image string) (bool, error)
+
+// CollectGarbage deletes the files of the group that are not referenced and older than the grace period.
+// The grace period protects uploads whose villa is not saved yet.
+// The variants of a referenced image are always kept.
+// Without a remover the orphans are deleted as they are, with one an image and its variants are left to it.
+func CollectGarbage(ctx context.Context, storage Uploader, dst string, referenced []string, grace time.Duration, dryRun bool, remove Remover) (*GCReport, error) {
+	keep := make(map[string]bool, len(referenced)*(len(Variants)+1))
+
+	for _, name := range referenced {
+		keep[name] = true
+
+		for _, variant := range VariantNames(name) {
+			keep[variant] = true
+		}
+	}
+
+	files, err := storage.List(ctx, dst)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &GCReport{Group: dst, DryRun: dryRun, Scanned: len(files), Orphans: []FileInfo{}}
+	cutoff := time.Now().Add(-grace)
+
+	for _, file := range files {
+		if keep[file.Name] || file.ModTime.After(cutoff) {
+			report.Kept++
+			continue
+		}
+
+		report.Orphans = append(report.Orphans, file)
+	}
+
+	if dryRun {
+		return report, nil
+	}
+
+	// an image and its variants are removed together by the remover
+	grouped := remove != nil
+
+	if !grouped {
+		remove = func(ctx context.Context, name string) (bool, error) {
+			return true, storage.Delete(ctx, dst, name)
+		}
+	}
+
+	images := map[string][]FileInfo{}
+	order := []string{}
+
+	for _, file := range report.Orphans {
+		image := file.Name
+		if grouped {
+			image = OriginalName(file.Name)
+		}
+
+		if _, ok := images[image]; !ok {
+			order = append(order, image)
+		}
+
+		images[image] = append(images[image], file)
+	}
+
+	var errs []error
+
+	for _, image := range order {
+		removed, err := remove(ctx, image)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if !removed {
+			report.Kept += len(images[image])
+			continue
+		}
+
+		for _, file := range images[image] {
+			report.Deleted++
+			report.Bytes += file.Size
+		}
+	}
+
+	return report, errors.Join(errs...)
+}
+
+// OriginalName returns the image a variant was made from, any other name is returned as it is.
+func OriginalName(name string) string {
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+
+	for _, variant := range Variants {
+		if original, ok := strings.CutSuffix(base, "-"+variant.Name); ok {
+			return original + ext
+		}
+	}
+
+	return name
+}
+
+// ImageStore is the storage of the images of a group whose references are counted,
+// an image is removed with its variants.
+type ImageStore struct {
+	Storage Uploader
+	Dst     string
+}
+
+func (s ImageStore) Exists(ctx context.Context, name string) (bool, error) {
+	return s.Storage.Exists(ctx, s.Dst, name)
+}
+
+func (s ImageStore) Remove(ctx context.Context, images []string) error {
+	names := []string{}
+
+	for _, image := range images {
+		names = append(names, path.Base(image))
+
+		for _, variant := range VariantNames(path.Base(image)) {
+			names = append(names, variant)
+		}
+	}
+
+	return s.Storage.Delete(ctx, s.Dst, names...)
+}
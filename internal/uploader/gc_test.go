@@ -0,0 +1,132 @@
+package uploader
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCollectGarbage(t *testing.T) {
+	dir := t.TempDir()
+	lu := NewLocalUpload(dir, filepath.Join(dir, "private"), "http://localhost:8080/files")
+
+	old := time.Now().Add(-48 * time.Hour)
+
+	files := map[string]time.Time{
+		"kept.jpg":       old,
+		"kept-thumb.jpg": old,
+		"orphan.jpg":     old,
+		"fresh.jpg":      time.Now(),
+	}
+
+	os.MkdirAll(filepath.Join(dir, "villas"), 0755)
+
+	for name, modTime := range files {
+		path := filepath.Join(dir, "villas", name)
+
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		os.Chtimes(path, modTime, modTime)
+	}
+
+	ctx := context.Background()
+
+	report, err := CollectGarbage(ctx, lu, "villas", []string{"kept.jpg"}, 24*time.Hour, true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Scanned != 4 || report.Kept != 3 || len(report.Orphans) != 1 || report.Orphans[0].Name != "orphan.jpg" || report.Deleted != 0 {
+		t.Fatalf("expected only orphan.jpg to be reported but got: %+v", report)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "villas", "orphan.jpg")); err != nil {
+		t.Fatalf("expected the dry run to keep the file: %v", err)
+	}
+
+	report, err = CollectGarbage(ctx, lu, "villas", []string{"kept.jpg"}, 24*time.Hour, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Deleted != 1 {
+		t.Fatalf("expected: 1 deleted but got: %v", report.Deleted)
+	}
+
+	remaining, _ := lu.List(ctx, "villas")
+	if len(remaining) != 3 {
+		t.Errorf("expected: 3 files left but got: %v", remaining)
+	}
+}
+
+func TestCollectGarbageRemover(t *testing.T) {
+	dir := t.TempDir()
+	lu := NewLocalUpload(dir, filepath.Join(dir, "private"), "http://localhost:8080/files")
+
+	old := time.Now().Add(-48 * time.Hour)
+
+	os.MkdirAll(filepath.Join(dir, "villas"), 0755)
+
+	for _, name := range []string{"orphan.jpg", "orphan-thumb.jpg", "orphan-large.jpg", "retained.jpg", "retained-thumb.jpg"} {
+		path := filepath.Join(dir, "villas", name)
+
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		os.Chtimes(path, old, old)
+	}
+
+	store := ImageStore{lu, "villas"}
+	asked := []string{}
+
+	// retained.jpg is saved by a villa once the references were read
+	remove := func(ctx context.Context, image string) (bool, error) {
+		asked = append(asked, image)
+
+		if image == "retained.jpg" {
+			return false, nil
+		}
+
+		return true, store.Remove(ctx, []string{image})
+	}
+
+	report, err := CollectGarbage(context.Background(), lu, "villas", nil, 24*time.Hour, false, remove)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	CheckEqual(t, asked, []string{"orphan.jpg", "retained.jpg"})
+
+	if report.Deleted != 3 || report.Kept != 2 {
+		t.Errorf("expected: 3 deleted and 2 kept but got: %+v", report)
+	}
+
+	remaining, _ := lu.List(context.Background(), "villas")
+	if len(remaining) != 2 {
+		t.Errorf("expected the retained image and its variant to be left but got: %v", remaining)
+	}
+}
+
+func TestOriginalName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "abc.jpg", want: "abc.jpg"},
+		{name: "abc-thumb.jpg", want: "abc.jpg"},
+		{name: "abc-medium.jpg", want: "abc.jpg"},
+		{name: "abc-large.jpg", want: "abc.jpg"},
+		{name: "abc-small.jpg", want: "abc-small.jpg"},
+	}
+
+	for _, tt := range tests {
+		if got := OriginalName(tt.name); got != tt.want {
+			t.Errorf("expected: %v but got: %v", tt.want, got)
+		}
+	}
+}
@@ -1,96 +1,171 @@
 package uploader
 
 import (
+	"context"
 	"errors"
-	"fmt"
 	"io"
-	"mime/multipart"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/charmbracelet/log"
-	"github.com/google/uuid"
 )
 
 type LocalUpload struct {
 	baseDir string
+	// privateDir keeps the private files out of the FileServer serving baseDir
+	privateDir string
+	// baseURL the FileServer serves baseDir from
+	baseURL string
 }
 
-func NewLocalUpload(baseDir string) *LocalUpload {
-	return &LocalUpload{baseDir}
+func NewLocalUpload(baseDir, privateDir, baseURL string) *LocalUpload {
+	return &LocalUpload{baseDir, privateDir, strings.TrimSuffix(baseURL, "/")}
 }
 
-func (l *LocalUpload) Upload(r *http.Request, grp string, maxMem int64, allowMime []string) ([]string, error) {
+func (l *LocalUpload) dir(grp string) string {
+	if IsPrivate(grp) {
+		return filepath.Join(l.privateDir, strings.TrimPrefix(grp, privatePrefix))
+	}
 
-	r.ParseMultipartForm(maxMem)
+	return filepath.Join(l.baseDir, grp)
+}
 
-	FileFields := r.MultipartForm.File
+func (l *LocalUpload) Upload(r *http.Request, grp string, limits Limits, allowMime []string) ([]string, error) {
+	path := l.dir(grp)
 
-	if r.Method == "PUT" && len(FileFields) == 0 {
-		return nil, nil
+	_, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		_ = os.MkdirAll(path, 0755)
 	}
 
-	fileHeader := []*multipart.FileHeader{}
-
 	filenames := []string{}
+	written := []string{}
+
+	exists := func(name string) (bool, error) {
+		return l.Exists(r.Context(), grp, name)
+	}
 
-	for _, headerFiles := range FileFields {
-		// get one file each fields
-		headerFile := headerFiles[0]
+	err = streamImages(r, limits, allowMime, exists, func(image processedImage) error {
+		for _, file := range image.files {
+			fp := filepath.Join(path, file.name)
 
-		contentType := headerFile.Header["Content-Type"][0]
+			if err := writeFile(fp, file.body); err != nil {
+				return err
+			}
 
-		err := ValidateFile(allowMime, contentType)
-		if err != nil {
-			return nil, err
+			written = append(written, fp)
 		}
 
-		err = ValidateSize(headerFile.Size, maxMem)
-		if err != nil {
-			return nil, err
+		filenames = append(filenames, image.name)
+
+		log.Info("Image uploaded successfully")
+
+		return nil
+	})
+	if err != nil {
+		// do not leave a part of the upload behind, the files stored before stay
+		for _, fp := range written {
+			os.Remove(fp)
 		}
 
-		fileHeader = append(fileHeader, headerFile)
+		return nil, err
+	}
 
+	return filenames, nil
+}
+
+// partialPrefix names a file still being written, it is left out of List.
+const partialPrefix = ".upload-"
+
+// writeFile copies the file under a temporary name first,
+// a file cut short is never found under its content hash.
+func writeFile(fp string, body io.Reader) error {
+	tmp, err := os.CreateTemp(filepath.Dir(fp), partialPrefix+"*")
+	if err != nil {
+		return err
 	}
 
-	path := filepath.Join(l.baseDir, grp)
+	defer os.Remove(tmp.Name())
 
-	_, err := os.Stat(path)
-	if errors.Is(err, os.ErrNotExist) {
-		_ = os.Mkdir(path, 0755)
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return err
 	}
 
-	// save file
-	for _, fileHeader := range fileHeader {
-		file, err := fileHeader.Open()
-		if err != nil {
-			return nil, err
-		}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
 
-		name, ex := strings.Split(fileHeader.Filename, ".")[0], strings.Split(fileHeader.Filename, ".")[1]
+	if err := tmp.Close(); err != nil {
+		return err
+	}
 
-		// file-uuid.ext
-		fp := fmt.Sprintf("%s-%s.%s", name, uuid.New().String(), ex)
+	return os.Rename(tmp.Name(), fp)
+}
 
-		dst, err := os.Create(filepath.Join(l.baseDir, grp, fp))
-		if err != nil {
-			return nil, err
+func (l *LocalUpload) Delete(ctx context.Context, grp string, names ...string) error {
+	var errs []error
+
+	for _, name := range names {
+		err := os.Remove(filepath.Join(l.dir(grp), filepath.Base(name)))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
 		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (l *LocalUpload) URL(grp, name string) string {
+	return l.baseURL + "/" + grp + "/" + url.PathEscape(name)
+}
 
-		defer dst.Close()
+func (l *LocalUpload) Open(ctx context.Context, grp, name string) (io.ReadCloser, error) {
+	file, err := os.Open(filepath.Join(l.dir(grp), filepath.Base(name)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrFileNotFound
+	}
 
-		_, err = io.Copy(dst, file)
+	return file, err
+}
+
+func (l *LocalUpload) List(ctx context.Context, grp string) ([]FileInfo, error) {
+	entries, err := os.ReadDir(l.dir(grp))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	files := []FileInfo{}
+
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), partialPrefix) {
+			continue
+		}
+
+		info, err := entry.Info()
 		if err != nil {
 			return nil, err
 		}
 
-		filenames = append(filenames, fp)
+		files = append(files, FileInfo{Name: entry.Name(), Size: info.Size(), ModTime: info.ModTime()})
+	}
 
-		log.Info("Image uploaded successfully")
+	return files, nil
+}
+
+func (l *LocalUpload) Exists(ctx context.Context, grp, name string) (bool, error) {
+	_, err := os.Stat(filepath.Join(l.dir(grp), filepath.Base(name)))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
 	}
 
-	return filenames, nil
+	return err == nil, err
 }
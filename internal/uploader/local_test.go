@@ -2,8 +2,12 @@ package uploader
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
 	"fmt"
-	"io"
+	"hash/crc32"
+	"maps"
 	"mime"
 	"mime/multipart"
 	"net/http"
@@ -11,18 +15,17 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"regexp"
+	"slices"
 	"testing"
+	"time"
 )
 
 func MultipartRequest(t *testing.T, fieldName, filePath string) *http.Request {
-	file, err := os.Open(filePath)
+	content, err := os.ReadFile(filePath)
 	if err != nil {
 		t.Fatalf("Failed to open file: %v", err)
 	}
-	defer file.Close()
-
-	var body bytes.Buffer
-	writer := multipart.NewWriter(&body)
 
 	// Detect MIME type from file extension
 	mimeType := mime.TypeByExtension(filepath.Ext(filePath))
@@ -30,9 +33,17 @@ func MultipartRequest(t *testing.T, fieldName, filePath string) *http.Request {
 		mimeType = "application/octet-stream"
 	}
 
+	return multipartRequest(t, fieldName, filepath.Base(filePath), mimeType, content)
+}
+
+// multipartRequest sends the content with a client chosen filename and Content-Type.
+func multipartRequest(t *testing.T, fieldName, filename, mimeType string, content []byte) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
 	// Set part headers manually
 	h := make(textproto.MIMEHeader)
-	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, fieldName, filepath.Base(filePath)))
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, fieldName, filename))
 	h.Set("Content-Type", mimeType)
 
 	part, err := writer.CreatePart(h)
@@ -40,8 +51,7 @@ func MultipartRequest(t *testing.T, fieldName, filePath string) *http.Request {
 		t.Fatalf("Failed to create part: %v", err)
 	}
 
-	_, err = io.Copy(part, file)
-	if err != nil {
+	if _, err := part.Write(content); err != nil {
 		t.Fatalf("Failed to write file content: %v", err)
 	}
 
@@ -59,14 +69,21 @@ func MultipartRequest(t *testing.T, fieldName, filePath string) *http.Request {
 	return req
 }
 
-func CheckEqual(t *testing.T, result, want []string) {
-	if !reflect.DeepEqual(result, want) {
-		t.Errorf("expected: %v but got: %v", want, result)
-	}
+// pngHeader is a valid PNG signature and IHDR chunk declaring the dimensions, without pixel data.
+func pngHeader(width, height uint32) []byte {
+	ihdr := binary.BigEndian.AppendUint32([]byte("IHDR"), width)
+	ihdr = binary.BigEndian.AppendUint32(ihdr, height)
+	ihdr = append(ihdr, 8, 6, 0, 0, 0)
+
+	data := []byte("\x89PNG\r\n\x1a\n")
+	data = binary.BigEndian.AppendUint32(data, 13)
+	data = append(data, ihdr...)
+
+	return binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(ihdr))
 }
 
 func TestLocalUploaded(t *testing.T) {
-	var maxMemo int64 = 3 * 1024 * 1024 // 3 mb
+	limits := Limits{MaxFileSize: 3 * 1024 * 1024, MaxRequestSize: 10 * 1024 * 1024, MaxFiles: 5} // 3 mb each
 
 	dst := "./assets-test/output-assets/"
 	os.MkdirAll(dst, 0755)
@@ -74,40 +91,69 @@ func TestLocalUploaded(t *testing.T) {
 	// Remove after uploading
 	defer os.RemoveAll(dst)
 
-	lu := NewLocalUpload(dst)
+	lu := NewLocalUpload(dst, filepath.Join(dst, "private"), "http://localhost:8080/files")
 
-	t.Run("should success upload images", func(t *testing.T) {
-		req := MultipartRequest(t, "file", "./assets-test/input-assets/image1.jpeg")
+	allowMime := []string{"image/png", "image/jpeg"}
 
-		allowMime := []string{"image/png", "image/jpeg", "image/jpg"}
+	image1, err := os.ReadFile("./assets-test/input-assets/image1.jpeg")
+	if err != nil {
+		t.Fatal(err)
+	}
 
-		want := []string{"image1.jpeg"}
+	excel, err := os.ReadFile("./assets-test/input-assets/excel.xlsx")
+	if err != nil {
+		t.Fatal(err)
+	}
 
-		result, err := lu.Upload(req, "test", maxMemo, allowMime)
+	generated := regexp.MustCompile(`^[0-9a-f]{64}\.jpg$`)
 
-		if err != nil {
-			t.Error(err)
-		}
+	tests := []struct {
+		name     string
+		filename string
+		mimeType string
+		content  []byte
+		want     error
+	}{
+		{name: "should success upload images", filename: "image1.jpeg", mimeType: "image/jpeg", content: image1},
+		{name: "should upload a filename without a dot", filename: "image1", mimeType: "image/jpeg", content: image1},
+		{name: "should upload a filename with many dots", filename: "a.b.jpg", mimeType: "image/jpeg", content: image1},
+		{name: "should not write outside the directory", filename: "../../../etc/passwd.jpg", mimeType: "image/jpeg", content: image1},
+		{name: "should sniff the type instead of trusting the header", filename: "image1.png", mimeType: "application/pdf", content: image1},
+		{name: "should fail upload file if the type not allowed", filename: "excel.xlsx", mimeType: "image/jpeg", content: excel, want: ErrExtNotAllowed},
+		{name: "should fail upload html disguised as an image", filename: "page.png", mimeType: "image/png", content: []byte("<html><script>alert(1)</script></html>"), want: ErrExtNotAllowed},
+		{name: "should fail upload a truncated image", filename: "image1.jpeg", mimeType: "image/jpeg", content: image1[:len(image1)/2], want: ErrInvalidImage},
+		{name: "should fail upload a decompression bomb", filename: "bomb.png", mimeType: "image/png", content: pngHeader(100_000, 100_000), want: ErrImageTooLarge},
+		{name: "should fail upload an empty image", filename: "empty.png", mimeType: "image/png", content: pngHeader(0, 0), want: ErrInvalidImage},
+	}
 
-		CheckEqual(t, result, want)
-	})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := multipartRequest(t, "file", tt.filename, tt.mimeType, tt.content)
 
-	t.Run("should fail upload file if the type not allowed", func(t *testing.T) {
-		req := MultipartRequest(t, "file", "./assets-test/input-assets/excel.xlsx")
+			result, err := lu.Upload(req, "test", limits, allowMime)
+			if err != tt.want {
+				t.Fatalf("expected: %v but got: %v", tt.want, err)
+			}
 
-		allowMime := []string{"image/png", "image/jpeg", "image/jpg"}
+			if tt.want != nil {
+				return
+			}
 
-		want := ErrExtNotAllowed
+			if len(result) != 1 || !generated.MatchString(result[0]) {
+				t.Fatalf("expected a generated filename but got: %v", result)
+			}
 
-		_, err := lu.Upload(req, "test", maxMemo, allowMime)
-		if err != want {
-			t.Errorf("expected: %v but got: %v", want, err)
-		}
-	})
+			for _, name := range append([]string{result[0]}, slices.Collect(maps.Values(VariantNames(result[0])))...) {
+				if _, err := os.Stat(filepath.Join(dst, "test", name)); err != nil {
+					t.Errorf("expected %v to be stored: %v", name, err)
+				}
+			}
+		})
+	}
 
 	t.Run("should fail upload file if the size is too large", func(t *testing.T) {
 
-		maxMemo = 1 * 1024 * 1024 // 1 mb
+		limits := Limits{MaxFileSize: 1 * 1024 * 1024, MaxRequestSize: 10 * 1024 * 1024, MaxFiles: 5} // 1 mb
 
 		req := MultipartRequest(t, "file", "./assets-test/input-assets/image-large.png")
 
@@ -115,9 +161,132 @@ func TestLocalUploaded(t *testing.T) {
 
 		want := ErrSizeTooLarger
 
-		_, err := lu.Upload(req, "test", maxMemo, allowMime)
+		_, err := lu.Upload(req, "test", limits, allowMime)
 		if err != want {
 			t.Errorf("expected: %v but got: %v", want, err)
 		}
 	})
+
+	t.Run("should upload every file of a field and keep the form values", func(t *testing.T) {
+		req := multipartFiles(t, map[string]string{"properties": `{"name":"villa"}`}, image1, image1, image1)
+
+		result, err := lu.Upload(req, "test", limits, allowMime)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(result) != 3 {
+			t.Errorf("expected: 3 files but got: %v", result)
+		}
+
+		if got := req.MultipartForm.Value["properties"]; len(got) != 1 || got[0] != `{"name":"villa"}` {
+			t.Errorf("expected the properties to be kept but got: %v", got)
+		}
+	})
+
+	t.Run("should store an identical upload once", func(t *testing.T) {
+		first, err := lu.Upload(multipartFiles(t, nil, image1), "dedup", limits, allowMime)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		stored := filepath.Join(dst, "dedup", first[0])
+
+		// the file is kept as it is, a changed time shows it was written again
+		old := time.Now().Add(-time.Hour).Truncate(time.Second)
+		os.Chtimes(stored, old, old)
+
+		second, err := lu.Upload(multipartFiles(t, nil, image1), "dedup", limits, allowMime)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		CheckEqual(t, second, first)
+
+		if info, err := os.Stat(stored); err != nil || !info.ModTime().Equal(old) {
+			t.Errorf("expected the stored file to be reused")
+		}
+
+		files, _ := lu.List(context.Background(), "dedup")
+		if len(files) != len(Variants)+1 {
+			t.Errorf("expected the image and its variants once but got: %v", files)
+		}
+	})
+
+	t.Run("should store a document as it was sent", func(t *testing.T) {
+		document := append([]byte("%PDF-1.4\n"), bytes.Repeat([]byte("passport "), 100_000)...)
+
+		result, err := lu.Upload(multipartFiles(t, nil, document), Private("documents"), limits, []string{"application/pdf"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sum := sha256.Sum256(document)
+
+		CheckEqual(t, result, []string{ContentName(sum[:], ".pdf")})
+
+		stored, err := os.ReadFile(filepath.Join(dst, "private", "documents", result[0]))
+		if err != nil || !bytes.Equal(stored, document) {
+			t.Errorf("expected the document to be stored unchanged: %v", err)
+		}
+
+		files, _ := lu.List(context.Background(), Private("documents"))
+		if len(files) != 1 {
+			t.Errorf("expected only the document to be left but got: %v", files)
+		}
+	})
+
+	t.Run("should fail upload more files than allowed", func(t *testing.T) {
+		req := multipartFiles(t, nil, image1, image1)
+
+		_, err := lu.Upload(req, "test", Limits{MaxFileSize: limits.MaxFileSize, MaxRequestSize: limits.MaxRequestSize, MaxFiles: 1}, allowMime)
+		if err != ErrTooManyFiles {
+			t.Errorf("expected: %v but got: %v", ErrTooManyFiles, err)
+		}
+	})
+
+	t.Run("should fail upload a request larger than allowed", func(t *testing.T) {
+		req := multipartFiles(t, nil, image1, image1)
+
+		_, err := lu.Upload(req, "test", Limits{MaxFileSize: limits.MaxFileSize, MaxRequestSize: int64(len(image1)), MaxFiles: 5}, allowMime)
+		if err != ErrSizeTooLarger {
+			t.Errorf("expected: %v but got: %v", ErrSizeTooLarger, err)
+		}
+	})
+}
+
+// multipartFiles sends every file in the same "images" field along with the values.
+func multipartFiles(t *testing.T, values map[string]string, files ...[]byte) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	for key, value := range values {
+		writer.WriteField(key, value)
+	}
+
+	for i, file := range files {
+		part, err := writer.CreateFormFile("images", fmt.Sprintf("image%d.jpeg", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		part.Write(file)
+	}
+
+	writer.Close()
+
+	req, err := http.NewRequest("POST", "/", &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	return req
+}
+
+func CheckEqual(t *testing.T, result, want []string) {
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("expected: %v but got: %v", want, result)
+	}
 }
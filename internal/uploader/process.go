@@ -0,0 +1,290 @@
+package uploader
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+var ErrImageTooLarge = errors.New("image dimensions are too large")
+
+const (
+	// maxPixels guards against decompression bombs, 40 megapixels covers any phone camera
+	maxPixels = 40_000_000
+	maxSide   = 12_000
+
+	// maxWidth of the stored original, the variants are made from it
+	maxWidth = 2048
+
+	jpegQuality = 82
+
+	// maxHeader holds the segments in front of the image data of a JPEG, an EXIF segment is at most 64 kb
+	maxHeader = 256 * 1024
+)
+
+// Variant is a resized copy of an uploaded image.
+type Variant struct {
+	Name  string
+	Width int
+}
+
+var Variants = []Variant{
+	{Name: "thumb", Width: 320},
+	{Name: "medium", Width: 800},
+	{Name: "large", Width: 1600},
+}
+
+// VariantNames returns the filename of every variant of the stored image,
+// e.g. "uuid.jpg" has the thumbnail "uuid-thumb.jpg".
+func VariantNames(filename string) map[string]string {
+	ext := filepath.Ext(filename)
+	base := strings.TrimSuffix(filename, ext)
+
+	names := make(map[string]string, len(Variants))
+	for _, variant := range Variants {
+		names[variant.Name] = base + "-" + variant.Name + ext
+	}
+
+	return names
+}
+
+// ProcessImage decodes the image, applies the EXIF orientation and re-encodes it as JPEG.
+// The re-encoding drops every metadata like the EXIF GPS position.
+// It returns the original, limited to maxWidth, followed by the variants in the order of Variants.
+func ProcessImage(file io.ReadSeeker) ([][]byte, error) {
+	// the EXIF segment comes before the image data, only the head of the file is read for it
+	head, err := io.ReadAll(io.LimitReader(file, maxHeader))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	config, _, err := image.DecodeConfig(file)
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	// the header is checked before decoding, a small file can declare a huge image
+	if config.Width <= 0 || config.Height <= 0 || config.Width > maxSide || config.Height > maxSide || config.Width*config.Height > maxPixels {
+		return nil, ErrImageTooLarge
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	src, _, err := image.Decode(file)
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	img := orient(toRGBA(src), jpegOrientation(head))
+
+	original := resize(img, maxWidth)
+
+	outputs := make([][]byte, 0, len(Variants)+1)
+
+	encoded, err := encodeJPEG(original)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs = append(outputs, encoded)
+
+	for _, variant := range Variants {
+		encoded, err := encodeJPEG(resize(original, variant.Width))
+		if err != nil {
+			return nil, err
+		}
+
+		outputs = append(outputs, encoded)
+	}
+
+	return outputs, nil
+}
+
+func encodeJPEG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// toRGBA flattens transparent pixels on white, JPEG has no alpha channel.
+func toRGBA(src image.Image) *image.RGBA {
+	bounds := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), src, bounds.Min, draw.Over)
+
+	return dst
+}
+
+// resize scales the image down to the width by averaging the covered source pixels.
+// Smaller images are returned as is, they are never scaled up.
+func resize(src *image.RGBA, width int) *image.RGBA {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	if sw <= width {
+		return src
+	}
+
+	height := max(1, sh*width/sw)
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for y := range height {
+		y0, y1 := y*sh/height, max((y+1)*sh/height, y*sh/height+1)
+
+		for x := range width {
+			x0, x1 := x*sw/width, max((x+1)*sw/width, x*sw/width+1)
+
+			var r, g, b, a, n uint32
+
+			for sy := y0; sy < y1; sy++ {
+				i := src.PixOffset(x0, sy)
+
+				for sx := x0; sx < x1; sx++ {
+					r += uint32(src.Pix[i])
+					g += uint32(src.Pix[i+1])
+					b += uint32(src.Pix[i+2])
+					a += uint32(src.Pix[i+3])
+					n++
+					i += 4
+				}
+			}
+
+			j := dst.PixOffset(x, y)
+			dst.Pix[j] = uint8(r / n)
+			dst.Pix[j+1] = uint8(g / n)
+			dst.Pix[j+2] = uint8(b / n)
+			dst.Pix[j+3] = uint8(a / n)
+		}
+	}
+
+	return dst
+}
+
+// orient rotates and flips the pixels so the image is upright without its EXIF orientation.
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := range h {
+		for x := range w {
+			var dx, dy int
+
+			switch orientation {
+			case 2: // mirrored
+				dx, dy = w-1-x, y
+			case 3: // rotated 180
+				dx, dy = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				dx, dy = x, h-1-y
+			case 5: // transposed
+				dx, dy = y, x
+			case 6: // rotated 90 clockwise
+				dx, dy = h-1-y, x
+			case 7: // transversed
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotated 90 counter clockwise
+				dx, dy = y, w-1-x
+			}
+
+			copy(dst.Pix[dst.PixOffset(dx, dy):][:4], src.Pix[src.PixOffset(x, y):][:4])
+		}
+	}
+
+	return dst
+}
+
+// jpegOrientation reads the orientation tag of the EXIF segment, 1 (upright) when there is none.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+
+		marker := data[i+1]
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+
+		// the image data starts, no EXIF before it
+		if marker == 0xDA || size < 2 || i+2+size > len(data) {
+			return 1
+		}
+
+		segment := data[i+4 : i+2+size]
+
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+
+		i += 2 + size
+	}
+
+	return 1
+}
+
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 1
+	}
+
+	entries := int(order.Uint16(tiff[ifd:]))
+
+	for n := range entries {
+		entry := ifd + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			return int(order.Uint16(tiff[entry+8:]))
+		}
+	}
+
+	return 1
+}
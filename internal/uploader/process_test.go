@@ -0,0 +1,58 @@
+package uploader
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"testing"
+)
+
+// withOrientation inserts an EXIF segment holding only the orientation tag after the SOI marker.
+func withOrientation(data []byte, orientation byte) []byte {
+	tiff := []byte{
+		'M', 'M', 0, 42, 0, 0, 0, 8,
+		0, 1,
+		0x01, 0x12, 0, 3, 0, 0, 0, 1, 0, orientation, 0, 0,
+		0, 0, 0, 0,
+	}
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	size := len(segment) + 2
+
+	app1 := append([]byte{0xFF, 0xE1, byte(size >> 8), byte(size)}, segment...)
+
+	return append(append([]byte{0xFF, 0xD8}, app1...), data[2:]...)
+}
+
+func TestProcessImage(t *testing.T) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 1000, 500)), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	outputs, err := ProcessImage(bytes.NewReader(withOrientation(buf.Bytes(), 6)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(outputs) != len(Variants)+1 {
+		t.Fatalf("expected: %v images but got: %v", len(Variants)+1, len(outputs))
+	}
+
+	expected := []image.Point{{500, 1000}, {320, 640}, {500, 1000}, {500, 1000}}
+
+	for i, output := range outputs {
+		if jpegOrientation(output) != 1 || bytes.Contains(output, []byte("Exif")) {
+			t.Errorf("expected image %v without EXIF", i)
+		}
+
+		config, err := jpeg.DecodeConfig(bytes.NewReader(output))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := (image.Point{config.Width, config.Height}); got != expected[i] {
+			t.Errorf("expected image %v to be: %v but got: %v", i, expected[i], got)
+		}
+	}
+}
@@ -0,0 +1,366 @@
+package uploader
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+var ErrStorage = errors.New("object storage request failed")
+
+// S3Config addresses a bucket of any S3-compatible storage like AWS S3 or MinIO.
+type S3Config struct {
+	// Endpoint of the storage e.g. "https://s3.eu-west-1.amazonaws.com" or "http://localhost:9000"
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// PublicURL the objects are served from e.g. a CDN, defaults to the bucket on the endpoint
+	PublicURL string
+}
+
+// S3Upload stores the objects with path-style requests, which every S3-compatible storage supports.
+type S3Upload struct {
+	config S3Config
+	client *http.Client
+	now    func() time.Time
+}
+
+func NewS3Upload(config S3Config, client *http.Client) *S3Upload {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	config.Endpoint = strings.TrimSuffix(config.Endpoint, "/")
+
+	if config.PublicURL == "" {
+		config.PublicURL = config.Endpoint + "/" + config.Bucket
+	}
+
+	config.PublicURL = strings.TrimSuffix(config.PublicURL, "/")
+
+	return &S3Upload{config: config, client: client, now: time.Now}
+}
+
+func (s *S3Upload) Upload(r *http.Request, grp string, limits Limits, allowMime []string) ([]string, error) {
+	filenames := []string{}
+	written := []string{}
+
+	exists := func(name string) (bool, error) {
+		return s.Exists(r.Context(), grp, name)
+	}
+
+	err := streamImages(r, limits, allowMime, exists, func(image processedImage) error {
+		for _, file := range image.files {
+			if err := s.put(r.Context(), grp+"/"+file.name, file); err != nil {
+				return err
+			}
+
+			written = append(written, file.name)
+		}
+
+		filenames = append(filenames, image.name)
+
+		log.Info("Image uploaded successfully", "bucket", s.config.Bucket)
+
+		return nil
+	})
+	if err != nil {
+		// do not leave a part of the upload behind, the objects stored before stay
+		s.Delete(r.Context(), grp, written...)
+
+		return nil, err
+	}
+
+	return filenames, nil
+}
+
+// Delete removes the objects, S3 reports success for objects that do not exist.
+func (s *S3Upload) Delete(ctx context.Context, grp string, names ...string) error {
+	var errs []error
+
+	for _, name := range names {
+		req, err := s.request(ctx, http.MethodDelete, grp+"/"+name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if err := s.do(req, emptyPayload); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// URL of the object, the bucket policy has to keep the private/ prefix unreadable.
+func (s *S3Upload) URL(grp, name string) string {
+	return s.config.PublicURL + "/" + grp + "/" + url.PathEscape(name)
+}
+
+func (s *S3Upload) Open(ctx context.Context, grp, name string) (io.ReadCloser, error) {
+	req, err := s.request(ctx, http.MethodGet, grp+"/"+name)
+	if err != nil {
+		return nil, err
+	}
+
+	signV4(req, emptyPayload, s.config, s.now())
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		res.Body.Close()
+		return nil, ErrFileNotFound
+	case res.StatusCode >= 300:
+		res.Body.Close()
+		return nil, fmt.Errorf("%w: GET %s: %d", ErrStorage, req.URL.Path, res.StatusCode)
+	}
+
+	return res.Body, nil
+}
+
+type listBucketResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List pages through ListObjectsV2, the objects of nested groups are left out.
+func (s *S3Upload) List(ctx context.Context, grp string) ([]FileInfo, error) {
+	files := []FileInfo{}
+	token := ""
+
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {grp + "/"}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.config.Endpoint+"/"+s.config.Bucket+"?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		signV4(req, emptyPayload, s.config, s.now())
+
+		res, err := s.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		result := listBucketResult{}
+
+		if res.StatusCode >= 300 {
+			res.Body.Close()
+			return nil, fmt.Errorf("%w: list %s: %d", ErrStorage, grp, res.StatusCode)
+		}
+
+		err = xml.NewDecoder(res.Body).Decode(&result)
+		res.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, object := range result.Contents {
+			name := strings.TrimPrefix(object.Key, grp+"/")
+			if strings.Contains(name, "/") {
+				continue
+			}
+
+			files = append(files, FileInfo{Name: name, Size: object.Size, ModTime: object.LastModified})
+		}
+
+		if !result.IsTruncated {
+			return files, nil
+		}
+
+		token = result.NextContinuationToken
+	}
+}
+
+func (s *S3Upload) Exists(ctx context.Context, grp, name string) (bool, error) {
+	req, err := s.request(ctx, http.MethodHead, grp+"/"+name)
+	if err != nil {
+		return false, err
+	}
+
+	signV4(req, emptyPayload, s.config, s.now())
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+
+	res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return false, nil
+	case res.StatusCode >= 300:
+		return false, fmt.Errorf("%w: HEAD %s: %d", ErrStorage, req.URL.Path, res.StatusCode)
+	}
+
+	return true, nil
+}
+
+// put streams the file to the bucket, its SHA-256 sum is known before it is read.
+func (s *S3Upload) put(ctx context.Context, key string, file storedFile) error {
+	req, err := s.request(ctx, http.MethodPut, key)
+	if err != nil {
+		return err
+	}
+
+	req.Body = io.NopCloser(file.body)
+	req.ContentLength = file.size
+	req.Header.Set("Content-Type", file.contentType)
+
+	return s.do(req, hex.EncodeToString(file.sum))
+}
+
+func (s *S3Upload) request(ctx context.Context, method, key string) (*http.Request, error) {
+	endpoint := s.config.Endpoint + "/" + s.config.Bucket + "/" + key
+
+	return http.NewRequestWithContext(ctx, method, endpoint, nil)
+}
+
+func (s *S3Upload) do(req *http.Request, payloadHash string) error {
+	signV4(req, payloadHash, s.config, s.now())
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("%w: %s %s: %d %s", ErrStorage, req.Method, req.URL.Path, res.StatusCode, msg)
+	}
+
+	return nil
+}
+
+// emptyPayload is the SHA-256 sum of a request without a body.
+const emptyPayload = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// signV4 adds the AWS Signature Version 4 of the request as the Authorization header.
+// Every header already set on the request is signed along with the host.
+func signV4(req *http.Request, payloadHash string, config S3Config, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.Join(values, ",")
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uriEncode(req.URL.Path, false),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + config.Region + "/s3/aws4_request"
+
+	hashed := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hashed[:])
+
+	key := hmacSHA256([]byte("AWS4"+config.SecretKey), date)
+	key = hmacSHA256(key, config.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		config.AccessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	pairs := []string{}
+	for _, key := range keys {
+		values := query[key]
+		sort.Strings(values)
+
+		for _, value := range values {
+			pairs = append(pairs, uriEncode(key, true)+"="+uriEncode(value, true))
+		}
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode escapes everything but the unreserved characters, slashes are kept in a path.
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+
+	for _, c := range []byte(s) {
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+
+	return b.String()
+}
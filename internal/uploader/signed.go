@@ -0,0 +1,84 @@
+package uploader
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"mime"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidSignature = errors.New("the signature of the url is invalid")
+	ErrURLExpired       = errors.New("the url is expired")
+)
+
+// URLSigner issues expiring URLs for private files, the signature covers the file,
+// the expiry and the content disposition so neither can be changed by the holder.
+type URLSigner struct {
+	key []byte
+	// baseURL of the handler verifying the URLs e.g. "https://api.gobali.com/v1/files"
+	baseURL string
+}
+
+func NewURLSigner(key []byte, baseURL string) *URLSigner {
+	return &URLSigner{key: key, baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// URL signs the private file for the ttl, with a filename the file is downloaded
+// as an attachment instead of shown inline.
+func (s *URLSigner) URL(dst, name string, ttl time.Duration, filename string) string {
+	dst = strings.TrimPrefix(dst, privatePrefix)
+
+	disposition := "inline"
+	if filename != "" {
+		disposition = mime.FormatMediaType("attachment", map[string]string{"filename": filename})
+	}
+
+	expires := strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)
+
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("disposition", disposition)
+	query.Set("signature", s.sign(dst, name, expires, disposition))
+
+	return s.baseURL + "/" + dst + "/" + url.PathEscape(name) + "?" + query.Encode()
+}
+
+// Verify checks the signature and the expiry of the URL, it returns the content disposition to serve the file with.
+func (s *URLSigner) Verify(dst, name string, query url.Values, now time.Time) (string, error) {
+	dst = strings.TrimPrefix(dst, privatePrefix)
+
+	expires, disposition := query.Get("expires"), query.Get("disposition")
+
+	signature, err := hex.DecodeString(query.Get("signature"))
+	if err != nil {
+		return "", ErrInvalidSignature
+	}
+
+	want, _ := hex.DecodeString(s.sign(dst, name, expires, disposition))
+	if !hmac.Equal(signature, want) {
+		return "", ErrInvalidSignature
+	}
+
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return "", ErrInvalidSignature
+	}
+
+	if now.Unix() > exp {
+		return "", ErrURLExpired
+	}
+
+	return disposition, nil
+}
+
+func (s *URLSigner) sign(dst, name, expires, disposition string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(dst + "\n" + name + "\n" + expires + "\n" + disposition))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,55 @@
+package uploader
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestURLSigner(t *testing.T) {
+	signer := NewURLSigner([]byte("secret"), "http://localhost:8080/v1/files/private")
+
+	signed, err := url.Parse(signer.URL(Private("documents"), "passport.pdf", time.Minute, "passport.pdf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if signed.Path != "/v1/files/private/documents/passport.pdf" {
+		t.Fatalf("expected the path of the file but got: %v", signed.Path)
+	}
+
+	tamper := func(key, value string) url.Values {
+		query := signed.Query()
+		query.Set(key, value)
+		return query
+	}
+
+	tests := []struct {
+		name  string
+		file  string
+		query url.Values
+		now   time.Time
+		want  error
+	}{
+		{name: "should verify the signed url", file: "passport.pdf", query: signed.Query(), now: time.Now()},
+		{name: "should fail for another file", file: "other.pdf", query: signed.Query(), now: time.Now(), want: ErrInvalidSignature},
+		{name: "should fail for a later expiry", file: "passport.pdf", query: tamper("expires", "99999999999"), now: time.Now(), want: ErrInvalidSignature},
+		{name: "should fail for another disposition", file: "passport.pdf", query: tamper("disposition", "inline"), now: time.Now(), want: ErrInvalidSignature},
+		{name: "should fail without signature", file: "passport.pdf", query: tamper("signature", ""), now: time.Now(), want: ErrInvalidSignature},
+		{name: "should fail once expired", file: "passport.pdf", query: signed.Query(), now: time.Now().Add(2 * time.Minute), want: ErrURLExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			disposition, err := signer.Verify(Private("documents"), tt.file, tt.query, tt.now)
+			if err != tt.want {
+				t.Fatalf("expected: %v but got: %v", tt.want, err)
+			}
+
+			if err == nil && !strings.HasPrefix(disposition, "attachment") {
+				t.Errorf("expected an attachment but got: %v", disposition)
+			}
+		})
+	}
+}
@@ -1,18 +1,68 @@
 package uploader
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
 	"net/http"
+	"os"
 	"slices"
+	"strings"
+	"time"
 )
 
 var (
 	ErrExtNotAllowed = errors.New("this file type not allowed")
 	ErrSizeTooLarger = errors.New("size is too large")
+	ErrInvalidImage  = errors.New("file is not a valid image")
+	ErrFileNotFound  = errors.New("file not found")
+	ErrTooManyFiles  = errors.New("too many files")
 )
 
+// privatePrefix marks a destination whose files are never served publicly,
+// they are only reachable through a signed URL.
+const privatePrefix = "private/"
+
+// Private returns the private destination of the group, e.g. Private("documents").
+func Private(dst string) string {
+	return privatePrefix + dst
+}
+
+func IsPrivate(dst string) bool {
+	return strings.HasPrefix(dst, privatePrefix)
+}
+
 type Uploader interface {
-	Upload(r *http.Request, dst string, maxMem int64, allowMime []string) ([]string, error)
+	// Upload stores every file of the multipart body, the names are returned in the order they were sent
+	Upload(r *http.Request, dst string, limits Limits, allowMime []string) ([]string, error)
+	// Delete removes the stored files of the group, missing files are not an error
+	Delete(ctx context.Context, dst string, names ...string) error
+	// URL is the absolute address the public file is served from
+	URL(dst, name string) string
+	// Open reads a stored file, ErrFileNotFound when it does not exist
+	Open(ctx context.Context, dst, name string) (io.ReadCloser, error)
+	// List returns every file stored in the group
+	List(ctx context.Context, dst string) ([]FileInfo, error)
+	// Exists reports whether the file is stored in the group
+	Exists(ctx context.Context, dst, name string) (bool, error)
+}
+
+type FileInfo struct {
+	Name    string    `json:"name"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// DetectContentType sniffs the type from the first bytes of the file,
+// the Content-Type header is chosen by the client and can not be trusted.
+func DetectContentType(data []byte) string {
+	return http.DetectContentType(data)
 }
 
 func ValidateFile(allowMime []string, contentType string) error {
@@ -30,3 +80,263 @@ func ValidateSize(size, limit int64) error {
 
 	return nil
 }
+
+// storedFile is written to the storage as it is read, it is size bytes long with the SHA-256 sum.
+type storedFile struct {
+	name        string
+	contentType string
+	body        io.Reader
+	size        int64
+	sum         []byte
+}
+
+// memoryFile is a processed image, the variants are small enough to be held in memory.
+func memoryFile(name string, data []byte) storedFile {
+	sum := sha256.Sum256(data)
+	return storedFile{name, DetectContentType(data), bytes.NewReader(data), int64(len(data)), sum[:]}
+}
+
+// processedImage is an uploaded file ready to be stored, an image is followed by its variants.
+// A file stored before has no files left to store.
+type processedImage struct {
+	name  string
+	files []storedFile
+}
+
+// maxFieldSize of a form value sent along the files, e.g. the JSON properties of a villa
+const maxFieldSize = 1 << 20
+
+// Limits bound an upload, a request above any of them is refused.
+type Limits struct {
+	MaxFileSize    int64
+	MaxRequestSize int64
+	MaxFiles       int
+}
+
+// streamImages reads the multipart body part by part.
+// Every field may hold several files, each one is spooled to a temporary file, validated, processed and stored before the next is read,
+// so no upload is held in memory as a whole.
+// A file already stored under its content hash is neither processed nor stored again.
+// The other form values are kept in r.MultipartForm for the handler.
+// The names are generated, the client filename is never used on disk.
+func streamImages(r *http.Request, limits Limits, allowMime []string, exists func(name string) (bool, error), store func(processedImage) error) error {
+	r.Body = http.MaxBytesReader(nil, r.Body, limits.MaxRequestSize)
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return err
+	}
+
+	form := &multipart.Form{Value: map[string][]string{}, File: map[string][]*multipart.FileHeader{}}
+
+	// the handler reads its values through ParseMultipartForm, which keeps an already set form
+	defer func() { r.MultipartForm = form }()
+
+	files := 0
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return sizeError(err)
+		}
+
+		if part.FileName() == "" {
+			value, err := readLimited(part, maxFieldSize)
+			if err != nil {
+				return err
+			}
+
+			form.Value[part.FormName()] = append(form.Value[part.FormName()], string(value))
+			continue
+		}
+
+		if files++; files > limits.MaxFiles {
+			return ErrTooManyFiles
+		}
+
+		if err := storePart(part, limits.MaxFileSize, allowMime, exists, store); err != nil {
+			return err
+		}
+	}
+}
+
+func storePart(part *multipart.Part, limit int64, allowMime []string, exists func(name string) (bool, error), store func(processedImage) error) error {
+	file, err := spool(part, limit)
+	if err != nil {
+		return err
+	}
+
+	defer file.remove()
+
+	image, err := processUpload(file, allowMime, exists)
+	if err != nil {
+		return err
+	}
+
+	return store(image)
+}
+
+// spooledFile is an uploaded file kept in a temporary file, hashed while it was written.
+type spooledFile struct {
+	*os.File
+	size int64
+	sum  []byte
+}
+
+// spool copies the part to a temporary file, it fails once the part is larger than the limit.
+func spool(part io.Reader, limit int64) (*spooledFile, error) {
+	tmp, err := os.CreateTemp("", "upload-*")
+	if err != nil {
+		return nil, err
+	}
+
+	file := &spooledFile{File: tmp}
+
+	hash := sha256.New()
+
+	file.size, err = io.Copy(io.MultiWriter(tmp, hash), io.LimitReader(part, limit+1))
+	if err != nil {
+		file.remove()
+		return nil, sizeError(err)
+	}
+
+	if file.size > limit {
+		file.remove()
+		return nil, ErrSizeTooLarger
+	}
+
+	file.sum = hash.Sum(nil)
+
+	if err := file.rewind(); err != nil {
+		file.remove()
+		return nil, err
+	}
+
+	return file, nil
+}
+
+func (f *spooledFile) rewind() error {
+	_, err := f.Seek(0, io.SeekStart)
+	return err
+}
+
+func (f *spooledFile) remove() {
+	f.Close()
+	os.Remove(f.Name())
+}
+
+// readLimited fails once the part is larger than the limit instead of cutting it.
+func readLimited(part *multipart.Part, limit int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(part, limit+1))
+	if err != nil {
+		return nil, sizeError(err)
+	}
+
+	if int64(len(data)) > limit {
+		return nil, ErrSizeTooLarger
+	}
+
+	return data, nil
+}
+
+func sizeError(err error) error {
+	var maxBytes *http.MaxBytesError
+	if errors.As(err, &maxBytes) {
+		return ErrSizeTooLarger
+	}
+
+	return err
+}
+
+func processUpload(file *spooledFile, allowMime []string, exists func(name string) (bool, error)) (processedImage, error) {
+	// the sniffing reads at most the first 512 bytes
+	head := make([]byte, 512)
+
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return processedImage{}, err
+	}
+
+	contentType := DetectContentType(head[:n])
+
+	if err := ValidateFile(allowMime, contentType); err != nil {
+		return processedImage{}, err
+	}
+
+	if err := file.rewind(); err != nil {
+		return processedImage{}, err
+	}
+
+	image := strings.HasPrefix(contentType, "image/")
+
+	// every image is stored as JPEG once processed, documents like a PDF are stored as they are
+	ext := ".jpg"
+	if !image {
+		ext = extension(contentType)
+	}
+
+	name := ContentName(file.sum, ext)
+
+	stored, err := exists(name)
+	if err != nil {
+		return processedImage{}, err
+	}
+
+	if stored {
+		return processedImage{name: name}, nil
+	}
+
+	if !image {
+		return processedImage{name: name, files: []storedFile{{name, contentType, file, file.size, file.sum}}}, nil
+	}
+
+	outputs, err := ProcessImage(file)
+	if err != nil {
+		return processedImage{}, err
+	}
+
+	variants := VariantNames(name)
+
+	processed := processedImage{name: name, files: []storedFile{memoryFile(name, outputs[0])}}
+	for i, variant := range Variants {
+		processed.files = append(processed.files, memoryFile(variants[variant.Name], outputs[i+1]))
+	}
+
+	return processed, nil
+}
+
+// ContentName is the SHA-256 sum of the uploaded file, the same file is stored once however often it is uploaded.
+func ContentName(sum []byte, ext string) string {
+	return hex.EncodeToString(sum) + ext
+}
+
+func extension(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	exts, err := mime.ExtensionsByType(mediaType)
+	if err != nil || len(exts) == 0 {
+		return ""
+	}
+
+	return exts[0]
+}
+
+// New returns the storage by name, "local" keeps the files on disk and "s3" in a bucket.
+func New(storage, baseDir, privateDir, baseURL string, s3 S3Config) (Uploader, error) {
+	switch storage {
+	case "local":
+		return NewLocalUpload(baseDir, privateDir, baseURL), nil
+	case "s3":
+		if s3.Endpoint == "" || s3.Bucket == "" {
+			return nil, errors.New("the s3 storage needs an endpoint and a bucket")
+		}
+
+		return NewS3Upload(s3, nil), nil
+	default:
+		return nil, fmt.Errorf("unknown storage %q, expected local or s3", storage)
+	}
+}
@@ -15,6 +15,10 @@ import BathtubIcon from "@mui/icons-material/Bathtub";
 import HotelIcon from "@mui/icons-material/Hotel";
 
 export default function VillaCard({ content }) {
+  const cover = content?.image_urls[0];
+  // older villas have no resized copies, fall back to the original image
+  const image = content?.image_variants?.[cover]?.medium ?? cover;
+
   return (
     <Card
       sx={{ position: "relative", borderRadius: 0 }}
@@ -26,9 +30,7 @@ export default function VillaCard({ content }) {
         <CardMedia
           component="img"
           height="200"
-          image={`${import.meta.env.VITE_BASE_URL_DEV}/files/villas/${
-            content?.image_urls[0]
-          }`}
+          image={image}
           alt="Villa Image"
         />
         <Box position="absolute" bottom={8} left={16}>